@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+	"github.com/spf13/pflag"
+)
+
+// runAnalyze runs the analyze command with args under ctx, restoring every
+// analyze flag to its default once the test ends
+// Cobra hands the context to a subcommand only once, so it is set directly
+func runAnalyze(t *testing.T, ctx context.Context, args ...string) error {
+	t.Helper()
+	t.Cleanup(resetAnalyzeFlags)
+	analyzeCmd.SetContext(ctx)
+	rootCmd.SetArgs(append([]string{"analyze"}, args...))
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	return rootCmd.ExecuteContext(ctx)
+}
+
+// resetAnalyzeFlags sets the analyze flags back to their defaults; list
+// flags are emptied, as setting them appends
+func resetAnalyzeFlags() {
+	analyzeCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if list, ok := f.Value.(pflag.SliceValue); ok {
+			list.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
+// readJSONReport decodes the --json-report a run wrote to path
+func readJSONReport(t *testing.T, path string) templates.ReportData {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read JSON report: %v", err)
+	}
+	report, err := templates.DecodeJSONReport(data)
+	if err != nil {
+		t.Fatalf("Failed to decode JSON report: %v", err)
+	}
+	return report
+}
+
+// writeTextFile creates a text file at path with the given number of unique
+// lines
+func writeTextFile(t *testing.T, path string, lines int) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+}
+
+// captureStdout returns what run writes to os.Stdout
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdout")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create output file: %v", err)
+	}
+	defer out.Close()
+
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+	run()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	return string(content)
+}
+
+// writeSlowTree creates dir/data holding a.txt, b.txt and c.txt, where
+// counting the duplicate lines of b.txt takes far longer than 50ms
+// A run stopped 50ms in has processed a.txt but never gets to c.txt
+func writeSlowTree(t *testing.T, dir string) string {
+	t.Helper()
+	data := filepath.Join(dir, "data")
+	if err := os.Mkdir(data, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeTextFile(t, filepath.Join(data, "a.txt"), 1)
+	writeTextFile(t, filepath.Join(data, "b.txt"), 500000)
+	writeTextFile(t, filepath.Join(data, "c.txt"), 1)
+	return data
+}
+
+func TestAnalyzeDeadline(t *testing.T) {
+	dir := t.TempDir()
+	data := writeSlowTree(t, dir)
+
+	reportPath := filepath.Join(dir, "report.json")
+	err := runAnalyze(t, context.Background(), data,
+		"--deadline", "50ms", "--duplicate-lines", "--no-progress", "--json-report", reportPath)
+
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != exitCodeDeadline {
+		t.Fatalf("Expected exit code %d for the deadline, got %v", exitCodeDeadline, err)
+	}
+	stats := readJSONReport(t, reportPath).Statistics
+	if stats.TotalFiles == 0 || stats.TotalFiles == 3 {
+		t.Errorf("Expected partial statistics, got %d of 3 files", stats.TotalFiles)
+	}
+}
+
+func TestAnalyzeInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	data := writeSlowTree(t, dir)
+
+	// Cancelling the context is what Ctrl-C does to a run
+	ctx, cancel := context.WithCancel(context.Background())
+	defer time.AfterFunc(50*time.Millisecond, cancel).Stop()
+	var err error
+	out := captureStdout(t, func() {
+		err = runAnalyze(t, ctx, data, "--ndjson", "--duplicate-lines", "--no-progress")
+	})
+
+	// Any error other than an *exitError makes main exit with code 1
+	var exitErr *exitError
+	if err == nil || errors.As(err, &exitErr) || !strings.Contains(err.Error(), "interrupted") {
+		t.Fatalf("Expected the interrupted run to fail, got %v", err)
+	}
+
+	// The NDJSON stream ends with the summary line
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var summary ndjsonSummary
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+		t.Fatalf("Failed to decode summary line: %v", err)
+	}
+	if !summary.Summary || summary.Partial != "run was interrupted" {
+		t.Errorf("Expected a partial summary, got %+v", summary)
+	}
+	if n := summary.Statistics.TotalFiles; n == 0 || n == 3 {
+		t.Errorf("Expected partial statistics, got %d of 3 files", n)
+	}
+}
+
+func TestAnalyzeTextExt(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	if err := os.Mkdir(data, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeTextFile(t, filepath.Join(data, "records.dat"), 3)
+
+	// With or without the dot and in any case, .dat files reach the text processor
+	for _, ext := range []string{"DAT", ".dat"} {
+		reportPath := filepath.Join(dir, "report.json")
+		if err := runAnalyze(t, context.Background(), data, "--no-progress", "--text-ext", ext, "--json-report", reportPath); err != nil {
+			t.Fatalf("Failed to analyze with --text-ext %s: %v", ext, err)
+		}
+		files := readJSONReport(t, reportPath).Files
+		if len(files) != 1 || files[0].Type != "text" || files[0].WordCount != 6 {
+			t.Errorf("Expected records.dat analyzed as text with --text-ext %s, got %+v", ext, files)
+		}
+		resetAnalyzeFlags()
+	}
+
+	for _, ext := range []string{".", " "} {
+		err := runAnalyze(t, context.Background(), data, "--no-progress", "--text-ext", ext)
+		if err == nil || !strings.Contains(err.Error(), "invalid --text-ext") {
+			t.Errorf("Expected --text-ext %q to be rejected, got %v", ext, err)
+		}
+		resetAnalyzeFlags()
+	}
+}
+
+func TestAnalyzeCacheOptions(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	if err := os.Mkdir(data, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(data, "a.txt"), []byte("a b\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	cachePath := filepath.Join(dir, "cache.json")
+	reportPath := filepath.Join(dir, "report.json")
+
+	// The trailing newline counts as a line of its own unless wc counting is
+	// asked for, so a result cached without --wc-compatible must not be reused
+	tests := []struct {
+		args  []string
+		lines int
+	}{
+		{nil, 2},
+		{[]string{"--wc-compatible"}, 1},
+		{nil, 2},
+	}
+	for _, tt := range tests {
+		args := append([]string{data, "--no-progress", "--cache", cachePath, "--json-report", reportPath}, tt.args...)
+		if err := runAnalyze(t, context.Background(), args...); err != nil {
+			t.Fatalf("Failed to analyze with %v: %v", tt.args, err)
+		}
+		files := readJSONReport(t, reportPath).Files
+		if len(files) != 1 || files[0].LineCount != tt.lines {
+			t.Errorf("Expected %d lines with %v, got %+v", tt.lines, tt.args, files)
+		}
+		resetAnalyzeFlags()
+	}
+}
+
+func TestAnalyzeCacheSummary(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	if err := os.Mkdir(data, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeTextFile(t, filepath.Join(data, "a.txt"), 1)
+	writeTextFile(t, filepath.Join(data, "b.txt"), 1)
+	cachePath := filepath.Join(dir, "cache.json")
+
+	// The cache counts are part of the summary, not an info log hidden at
+	// the default log level
+	for _, want := range []string{
+		"Cache: 0 files served from cache, 2 freshly processed",
+		"Cache: 2 files served from cache, 0 freshly processed",
+	} {
+		var err error
+		out := captureStdout(t, func() {
+			err = runAnalyze(t, context.Background(), data, "--no-progress", "--cache", cachePath)
+		})
+		if err != nil {
+			t.Fatalf("Failed to analyze: %v", err)
+		}
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected the summary to contain %q, got:\n%s", want, out)
+		}
+		resetAnalyzeFlags()
+	}
+}
+
+func TestAnalyzeJSONReportPrettyWithNDJSON(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	if err := os.Mkdir(data, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeTextFile(t, filepath.Join(data, "a.txt"), 1)
+	reportPath := filepath.Join(dir, "report.json")
+
+	// --ndjson makes the report compact unless --json-pretty is given
+	tests := []struct {
+		args     []string
+		indented bool
+	}{
+		{nil, false},
+		{[]string{"--json-pretty"}, true},
+		{[]string{"--json-pretty=false"}, false},
+	}
+	for _, tt := range tests {
+		var err error
+		captureStdout(t, func() {
+			args := append([]string{data, "--no-progress", "--ndjson", "--json-report", reportPath}, tt.args...)
+			err = runAnalyze(t, context.Background(), args...)
+		})
+		if err != nil {
+			t.Fatalf("Failed to analyze with %v: %v", tt.args, err)
+		}
+		content, err := os.ReadFile(reportPath)
+		if err != nil {
+			t.Fatalf("Failed to read JSON report: %v", err)
+		}
+		if indented := strings.Contains(string(content), "\n  "); indented != tt.indented {
+			t.Errorf("Expected indented %v with %v, got:\n%s", tt.indented, tt.args, content)
+		}
+		resetAnalyzeFlags()
+	}
+}
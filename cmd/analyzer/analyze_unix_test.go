@@ -0,0 +1,48 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzeUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read directories without permission")
+	}
+
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	locked := filepath.Join(data, "locked")
+	if err := os.MkdirAll(locked, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeTextFile(t, filepath.Join(data, "a.txt"), 1)
+	writeTextFile(t, filepath.Join(locked, "b.txt"), 1)
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatalf("Failed to lock directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+	// By default the locked directory is reported and the walk goes on
+	reportPath := filepath.Join(dir, "report.json")
+	if err := runAnalyze(t, context.Background(), data, "--no-progress", "--json-report", reportPath); err != nil {
+		t.Fatalf("Expected the run to skip the locked directory, got %v", err)
+	}
+	stats := readJSONReport(t, reportPath).Statistics
+	// TotalFiles counts the skipped directory with the errors
+	if stats.TotalFiles != 2 || stats.ErrorCount != 1 {
+		t.Errorf("Expected 2 files, 1 of them an error, got %d and %d", stats.TotalFiles, stats.ErrorCount)
+	}
+	resetAnalyzeFlags()
+
+	// --strict fails on it instead
+	err := runAnalyze(t, context.Background(), data, "--no-progress", "--strict")
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("Expected --strict to fail with a permission error, got %v", err)
+	}
+}
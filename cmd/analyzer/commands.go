@@ -2,15 +2,207 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
 	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
+// Analyze command flags
+var (
+	// byDir prints a du-style per-directory rollup after analysis
+	byDir bool
+
+	// cacheFile enables the incremental result cache stored at this path
+	cacheFile string
+
+	// outputDir receives an overall HTML report plus one report per file type
+	outputDir string
+
+	// jsonReport receives the JSON report, "-" for stdout; jsonPretty indents it
+	jsonReport string
+	jsonPretty bool
+
+	// summaryOnly leaves the per-file rows out of the HTML and JSON reports
+	summaryOnly bool
+
+	// ndjsonOutput streams one JSON line per file and a closing summary to stdout
+	ndjsonOutput bool
+
+	// maxFiles and maxBytes stop the walk once that many files, or bytes of
+	// files, have been accepted; 0 means unlimited
+	maxFiles int
+	maxBytes int64
+
+	// archiveEntry analyzes only this entry of the zip or tar archive given as the path
+	archiveEntry string
+
+	// noProgress disables the overall progress bar
+	noProgress bool
+
+	// noCache bypasses the result cache even when cacheFile is set
+	noCache bool
+
+	// deadline is the wall-clock budget for the whole run (0 means none)
+	deadline time.Duration
+
+	// dryRun estimates the processing time from a few files instead of analyzing
+	dryRun bool
+
+	// duplicateLines counts unique vs repeated lines in text files
+	duplicateLines bool
+
+	// wordPattern is a regex defining what counts as a word in text files
+	wordPattern string
+
+	// csvNoHeader counts the first CSV row as data instead of a header
+	csvNoHeader bool
+
+	// csvMaxField caps a single CSV field in bytes; csvTruncate cuts oversized
+	// fields down instead of failing the file
+	csvMaxField int
+	csvTruncate bool
+
+	// indentation reports the dominant indentation of text files and flags
+	// lines mixing tabs and spaces
+	indentation bool
+
+	// scanSecrets flags text lines that appear to contain secrets, matching
+	// the configured patterns plus secretPatterns, or holding a token above
+	// secretEntropy bits per character
+	scanSecrets    bool
+	secretPatterns []string
+	secretEntropy  float64
+
+	// wcCompatible counts text lines, words and bytes exactly as wc does
+	wcCompatible bool
+
+	// sniffContent flags files whose content doesn't match their extension
+	sniffContent bool
+
+	// lineLength reports line lengths and counts text lines longer than this many runes
+	lineLength int
+
+	// hashAlgo selects the per-file digest shown in reports ("none" skips it)
+	hashAlgo string
+
+	// jsonSchema validates every JSON document against this schema file
+	jsonSchema string
+
+	// redactFields are field=method rules added to the config's redact rules
+	redactFields []string
+
+	// extractTextDir receives the plain text of processors that extract it,
+	// such as XML character data
+	extractTextDir string
+
+	// jsonStructure records the depth and container counts of JSON files;
+	// jsonKeys also lists their top-level keys
+	jsonStructure bool
+	jsonKeys      bool
+
+	// strict aborts the walk on the first unreadable path
+	strict bool
+
+	// textExtensions are extra extensions analyzed as plain text
+	textExtensions []string
+
+	// maxFileSize skips files larger than this many bytes (0 means unlimited)
+	maxFileSize int64
+	// maxDecompressed fails compressed files that decompress to more than
+	// this many bytes (0 means unlimited)
+	maxDecompressed int64
+
+	// plugins are Go plugin files providing extra processors
+	plugins []string
+
+	// hookPlugins are Go plugin files whose OnResult runs after each file
+	hookPlugins []string
+
+	// failOnHookError aborts the run when a result hook returns an error
+	failOnHookError bool
+
+	// sampleFraction is the probability of analyzing each matching file
+	sampleFraction float64
+
+	// sampleSeed seeds the sampling PRNG so sampled runs are reproducible
+	sampleSeed int64
+
+	// treeChunkSize switches hash to a parallel tree hash over chunks of this size
+	treeChunkSize int64
+
+	// hashMmap hashes large files from a memory map instead of streaming them
+	hashMmap bool
+
+	// warnEmpty logs empty and whitespace-only files and counts them as their own type
+	warnEmpty bool
+
+	// sortOutput orders per-file output by path, size or name; empty keeps walk order
+	sortOutput string
+
+	// requireMatches fails a run in which no file matched the filters
+	requireMatches bool
+
+	// topFiles is how many of the largest and wordiest files to list
+	topFiles int
+
+	// sinceRef limits analyze to files changed since this git ref
+	sinceRef string
+
+	// pathsFrom names a file, or - for stdin, listing the paths to analyze
+	pathsFrom string
+
+	// sqlitePath receives one row per processed file in a SQLite files table
+	sqlitePath string
+
+	// processOrder sorts the matching files by size, mtime or name before
+	// processing; empty keeps walk order
+	processOrder string
+)
+
+// analyzeOptions collects the settings that control a single analyze run
+type analyzeOptions struct {
+	filter  utils.FileFilter
+	cache   *processor.ResultCache
+	stats   *templates.StatsAccumulator
+	sampler *sampler
+	// progress advances once per walked file; nil when disabled
+	progress *runProgress
+	// hooks run after each result, in registration order
+	hooks processor.Hooks
+	// limits guard against accidentally walking a huge tree; nil when unset
+	limits *walkLimits
+	// ndjson streams results to stdout for --ndjson; nil otherwise
+	ndjson *ndjsonWriter
+	// mismatches collects extension/content mismatches for --sniff; nil otherwise
+	mismatches *contentMismatches
+	// sorted holds per-file output back for --sort-output; nil otherwise
+	sorted *sortedOutput
+	// sqlite stores each result for --sqlite; nil otherwise
+	sqlite *sqliteSink
+	// files replaces walking the path when set, for --since and --paths-from
+	files []string
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "analyzer",
 	Short: "A file analysis tool",
@@ -22,40 +214,342 @@ var analyzeCmd = &cobra.Command{
 	Use:   "analyze [path]",
 	Short: "Analyze files in the specified path",
 	Long: `Analyze files in the specified path, processing them according to their type.
-	Supports multiple file formats including text, JSON, and CSV.`,
+	Supports multiple file formats including text, JSON, and CSV.
+	With --paths-from the files listed in a file are analyzed instead, and no path is given.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) < 1 {
+		path := "."
+		switch {
+		case pathsFrom != "" && len(args) > 0:
+			return fmt.Errorf("--paths-from doesn't take a path argument")
+		case pathsFrom == "" && len(args) < 1:
 			return fmt.Errorf("path argument is required")
+		case len(args) > 0:
+			path = args[0]
 		}
-
-		path := args[0]
 		if _, err := os.Stat(path); os.IsNotExist(err) {
 			return fmt.Errorf("path does not exist: %s", path)
 		}
 
+		// Buffer sizes come from config and are clamped to the configured maximum
+		models.SetMaxBufferSize(viper.GetInt("processing.max_buffer_size"))
+		bufferSize := viper.GetInt("processing.buffer_size")
+		processor.SetMaxConcurrency(viper.GetInt("processing.max_concurrent"))
+
+		if sampleFraction <= 0 || sampleFraction > 1 {
+			return fmt.Errorf("invalid --sample value %v: must be greater than 0 and at most 1", sampleFraction)
+		}
+
 		// Create processors
-		processors := []processor.Processor{
-			processor.NewTextProcessor(4096),
-			processor.NewJSONProcessor(4096),
-			processor.NewCSVProcessor(4096),
+		processors, err := buildProcessors(bufferSize)
+		if err != nil {
+			return err
+		}
+
+		// Arguments are valid; later failures are runtime errors, not usage errors
+		cmd.SilenceUsage = true
+
+		configFilter, err := loadConfigFilter()
+		if err != nil {
+			return err
+		}
+
+		hooks, err := loadHooks()
+		if err != nil {
+			return err
+		}
+
+		limits, err := newWalkLimits(maxFiles, maxBytes)
+		if err != nil {
+			return err
+		}
+		if ndjsonOutput && jsonReport == "-" {
+			return fmt.Errorf("--ndjson and --json-report - can't both write to stdout")
+		}
+		if err := checkSortKey(sortOutput); err != nil {
+			return err
+		}
+		if err := checkOrderKey(processOrder); err != nil {
+			return err
+		}
+		if topFiles < 0 {
+			return fmt.Errorf("invalid --top value %d: must not be negative", topFiles)
+		}
+
+		// Cancel processing on Ctrl-C so the partial results can still be reported
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// Bound the whole run when a deadline is given
+		if deadline > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, deadline)
+			defer cancel()
+		}
+
+		// Walk only regular files some processor handles, including --text-ext
+		// additions and plugins, that also pass the config file's filters
+		// FIFOs and devices are ruled out first, as some CanHandle reads the file
+		opts := analyzeOptions{
+			filter: utils.CombineFilters(regularFilesOnly(), handledByAny(processors), configFilter),
+			stats:  templates.NewStatsAccumulator(),
+			hooks:  hooks,
+			limits: limits,
+		}
+		if ndjsonOutput {
+			opts.ndjson = newNDJSONWriter(os.Stdout)
+		}
+		if sortOutput != "" {
+			opts.sorted = newSortedOutput(sortOutput)
+		}
+		// Sniffing also looks at files no processor handles, like images
+		if sniffContent {
+			opts.filter = utils.CombineFilters(regularFilesOnly(), configFilter)
+			opts.mismatches = &contentMismatches{}
+		}
+		seed := sampleSeed
+		if !cmd.Flags().Changed("sample-seed") {
+			seed = time.Now().UnixNano()
+		}
+
+		// Facts about the run as a whole are printed with the summary, as they
+		// matter even when per-file logging is off
+		var runNotes []string
+		if sinceRef != "" {
+			if archiveEntry != "" || dryRun {
+				return fmt.Errorf("--since can't be combined with --entry or --dry-run")
+			}
+			files, err := changedFiles(ctx, path, sinceRef)
+			if err != nil {
+				return err
+			}
+			runNotes = append(runNotes, fmt.Sprintf("Changed since %s: %d files", sinceRef, len(files)))
+			opts.files = files
+		}
+		if pathsFrom != "" {
+			if archiveEntry != "" || dryRun || sinceRef != "" {
+				return fmt.Errorf("--paths-from can't be combined with --entry, --dry-run or --since")
+			}
+			files, err := readPathList(pathsFrom, os.Stdin)
+			if err != nil {
+				return err
+			}
+			runNotes = append(runNotes, fmt.Sprintf("Listed in %s: %d paths", pathsFrom, len(files)))
+			opts.files = files
+		}
+
+		// A single archive entry is extracted and analyzed on its own
+		if archiveEntry != "" {
+			return analyzeArchiveEntry(ctx, path, processors, opts)
+		}
+
+		// A dry run only estimates how long the full run would take
+		if dryRun {
+			est, err := estimateRun(ctx, path, processors, opts.filter, seed)
+			if err != nil {
+				return err
+			}
+			printEstimate(os.Stdout, est)
+			return nil
+		}
+
+		if sampleFraction < 1 {
+			runNotes = append(runNotes, fmt.Sprintf("Sampling: %.1f%% of matching files (--sample-seed %d)", sampleFraction*100, seed))
+			opts.sampler = newSampler(sampleFraction, seed)
+		}
+		opts.stats.SetEmptyCategory(warnEmpty)
+		opts.stats.SetTopN(topFiles)
+		if cacheFile != "" && !noCache {
+			cache, err := processor.LoadResultCache(cacheFile, processingFingerprint(cmd.Flags()))
+			if err != nil {
+				return err
+			}
+			opts.cache = cache
+		}
+
+		if sqlitePath != "" {
+			sink, err := openSQLiteSink(sqlitePath)
+			if err != nil {
+				return err
+			}
+			defer sink.close()
+			opts.sqlite = sink
+		}
+
+		// --order buffers every matching path so the run can start with the
+		// largest, newest or first-named files
+		if processOrder != "" {
+			if opts.files == nil {
+				files, err := enumerateFiles(ctx, path, opts, strict)
+				if err != nil {
+					return err
+				}
+				opts.files = files
+			}
+			opts.files = orderFiles(opts.files, processOrder)
+		}
+
+		// The bar needs a total up front, so count the matching files first
+		if progressEnabled(noProgress) {
+			if total, err := countFiles(path, opts); err == nil {
+				opts.progress = startProgress(total)
+				defer opts.progress.Finish()
+			} else {
+				logrus.Debugf("Progress bar disabled: %v", err)
+			}
 		}
 
 		// Process files
-		return processFiles(path, processors)
+		results, err := processFiles(ctx, path, processors, opts)
+		opts.progress.Finish()
+		opts.stats.Finish()
+		if flushErr := opts.sorted.flush(opts.ndjson); flushErr != nil {
+			return flushErr
+		}
+		if closeErr := opts.sqlite.close(); closeErr != nil {
+			return closeErr
+		}
+		interrupted := errors.Is(err, context.Canceled)
+		deadlineHit := errors.Is(err, context.DeadlineExceeded)
+		limitHit := errors.Is(err, errLimitReached)
+		if err != nil && !interrupted && !deadlineHit && !limitHit {
+			opts.ndjson.writeSummary(opts.stats.Statistics(), err.Error())
+			return err
+		}
+
+		if opts.cache != nil {
+			hits, misses := opts.cache.Stats()
+			runNotes = append(runNotes, fmt.Sprintf("Cache: %d files served from cache, %d freshly processed", hits, misses))
+			if err := opts.cache.Save(); err != nil {
+				return err
+			}
+		}
+
+		if byDir {
+			printDirSummary(summaryWriter(), path, results)
+		}
+
+		// Partial runs still print what was gathered, then fail
+		var note string
+		var runErr error
+		switch {
+		case interrupted:
+			note = "run was interrupted"
+			runErr = fmt.Errorf("analysis interrupted: partial results shown")
+		case deadlineHit:
+			note = fmt.Sprintf("deadline of %v was hit", deadline)
+			runErr = &exitError{
+				code: exitCodeDeadline,
+				err:  fmt.Errorf("analysis deadline of %v exceeded: partial results shown", deadline),
+			}
+		case limitHit:
+			note = err.Error()
+			runErr = &exitError{
+				code: exitCodeLimit,
+				err:  fmt.Errorf("analysis stopped, %v: partial results shown", err),
+			}
+		}
+
+		stats := opts.stats.Statistics()
+		printSummary(summaryWriter(), stats, note)
+		for _, line := range runNotes {
+			fmt.Fprintln(summaryWriter(), line)
+		}
+		printTopFiles(summaryWriter(), stats)
+		if err := opts.ndjson.writeSummary(stats, note); err != nil {
+			return err
+		}
+		if opts.sampler != nil {
+			printSampleSummary(summaryWriter(), opts.sampler, stats)
+		}
+		opts.mismatches.print(summaryWriter())
+
+		report := opts.stats.Report(reportTitle)
+		report.SummaryOnly = summaryOnly
+		if sortOutput != "" {
+			sortReport(&report, sortOutput)
+		}
+		if jsonReport != "" {
+			if err := writeJSONReport(jsonReport, report, jsonReportPretty(cmd)); err != nil {
+				return err
+			}
+		}
+
+		if outputDir != "" {
+			written, err := writeReports(outputDir, report)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(summaryWriter(), "Wrote %d reports to %s\n", len(written), outputDir)
+		}
+
+		// An empty run is reported rather than passing silently as a success
+		if runErr == nil && matchedFiles(stats, opts.sampler) == 0 {
+			msg := fmt.Sprintf("0 files matched filters; extensions analyzed: %s",
+				strings.Join(analyzedExtensions(processors), ", "))
+			if requireMatches {
+				return &exitError{code: exitCodeNoMatches, err: errors.New(msg)}
+			}
+			fmt.Fprintln(summaryWriter(), msg)
+		}
+		return runErr
 	},
 }
 
+// matchedFiles returns how many files passed the filters, whether or not
+// they were processed successfully or picked by --sample
+func matchedFiles(stats templates.Statistics, s *sampler) int {
+	if s != nil {
+		return s.matched
+	}
+	return stats.TotalFiles + stats.VanishedCount
+}
+
+// analyzedExtensions lists the extensions the processors handle, once each
+func analyzedExtensions(processors []processor.Processor) []string {
+	var extensions []string
+	seen := make(map[string]bool)
+	for _, info := range processor.Describe(processors) {
+		for _, ext := range info.Extensions {
+			if !seen[ext] {
+				seen[ext] = true
+				extensions = append(extensions, ext)
+			}
+		}
+	}
+	return extensions
+}
+
 // hashCmd represents the hash command
 var hashCmd = &cobra.Command{
-	Use:   "hash [file]",
+	Use:   "hash [file|directory]",
 	Short: "Calculate SHA256 hash of a file",
-	Long:  `Calculate and display the SHA256 hash of the specified file.`,
+	Long: `Calculate and display the SHA256 hash of the specified file.
+	For a directory, every file under it is hashed in parallel and listed
+	in sha256sum format; Ctrl-C stops the run after the files in progress.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return fmt.Errorf("file argument is required")
 		}
 
-		hash, err := utils.HashFile(args[0])
+		if hashMmap && treeChunkSize > 0 {
+			return fmt.Errorf("--mmap and --tree-chunk-size can't be used together")
+		}
+
+		if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+			return hashDirectory(cmd.Context(), args[0])
+		}
+
+		// Large files can be hashed in parallel as a tree instead
+		if treeChunkSize > 0 {
+			hash, err := utils.TreeHashFile(args[0], treeChunkSize, runtime.NumCPU())
+			if err != nil {
+				return fmt.Errorf("failed to calculate tree hash: %w", err)
+			}
+			fmt.Printf("Tree hash: %s\n", hash)
+			return nil
+		}
+
+		hash, err := hashFile(args[0])
 		if err != nil {
 			return fmt.Errorf("failed to calculate hash: %w", err)
 		}
@@ -65,6 +559,45 @@ var hashCmd = &cobra.Command{
 	},
 }
 
+// hashFile returns the SHA256 of the file at path, through a memory map for
+// large files with --mmap
+func hashFile(path string) (string, error) {
+	if hashMmap {
+		return utils.HashFileMmap(path, "sha256")
+	}
+	return utils.HashFile(path)
+}
+
+// hashDirectory prints the hash of every file under dir, sorted by path,
+// with progress on stderr
+func hashDirectory(ctx context.Context, dir string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	progress := func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rHashed %d/%d files", done, total)
+	}
+	hashes, err := utils.HashDirWith(ctx, dir, regularFilesOnly(), runtime.NumCPU(), hashFile, progress)
+	fmt.Fprintln(os.Stderr)
+
+	paths := make([]string, 0, len(hashes))
+	for path := range hashes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("%s  %s\n", hashes[path], path)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("hashing interrupted: %d files hashed", len(hashes))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to hash directory: %w", err)
+	}
+	return nil
+}
+
 // encodeCmd represents the encode command
 var encodeCmd = &cobra.Command{
 	Use:   "encode [file]",
@@ -104,47 +637,699 @@ var decodeCmd = &cobra.Command{
 	},
 }
 
-// processFiles processes files in the given path using the provided processors
-func processFiles(path string, processors []processor.Processor) error {
-	// Create file filter
-	filter := utils.CreateExtensionFilter(".txt", ".json", ".csv", ".tsv")
+// buildProcessors creates the analyze processors configured from the flags
+// Plugin processors come first so they can claim formats the built-ins also handle
+func buildProcessors(bufferSize int) ([]processor.Processor, error) {
+	var processors []processor.Processor
+	for _, path := range plugins {
+		proc, err := processor.LoadPlugin(path)
+		if err != nil {
+			return nil, err
+		}
+		logrus.Debugf("Loaded processor %q from plugin %s", proc.Name(), path)
+		processors = append(processors, proc)
+	}
 
-	// Walk through files
-	return utils.WalkFiles(path, filter, func(filePath string) error {
-		// Find appropriate processor
-		var selectedProcessor processor.Processor
+	// Validate the flags once rather than in every factory call
+	if lineLength < 0 {
+		return nil, fmt.Errorf("invalid --line-length value %d: must not be negative", lineLength)
+	}
+	var wordRegexp *regexp.Regexp
+	if wordPattern != "" {
+		re, err := regexp.Compile(wordPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --word-pattern: %w", err)
+		}
+		if re.MatchString("") {
+			return nil, fmt.Errorf("invalid --word-pattern %q: must not match an empty string", wordPattern)
+		}
+		wordRegexp = re
+	}
+	if wcCompatible && wordRegexp != nil {
+		return nil, fmt.Errorf("--wc-compatible and --word-pattern can't be combined")
+	}
+	for _, ext := range textExtensions {
+		if strings.Trim(ext, ". ") == "" {
+			return nil, fmt.Errorf("invalid --text-ext value: %q", ext)
+		}
+	}
+	if csvMaxField < 0 {
+		return nil, fmt.Errorf("invalid --csv-max-field value %d: must not be negative", csvMaxField)
+	}
+
+	// Hashing shares the read that analyzes each file
+	algo, err := digestAlgorithm(hashAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	redactor, err := loadRedactor()
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := loadSecretScanner()
+	if err != nil {
+		return nil, err
+	}
+
+	// Text extraction shares it too
+	var textSink processor.TextSink
+	if extractTextDir != "" {
+		textSink = processor.DirTextSink(extractTextDir)
+	}
+
+	// Each built-in is created by a factory, so config overrides for some
+	// extensions get a processor configured from the same flags
+	factories := []processor.Factory{
+		func(size int) (processor.Processor, error) {
+			textProcessor := processor.NewTextProcessor(size)
+			textProcessor.SetDetectDuplicates(duplicateLines)
+			textProcessor.SetCheckIndentation(indentation)
+			textProcessor.SetWCCompatible(wcCompatible)
+			textProcessor.SetLineLengthThreshold(lineLength)
+			textProcessor.SetSecretScanner(secrets)
+			if wordRegexp != nil {
+				textProcessor.SetWordPattern(wordRegexp)
+			}
+			for _, ext := range textExtensions {
+				textProcessor.AddExtension(ext)
+			}
+			textProcessor.SetMaxFileSize(maxFileSize)
+			textProcessor.SetHashAlgorithm(algo)
+			return textProcessor, nil
+		},
+		func(size int) (processor.Processor, error) {
+			jsonProcessor := processor.NewJSONProcessor(size)
+			if jsonSchema != "" {
+				var err error
+				if jsonProcessor, err = processor.NewJSONSchemaProcessor(size, jsonSchema); err != nil {
+					return nil, err
+				}
+			}
+			jsonProcessor.SetStructureStats(jsonStructure)
+			jsonProcessor.SetTopLevelKeys(jsonKeys)
+			jsonProcessor.SetRedactor(redactor)
+			jsonProcessor.SetMaxFileSize(maxFileSize)
+			jsonProcessor.SetHashAlgorithm(algo)
+			return jsonProcessor, nil
+		},
+		func(size int) (processor.Processor, error) {
+			ndjsonProcessor := processor.NewNDJSONProcessor(size)
+			ndjsonProcessor.SetMaxFileSize(maxFileSize)
+			ndjsonProcessor.SetHashAlgorithm(algo)
+			return ndjsonProcessor, nil
+		},
+		func(size int) (processor.Processor, error) {
+			csvProcessor := processor.NewCSVProcessor(size)
+			csvProcessor.SetHasHeader(!csvNoHeader)
+			csvProcessor.SetMaxFieldSize(csvMaxField, csvTruncate)
+			csvProcessor.SetRedactor(redactor)
+			csvProcessor.SetMaxFileSize(maxFileSize)
+			csvProcessor.SetHashAlgorithm(algo)
+			return csvProcessor, nil
+		},
+		func(size int) (processor.Processor, error) {
+			xmlProcessor := processor.NewXMLProcessor(size)
+			xmlProcessor.SetTextSink(textSink)
+			xmlProcessor.SetMaxFileSize(maxFileSize)
+			xmlProcessor.SetHashAlgorithm(algo)
+			return xmlProcessor, nil
+		},
+		func(size int) (processor.Processor, error) {
+			htmlProcessor := processor.NewHTMLProcessor(size)
+			htmlProcessor.SetTextSink(textSink)
+			htmlProcessor.SetMaxFileSize(maxFileSize)
+			htmlProcessor.SetHashAlgorithm(algo)
+			return htmlProcessor, nil
+		},
+	}
+
+	var overrides []processor.ExtensionOverride
+	if err := viper.UnmarshalKey("processing.overrides", &overrides); err != nil {
+		return nil, fmt.Errorf("failed to read processing.overrides config: %w", err)
+	}
+	builtins, err := processor.BuildProcessors(bufferSize, factories, overrides)
+	if err != nil {
+		return nil, err
+	}
+	processors = append(processors, builtins...)
+
+	// Processors compiled in with build tags, e.g. -tags xlsx
+	for _, proc := range processor.OptionalProcessors(bufferSize) {
+		if limited, ok := proc.(interface{ SetMaxFileSize(int64) }); ok {
+			limited.SetMaxFileSize(maxFileSize)
+		}
+		if hashing, ok := proc.(interface{ SetHashAlgorithm(string) }); ok {
+			hashing.SetHashAlgorithm(algo)
+		}
+		if extracting, ok := proc.(interface{ SetTextSink(processor.TextSink) }); ok {
+			extracting.SetTextSink(textSink)
+		}
+		processors = append(processors, proc)
+	}
+
+	// Compressed files are recognized by content, so this goes ahead of the
+	// built-ins to catch e.g. a gzipped .log; plugins still take precedence
+	compressed := processor.NewCompressedProcessor(bufferSize, processors...)
+	compressed.SetMaxFileSize(maxFileSize)
+	compressed.SetMaxDecompressedSize(maxDecompressed)
+	compressed.SetHashAlgorithm(algo)
+	ordered := make([]processor.Processor, 0, len(processors)+1)
+	ordered = append(ordered, processors[:len(plugins)]...)
+	ordered = append(ordered, compressed)
+	ordered = append(ordered, processors[len(plugins):]...)
+	return ordered, nil
+}
+
+// loadRedactor builds the redactor from the config's redact rules followed
+// by the --redact flags, so a flag overrides the config for the same field
+func loadRedactor() (*processor.Redactor, error) {
+	var rules []processor.RedactRule
+	if err := viper.UnmarshalKey("redact", &rules); err != nil {
+		return nil, fmt.Errorf("failed to read redact config: %w", err)
+	}
+	for _, spec := range redactFields {
+		rule, err := processor.ParseRedactRule(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --redact value: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return processor.NewRedactor(rules)
+}
+
+// loadSecretScanner builds the --secrets scanner from the patterns of the
+// config file's secrets section, or the built-in ones when it lists none,
+// followed by the --secret-pattern flags; it returns nil without --secrets
+func loadSecretScanner() (*processor.SecretScanner, error) {
+	if !scanSecrets {
+		if len(secretPatterns) > 0 {
+			return nil, fmt.Errorf("--secret-pattern needs --secrets")
+		}
+		return nil, nil
+	}
+
+	var patterns []processor.SecretPattern
+	if err := viper.UnmarshalKey("secrets.patterns", &patterns); err != nil {
+		return nil, fmt.Errorf("failed to read secrets config: %w", err)
+	}
+	if len(patterns) == 0 {
+		patterns = append(patterns, processor.DefaultSecretPatterns...)
+	}
+	for _, spec := range secretPatterns {
+		pattern, err := processor.ParseSecretPattern(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --secret-pattern value: %w", err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return processor.NewSecretScanner(patterns, secretEntropy)
+}
+
+// loadConfigFilter builds the file filter described by the config file's
+// filters section; without one every file passes
+func loadConfigFilter() (utils.FileFilter, error) {
+	return buildConfigFilter(viper.GetViper())
+}
+
+// digestAlgorithm validates the --hash value and returns the algorithm for
+// processors, which is empty for "none"
+func digestAlgorithm(value string) (string, error) {
+	if value == "" || value == "none" {
+		return "", nil
+	}
+	if _, err := utils.NewHash(value); err != nil {
+		return "", fmt.Errorf("invalid --hash value: %w", err)
+	}
+	return value, nil
+}
+
+// hasDigest reports whether a cached result carries the digest this run asks
+// for; results cached by a run with another --hash must be processed again
+func hasDigest(result models.ProcessResult) bool {
+	algo, err := digestAlgorithm(hashAlgo)
+	if err != nil || algo == "" {
+		return true
+	}
+	h, err := utils.NewHash(algo)
+	return err == nil && len(result.Hash) == hex.EncodedLen(h.Size())
+}
+
+// processingFlags are the analyze flags that change what processors report
+// for a file; --hash is left to hasDigest
+var processingFlags = []string{
+	"plugin", "text-ext", "max-file-size", "max-decompressed-size",
+	"wc-compatible", "word-pattern", "line-length", "indentation", "duplicate-lines",
+	"secrets", "secret-pattern", "secret-entropy",
+	"csv-no-header", "csv-max-field", "csv-truncate",
+	"json-schema", "json-structure", "json-keys", "redact",
+}
+
+// processingConfigKeys are the config file settings that configure processors
+var processingConfigKeys = []string{"processing.overrides", "redact", "secrets.patterns"}
+
+// processingFingerprint identifies the processor configuration of this run,
+// so the result cache only serves results produced the same way
+func processingFingerprint(flags *pflag.FlagSet) string {
+	h := sha256.New()
+	for _, name := range processingFlags {
+		fmt.Fprintf(h, "%s=%s\n", name, flags.Lookup(name).Value)
+	}
+	for _, key := range processingConfigKeys {
+		fmt.Fprintf(h, "%s=%v\n", key, viper.Get(key))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// analyzeArchiveEntry analyzes the --entry file inside the archive at path
+// and prints its statistics
+func analyzeArchiveEntry(ctx context.Context, path string, processors []processor.Processor, opts analyzeOptions) error {
+	result, err := processor.ProcessArchiveEntry(ctx, path, archiveEntry, processors)
+	if errors.Is(err, processor.ErrEntryNotFound) {
+		return fmt.Errorf("archive %s has no entry named %s", path, archiveEntry)
+	}
+	if err != nil {
+		return err
+	}
+
+	logResult(result)
+	opts.stats.Add(result)
+	if err := opts.ndjson.write(result); err != nil {
+		return err
+	}
+	if err := runHooks(opts, result); err != nil {
+		return err
+	}
+	opts.stats.Finish()
+	printSummary(summaryWriter(), opts.stats.Statistics(), "")
+	return opts.ndjson.writeSummary(opts.stats.Statistics(), "")
+}
+
+// loadHooks loads the result hooks from --hook plugins, in flag order
+func loadHooks() (processor.Hooks, error) {
+	var hooks processor.Hooks
+	for _, path := range hookPlugins {
+		hook, err := processor.LoadHookPlugin(path)
+		if err != nil {
+			return nil, err
+		}
+		logrus.Debugf("Loaded result hook from plugin %s", path)
+		hooks.Add(hook)
+	}
+	return hooks, nil
+}
+
+// emitResult stores result for --sqlite and logs it, when log is set, and
+// streams it to the --ndjson output, or holds both back until the walk ends
+// for --sort-output
+func emitResult(opts analyzeOptions, result models.ProcessResult, log bool) error {
+	if err := opts.sqlite.insert(result); err != nil {
+		return err
+	}
+	if opts.sorted != nil {
+		opts.sorted.hold(result, log)
+		return nil
+	}
+	if log {
+		logResult(result)
+	}
+	return opts.ndjson.write(result)
+}
+
+// logResult logs the outcome of processing one file
+func logResult(result models.ProcessResult) {
+	filePath := result.Path
+	if result.Error != nil {
+		if errors.Is(result.Error, models.ErrFileTooLarge) {
+			logrus.Warnf("Skipping %s: %v", filePath, result.Error)
+		} else {
+			logrus.Errorf("Failed to process file %s: %v", filePath, result.Error)
+		}
+		return
+	}
+
+	logrus.Infof("Processed %s: %d lines, %d words, %d bytes in %v",
+		filePath, result.Lines, result.Words, result.Bytes, result.Duration)
+	if result.InvalidRecords > 0 {
+		logrus.Warnf("  %s: %d of %d records failed validation",
+			filePath, result.InvalidRecords, result.ValidRecords+result.InvalidRecords)
+	}
+	if result.AnomalyCount > 0 {
+		first := result.Anomalies[0]
+		logrus.Warnf("  %s: %d anomalies, first: %s at line %d: %s",
+			filePath, result.AnomalyCount, first.Kind, first.Line, first.Detail)
+	}
+	if result.IsEmpty && warnEmpty {
+		logrus.Warnf("  %s: file is empty or contains only whitespace", filePath)
+	}
+	if result.LinesOverThreshold > 0 {
+		logrus.Infof("  %s: %d lines longer than %d characters (longest %d, average %.1f)",
+			filePath, result.LinesOverThreshold, lineLength, result.MaxLineLength, result.AvgLineLength)
+	}
+	if result.DuplicateLines > 0 {
+		logrus.Infof("  %s: %d unique lines, %d duplicate lines",
+			filePath, result.UniqueLines, result.DuplicateLines)
+	}
+}
+
+// runHooks passes result to the run's hooks; a failing hook is only logged
+// unless --fail-on-hook-error is set, in which case the error stops the walk
+func runHooks(opts analyzeOptions, result models.ProcessResult) error {
+	err := opts.hooks.Run(result)
+	if err == nil || failOnHookError {
+		return err
+	}
+	logrus.Warnf("%v", err)
+	return nil
+}
+
+// handledByAny returns a filter accepting files that some processor can handle
+func handledByAny(processors []processor.Processor) utils.FileFilter {
+	return func(path string) bool {
 		for _, p := range processors {
-			if p.CanHandle(filePath) {
-				selectedProcessor = p
-				break
+			if p.CanHandle(path) {
+				return true
 			}
 		}
+		return false
+	}
+}
+
+// regularFilesOnly returns a filter skipping FIFOs, sockets and devices,
+// which can't be analyzed and may block forever when opened
+func regularFilesOnly() utils.FileFilter {
+	regular := utils.CreateRegularFileFilter()
+	return func(path string) bool {
+		if regular(path) {
+			return true
+		}
+		logrus.Debugf("Skipping %s: not a regular file", path)
+		return false
+	}
+}
+
+// processFiles processes files in the given path using the provided processors
+// and returns the results of every successfully processed file
+// Cancelling ctx stops the walk; results gathered up to that point are still returned
+func processFiles(ctx context.Context, path string, processors []processor.Processor, opts analyzeOptions) ([]models.ProcessResult, error) {
+	var results []models.ProcessResult
+
+	handle := func(filePath string) error {
+		// Stop walking once the run has been cancelled or a limit is used up
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := opts.limits.accept(filePath); err != nil {
+			return err
+		}
+		defer opts.progress.Add()
 
+		// Decide on sampling before the file is even stat'ed
+		if opts.sampler != nil && !opts.sampler.include() {
+			return nil
+		}
+
+		mismatch := opts.mismatches.check(filePath)
+
+		// Find appropriate processor
+		selectedProcessor := handlerFor(processors, filePath)
 		if selectedProcessor == nil {
-			logrus.Warnf("No processor found for file: %s", filePath)
+			if opts.mismatches != nil {
+				logrus.Debugf("No processor found for file: %s", filePath)
+			} else {
+				logrus.Warnf("No processor found for file: %s", filePath)
+			}
 			return nil
 		}
 
+		// Reuse the cached result when the file is unchanged
+		if opts.cache != nil {
+			if info, err := os.Stat(filePath); err == nil {
+				if cached, ok := opts.cache.Lookup(filePath, info); ok && hasDigest(cached) {
+					logrus.Debugf("Cache hit for %s", filePath)
+					addMismatch(&cached, mismatch)
+					opts.stats.Add(cached)
+					results = append(results, cached)
+					if err := emitResult(opts, cached, false); err != nil {
+						return err
+					}
+					return runHooks(opts, cached)
+				}
+			}
+		}
+
 		// Process file
-		result, err := selectedProcessor.Process(context.Background(), filePath)
-		if err != nil {
-			logrus.Errorf("Failed to process file %s: %v", filePath, err)
+		result, err := processor.SafeProcess(ctx, selectedProcessor, filePath)
+		if errors.Is(err, models.ErrFileVanished) {
+			// Expected on live directories, so not worth more than a debug line
+			logrus.Debugf("Skipping %s: %v", filePath, err)
+			opts.stats.AddVanished(filePath)
 			return nil
 		}
+		if err != nil {
+			opts.stats.AddError(filePath, err)
+			result.Path, result.Error = filePath, err
+			if err := emitResult(opts, result, true); err != nil {
+				return err
+			}
+			return runHooks(opts, result)
+		}
 
-		// Log results
-		logrus.Infof("Processed %s: %d lines, %d words, %d bytes in %v",
-			filePath, result.Lines, result.Words, result.Bytes, result.Duration)
+		if opts.cache != nil {
+			opts.cache.Store(result)
+		}
+		addMismatch(&result, mismatch)
 
-		return nil
+		opts.stats.Add(result)
+		results = append(results, result)
+		if err := emitResult(opts, result, true); err != nil {
+			return err
+		}
+		return runHooks(opts, result)
+	}
+
+	// --since, --paths-from and --order hand over the files, so there is
+	// nothing to walk; a listed path that isn't a file is an error, not the
+	// end of the run
+	if opts.files != nil {
+		for _, file := range opts.files {
+			info, err := os.Stat(file)
+			if err == nil && info.IsDir() {
+				err = fmt.Errorf("%s is a directory", file)
+			}
+			if err != nil {
+				logrus.Warnf("Skipping %s: %v", file, err)
+				opts.stats.AddError(file, err)
+				continue
+			}
+			if !opts.filter(file) {
+				continue
+			}
+			if err := handle(file); err != nil {
+				return results, err
+			}
+		}
+		if opts.sorted != nil {
+			sortResults(results, opts.sorted.key)
+		}
+		return results, nil
+	}
+
+	// Walk through files; by default unreadable paths are skipped and reported
+	if strict {
+		err := utils.WalkFiles(path, opts.filter, handle)
+		if opts.sorted != nil {
+			sortResults(results, opts.sorted.key)
+		}
+		return results, err
+	}
+
+	skipped, err := utils.WalkFilesLenient(path, opts.filter, handle)
+	if opts.sorted != nil {
+		sortResults(results, opts.sorted.key)
+	}
+	recordSkipped(opts, skipped)
+	return results, err
+}
+
+// countFiles counts the files a run will analyze, for the progress bar
+func countFiles(path string, opts analyzeOptions) (int, error) {
+	if opts.files == nil {
+		return utils.CountFiles(path, opts.filter)
+	}
+	count := 0
+	for _, file := range opts.files {
+		if opts.filter(file) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// printSummary writes the aggregated statistics
+// A non-empty note marks the summary as partial and says why
+func printSummary(out io.Writer, stats templates.Statistics, note string) {
+	if note != "" {
+		fmt.Fprintf(out, "Summary (partial - %s):\n", note)
+	} else {
+		fmt.Fprintln(out, "Summary:")
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  Files\t%d (%d ok, %d failed)\n", stats.TotalFiles, stats.SuccessCount, stats.ErrorCount)
+	fmt.Fprintf(w, "  Size\t%s\n", utils.FormatBytes(stats.TotalSize))
+	if stats.SuccessCount > 0 {
+		fmt.Fprintf(w, "  File sizes\t%s min, %s avg, %s max (%s)\n", utils.FormatBytes(stats.MinSize),
+			utils.FormatBytes(stats.AvgSize), utils.FormatBytes(stats.MaxSize), stats.LargestFile)
+	}
+	fmt.Fprintf(w, "  Lines\t%d\n", stats.TotalLines)
+	fmt.Fprintf(w, "  Words\t%d\n", stats.TotalWords)
+	if stats.VanishedCount > 0 {
+		fmt.Fprintf(w, "  Vanished\t%d (deleted during the run)\n", stats.VanishedCount)
+	}
+	if stats.EmptyCount > 0 {
+		fmt.Fprintf(w, "  Empty files\t%d\n", stats.EmptyCount)
+	}
+	fmt.Fprintf(w, "  Average time\t%v\n", stats.AverageTime)
+	fmt.Fprintf(w, "  Throughput\t%.2f MB/s, %.1f files/s (%v wall)\n",
+		stats.MBPerSecond, stats.FilesPerSecond, stats.WallTime.Round(time.Millisecond))
+	w.Flush()
+
+	printTypeHistogram(out, stats.TypeCounts)
+}
+
+// printTypeHistogram writes a bar per file type, most common first
+func printTypeHistogram(out io.Writer, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	types := make([]string, 0, len(counts))
+	maxCount := 0
+	for name, count := range counts {
+		types = append(types, name)
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
 	})
+
+	const barWidth = 40
+	fmt.Fprintln(out, "File types:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range types {
+		bar := strings.Repeat("#", (counts[name]*barWidth+maxCount-1)/maxCount)
+		fmt.Fprintf(w, "  %s\t%d\t%s\n", name, counts[name], bar)
+	}
+	w.Flush()
+}
+
+// printTopFiles lists the largest files and those with the most words, when
+// --top asked for them
+func printTopFiles(out io.Writer, stats templates.Statistics) {
+	if len(stats.TopBySize) > 0 {
+		fmt.Fprintln(out, "Largest files:")
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, file := range stats.TopBySize {
+			fmt.Fprintf(w, "  %s\t%s\n", utils.FormatBytes(file.Value), file.Name)
+		}
+		w.Flush()
+	}
+	if len(stats.TopByWords) > 0 {
+		fmt.Fprintln(out, "Most words:")
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, file := range stats.TopByWords {
+			fmt.Fprintf(w, "  %d\t%s\n", file.Value, file.Name)
+		}
+		w.Flush()
+	}
+}
+
+// printDirSummary writes a du-style table of per-directory totals, largest first
+func printDirSummary(out io.Writer, root string, results []models.ProcessResult) {
+	stats := utils.RollupDirs(utils.AggregateByDir(results), root)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SIZE\tFILES\tLINES\tWORDS\tDIRECTORY")
+	for _, entry := range utils.SortDirStats(stats) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n",
+			utils.FormatBytes(entry.Bytes), entry.Files, entry.Lines, entry.Words, entry.Dir)
+	}
+	w.Flush()
 }
 
 func init() {
+	analyzeCmd.Flags().BoolVar(&byDir, "by-dir", false, "print a per-directory summary sorted by size")
+	analyzeCmd.Flags().StringVar(&cacheFile, "cache", "", "reuse results for unchanged files from this cache file")
+	analyzeCmd.Flags().StringVar(&outputDir, "output-dir", "", "write report.html and a report-<type>.html per file type to this directory")
+	analyzeCmd.Flags().StringVar(&jsonReport, "json-report", "", "write a JSON report to this file, or - for stdout")
+	analyzeCmd.Flags().BoolVar(&jsonPretty, "json-pretty", true, "indent the JSON report (default compact with --ndjson or when piped to stdout)")
+	analyzeCmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "leave the per-file table out of reports, keeping only statistics and the type histogram")
+	analyzeCmd.Flags().IntVar(&maxFiles, "max-files", 0, "stop after this many files and report partial results (0 for no limit)")
+	analyzeCmd.Flags().Int64Var(&maxBytes, "max-bytes", 0, "stop once files totalling this many bytes were accepted and report partial results (0 for no limit)")
+	analyzeCmd.Flags().StringVar(&archiveEntry, "entry", "", "analyze only this file inside the zip or tar archive given as the path")
+	analyzeCmd.Flags().BoolVar(&ndjsonOutput, "ndjson", false, "stream one JSON result per file to stdout, ending with a {\"summary\": true} statistics line")
+	analyzeCmd.Flags().StringVar(&sqlitePath, "sqlite", "", "write one row per processed file to the files table of this SQLite database, replacing the table")
+	analyzeCmd.Flags().StringVar(&processOrder, "order", "", "list every matching file first and process them by size (largest first), mtime (newest first) or name; holds all matching paths in memory")
+	analyzeCmd.Flags().StringVar(&sortOutput, "sort-output", "", "hold per-file log lines and --ndjson results until the end and emit them sorted by path, size or name")
+	analyzeCmd.Flags().BoolVar(&noProgress, "no-progress", false, "don't draw the progress bar (it is only shown on a terminal)")
+	analyzeCmd.Flags().BoolVar(&noCache, "no-cache", false, "ignore the result cache for this run")
+	analyzeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "estimate the processing time from a small sample of files instead of analyzing")
+	analyzeCmd.Flags().BoolVar(&csvNoHeader, "csv-no-header", false, "treat CSV files as having no header row, so every row counts as data")
+	analyzeCmd.Flags().IntVar(&csvMaxField, "csv-max-field", 0, "fail CSV files with a field larger than this many bytes (0 for no limit)")
+	analyzeCmd.Flags().BoolVar(&csvTruncate, "csv-truncate", false, "truncate CSV fields over --csv-max-field and report them as anomalies instead of failing")
+	analyzeCmd.Flags().StringVar(&wordPattern, "word-pattern", "", "count matches of this regex as words in text files, e.g. '[[:alnum:]]+' (default: whitespace-separated)")
+	analyzeCmd.Flags().BoolVar(&duplicateLines, "duplicate-lines", false, "count unique vs duplicate lines in text files")
+	analyzeCmd.Flags().BoolVar(&scanSecrets, "secrets", false, "flag text lines that appear to contain secrets, such as AWS or private keys, as anomalies without the values")
+	analyzeCmd.Flags().StringArrayVar(&secretPatterns, "secret-pattern", nil, "with --secrets, also flag lines matching this regex, as name=regex (repeatable)")
+	analyzeCmd.Flags().Float64Var(&secretEntropy, "secret-entropy", processor.DefaultSecretEntropy, "with --secrets, flag tokens of 24+ characters above this many bits of entropy per character (0 disables)")
+	analyzeCmd.Flags().BoolVar(&indentation, "indentation", false, "report the dominant indentation of text files and flag lines mixing tabs and spaces")
+	analyzeCmd.Flags().BoolVar(&wcCompatible, "wc-compatible", false, "count text lines, words and bytes exactly as wc -l -w -c does")
+	analyzeCmd.Flags().BoolVar(&sniffContent, "sniff", false, "flag files whose content doesn't match their extension, such as an executable named .txt")
+	analyzeCmd.Flags().IntVar(&lineLength, "line-length", 0, "report line lengths and count text lines longer than this many characters, e.g. 120")
+	analyzeCmd.Flags().StringVar(&hashAlgo, "hash", "none", "compute a per-file checksum for reports while analyzing: none, md5 or sha256")
+	analyzeCmd.Flags().StringVar(&extractTextDir, "extract-text-dir", "", "write the text extracted from XML and HTML files to <dir>/<file path>.txt while analyzing")
+	analyzeCmd.Flags().StringVar(&jsonSchema, "json-schema", "", "validate JSON documents against this JSON Schema file")
+	analyzeCmd.Flags().StringArrayVar(&redactFields, "redact", nil, "redact a CSV column or JSON key before it reaches row callbacks or anomalies, as field=hash|mask|drop (repeatable)")
+	analyzeCmd.Flags().BoolVar(&jsonStructure, "json-structure", false, "record the nesting depth, object, array and scalar counts and keys per depth of JSON files")
+	analyzeCmd.Flags().BoolVar(&jsonKeys, "json-keys", false, "list the top-level keys of JSON files, with the --json-structure stats")
+	analyzeCmd.Flags().BoolVar(&strict, "strict", false, "abort on the first unreadable path instead of skipping it")
+	analyzeCmd.Flags().StringArrayVar(&textExtensions, "text-ext", nil, "additional extension to analyze as text, e.g. .dat (repeatable)")
+	analyzeCmd.Flags().Int64Var(&maxFileSize, "max-file-size", 0, "skip files larger than this many bytes (0 means unlimited)")
+	analyzeCmd.Flags().Int64Var(&maxDecompressed, "max-decompressed-size", processor.DefaultMaxDecompressedSize, "fail compressed files that decompress to more than this many bytes (0 means unlimited)")
+	analyzeCmd.Flags().StringArrayVar(&hookPlugins, "hook", nil, "run OnResult from a Go plugin (.so) after each file is processed (repeatable)")
+	analyzeCmd.Flags().BoolVar(&failOnHookError, "fail-on-hook-error", false, "abort the run when a result hook returns an error")
+	analyzeCmd.Flags().StringArrayVar(&plugins, "plugin", nil, "load an extra processor from a Go plugin (.so) exporting NewProcessor (repeatable)")
+	analyzeCmd.Flags().Float64Var(&sampleFraction, "sample", 1, "analyze each matching file with this probability (0-1] and extrapolate totals")
+	analyzeCmd.Flags().Int64Var(&sampleSeed, "sample-seed", 0, "seed for --sample so runs are reproducible (default: random)")
+	analyzeCmd.Flags().BoolVar(&warnEmpty, "warn-empty", false, "log empty and whitespace-only files and count them as a separate type")
+	analyzeCmd.Flags().StringVar(&pathsFrom, "paths-from", "", "analyze exactly the files listed in this file, one per line (# comments, - for stdin), instead of walking a path")
+	analyzeCmd.Flags().StringVar(&sinceRef, "since", "", "analyze only files under the path changed since this git ref, e.g. origin/main")
+	analyzeCmd.Flags().IntVar(&topFiles, "top", 0, "list the N largest files and the N with the most words in the summary and reports")
+	analyzeCmd.Flags().BoolVar(&requireMatches, "require-matches", false, "exit with code 4 when no file matches the filters, instead of only saying so")
+	analyzeCmd.Flags().DurationVar(&deadline, "deadline", 0, "wall-clock budget for the whole run, e.g. 30s (0 means no limit)")
+
+	hashCmd.Flags().BoolVar(&hashMmap, "mmap", false, "read files of 1 MiB or more through a memory map, which is faster for large local files")
+	hashCmd.Flags().Int64Var(&treeChunkSize, "tree-chunk-size", 0, "hash chunks of this many bytes in parallel as a Merkle tree (differs from plain SHA256)")
+
 	rootCmd.AddCommand(analyzeCmd)
 	rootCmd.AddCommand(hashCmd)
 	rootCmd.AddCommand(encodeCmd)
 	rootCmd.AddCommand(decodeCmd)
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(watchFileCmd)
+	rootCmd.AddCommand(describeCmd)
+	rootCmd.AddCommand(trendCmd)
+
+	// Replace cobra's default completion command with completionCmd
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	registerCompletions()
 }
 
 func Execute() error {
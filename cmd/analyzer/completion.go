@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// completionCmd writes a shell completion script to stdout
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script for the given shell and write it to stdout.
+
+To load completions in the current bash session:
+  source <(analyzer completion bash)
+
+To load them for every zsh session:
+  analyzer completion zsh > "${fpath[1]}/_analyzer"`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	// Completion must work without a config file, so skip the root setup
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := cmd.Root()
+		switch args[0] {
+		case "bash":
+			return root.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return root.GenZshCompletion(os.Stdout)
+		case "fish":
+			return root.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			return root.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+		return fmt.Errorf("unsupported shell: %s", args[0])
+	},
+}
+
+// completeValues returns a completion function offering a fixed set of values
+// Use it for flags that only accept an enumerated set of choices
+func completeValues(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// registerCompletions wires dynamic completion for arguments and flags
+func registerCompletions() {
+	// The analyze path is a directory
+	analyzeCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+
+	mustComplete(analyzeCmd.MarkFlagFilename("json-schema", "json"))
+	mustComplete(analyzeCmd.MarkFlagFilename("plugin", "so"))
+	mustComplete(analyzeCmd.MarkFlagFilename("hook", "so"))
+	mustComplete(analyzeCmd.MarkFlagFilename("json-report", "json"))
+	mustComplete(analyzeCmd.MarkFlagDirname("output-dir"))
+	mustComplete(analyzeCmd.RegisterFlagCompletionFunc("hash", completeValues(append([]string{"none"}, utils.HashAlgorithms...)...)))
+	mustComplete(analyzeCmd.RegisterFlagCompletionFunc("text-ext", completeValues(".dat", ".ini", ".cfg", ".yaml", ".toml")))
+
+	// trend takes JSON reports
+	trendCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveFilterFileExt
+	}
+}
+
+// mustComplete panics on completion registration errors, which are programming mistakes
+func mustComplete(err error) {
+	if err != nil {
+		panic(fmt.Sprintf("failed to register completion: %v", err))
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// outputFormats are the accepted values of output.format
+var outputFormats = []string{"text", "json", "csv"}
+
+// configCmd groups the config subcommands
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration file",
+}
+
+// configValidateCmd checks a config file without processing any files
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check a config file for invalid settings",
+	Long: `Load a config file, default the one given by --config, and check the types
+and ranges of its settings, including the filter patterns. Every offending key
+is listed, and the command fails if there is any.`,
+	Args: cobra.MaximumNArgs(1),
+	// An invalid config isn't a usage mistake
+	SilenceUsage: true,
+	// The file is loaded here, so a broken config can still be reported
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		setupLogging()
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configFile
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		v := viper.New()
+		setConfigDefaults(v)
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		problems := validateConfig(v)
+		printConfigProblems(cmd.OutOrStdout(), path, problems)
+		if len(problems) > 0 {
+			return fmt.Errorf("config file %s has %d invalid settings", path, len(problems))
+		}
+		return nil
+	},
+}
+
+// configProblem is an invalid setting found by validateConfig
+type configProblem struct {
+	key     string
+	message string
+}
+
+// configChecker accumulates the problems found in a config
+type configChecker struct {
+	v        *viper.Viper
+	problems []configProblem
+}
+
+// add records a problem with key
+func (c *configChecker) add(key, format string, args ...interface{}) {
+	c.problems = append(c.problems, configProblem{key: key, message: fmt.Sprintf(format, args...)})
+}
+
+// int returns the integer at key, reporting a problem if it has another type
+// The second result is false when the key is unset or invalid
+func (c *configChecker) int(key string) (int, bool) {
+	if !c.v.IsSet(key) {
+		return 0, false
+	}
+	switch value := c.v.Get(key).(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	default:
+		c.add(key, "must be an integer, got %v", value)
+		return 0, false
+	}
+}
+
+// string returns the string at key, reporting a problem if it has another type
+func (c *configChecker) string(key string) (string, bool) {
+	if !c.v.IsSet(key) {
+		return "", false
+	}
+	value, ok := c.v.Get(key).(string)
+	if !ok {
+		c.add(key, "must be a string, got %v", c.v.Get(key))
+	}
+	return value, ok
+}
+
+// bool reports a problem if key is set to something other than true or false
+func (c *configChecker) bool(key string) {
+	if c.v.IsSet(key) {
+		if _, ok := c.v.Get(key).(bool); !ok {
+			c.add(key, "must be true or false, got %v", c.v.Get(key))
+		}
+	}
+}
+
+// strings returns the list of strings at key, reporting a problem for a
+// value that isn't a list or an element that isn't a string
+func (c *configChecker) strings(key string) []string {
+	if !c.v.IsSet(key) || c.v.Get(key) == nil {
+		return nil
+	}
+	list, ok := c.v.Get(key).([]interface{})
+	if !ok {
+		c.add(key, "must be a list, got %v", c.v.Get(key))
+		return nil
+	}
+	var values []string
+	for i, item := range list {
+		value, ok := item.(string)
+		if !ok {
+			c.add(fmt.Sprintf("%s[%d]", key, i), "must be a string, got %v", item)
+			continue
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// validateConfig checks the types and ranges of every known setting in v
+func validateConfig(v *viper.Viper) []configProblem {
+	c := &configChecker{v: v}
+
+	maxBuffer, hasMax := c.int("processing.max_buffer_size")
+	if hasMax && maxBuffer <= 0 {
+		c.add("processing.max_buffer_size", "must be positive, got %d", maxBuffer)
+		hasMax = false
+	}
+	if buffer, ok := c.int("processing.buffer_size"); ok {
+		switch {
+		case buffer <= 0:
+			c.add("processing.buffer_size", "must be positive, got %d", buffer)
+		case hasMax && buffer > maxBuffer:
+			c.add("processing.buffer_size", "%d exceeds processing.max_buffer_size (%d)", buffer, maxBuffer)
+		}
+	}
+	if workers, ok := c.int("processing.max_concurrent"); ok && workers < 0 {
+		c.add("processing.max_concurrent", "must not be negative (0 means unlimited), got %d", workers)
+	}
+	if v.IsSet("processing.extensions") {
+		if types, ok := v.Get("processing.extensions").(map[string]interface{}); ok {
+			names := make([]string, 0, len(types))
+			for name := range types {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				key := "processing.extensions." + name
+				for i, ext := range c.strings(key) {
+					if !strings.HasPrefix(ext, ".") {
+						c.add(fmt.Sprintf("%s[%d]", key, i), "must start with a dot, got %q", ext)
+					}
+				}
+			}
+		} else {
+			c.add("processing.extensions", "must map processor names to extension lists")
+		}
+	}
+
+	var overrides []processor.ExtensionOverride
+	if err := v.UnmarshalKey("processing.overrides", &overrides); err != nil {
+		c.add("processing.overrides", "%v", err)
+	}
+	for i, override := range overrides {
+		if err := override.Validate(); err != nil {
+			c.add(fmt.Sprintf("processing.overrides[%d]", i), "%v", err)
+		}
+	}
+
+	if _, err := buildConfigFilter(v); err != nil {
+		c.add("filters", "%v", err)
+	}
+
+	if format, ok := c.string("output.format"); ok && !contains(outputFormats, format) {
+		c.add("output.format", "must be one of %s, got %q", strings.Join(outputFormats, ", "), format)
+	}
+	c.string("output.file")
+	c.bool("output.detailed")
+
+	if level, ok := c.string("logging.level"); ok {
+		if _, err := logrus.ParseLevel(level); err != nil {
+			c.add("logging.level", "%v", err)
+		}
+	}
+	c.string("logging.file")
+	c.bool("logging.timestamps")
+
+	c.strings("watch.directories")
+	if interval, ok := c.int("watch.interval"); ok && interval <= 0 {
+		c.add("watch.interval", "must be positive, got %d", interval)
+	}
+	for i, pattern := range c.strings("watch.ignore") {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			c.add(fmt.Sprintf("watch.ignore[%d]", i), "invalid glob %q: %v", pattern, err)
+		}
+	}
+
+	return c.problems
+}
+
+// contains reports whether values includes value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// printConfigProblems writes the verdict for the config file at path
+func printConfigProblems(out io.Writer, path string, problems []configProblem) {
+	if len(problems) == 0 {
+		fmt.Fprintf(out, "PASS %s\n", path)
+		return
+	}
+	fmt.Fprintf(out, "FAIL %s\n", path)
+	for _, problem := range problems {
+		fmt.Fprintf(out, "  %s: %s\n", problem.key, problem.message)
+	}
+}
+
+// buildConfigFilter builds the file filter described by v's filters
+// section; without one every file passes
+func buildConfigFilter(v *viper.Viper) (utils.FileFilter, error) {
+	var cfg utils.FilterConfig
+	if err := v.UnmarshalKey("filters", &cfg); err != nil {
+		return nil, fmt.Errorf("failed to read filters config: %w", err)
+	}
+	filter, err := cfg.Build(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters config: %w", err)
+	}
+	return filter, nil
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// loadTestConfig reads content as a YAML config with the usual defaults
+func loadTestConfig(t *testing.T, content string) *viper.Viper {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	v := viper.New()
+	setConfigDefaults(v)
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	return v
+}
+
+func TestValidateConfig(t *testing.T) {
+	// The shipped config is valid
+	v := viper.New()
+	setConfigDefaults(v)
+	v.SetConfigFile(filepath.Join("..", "..", "configs", "config.yaml"))
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if problems := validateConfig(v); len(problems) != 0 {
+		t.Errorf("Expected the shipped config to pass, got %v", problems)
+	}
+
+	v = loadTestConfig(t, `
+processing:
+  buffer_size: 0
+  max_concurrent: many
+  extensions:
+    text: [txt]
+  overrides:
+    - extensions: [.csv]
+      delimiter: ";;"
+filters:
+  exclude: ["(unclosed"]
+output:
+  format: xml
+logging:
+  level: loud
+watch:
+  interval: 0
+`)
+	keys := map[string]bool{}
+	for _, problem := range validateConfig(v) {
+		keys[problem.key] = true
+	}
+	for _, key := range []string{
+		"processing.buffer_size", "processing.max_concurrent", "processing.extensions.text[0]",
+		"processing.overrides[0]", "filters", "output.format", "logging.level", "watch.interval",
+	} {
+		if !keys[key] {
+			t.Errorf("Expected a problem with %s, got %v", key, keys)
+		}
+	}
+	if len(keys) != 8 {
+		t.Errorf("Expected 8 offending keys, got %v", keys)
+	}
+
+	// A buffer larger than the configured maximum is out of range
+	v = loadTestConfig(t, "processing:\n  buffer_size: 8192\n  max_buffer_size: 4096\n")
+	problems := validateConfig(v)
+	if len(problems) != 1 || !strings.Contains(problems[0].message, "max_buffer_size") {
+		t.Errorf("Expected the buffer size to exceed the maximum, got %v", problems)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// describeCmd lists the processors analyze would use
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "List the processors and the file extensions they analyze",
+	Long: `List the processors analyze would use, in the order they are tried,
+with the file extensions each one handles.
+
+The list reflects this build and its settings: --plugin and --text-ext
+take the same values as for analyze, and processing.overrides from the
+config file appear as processors of their own ahead of the default one.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		processors, err := buildProcessors(viper.GetInt("processing.buffer_size"))
+		if err != nil {
+			return err
+		}
+		printProcessors(cmd.OutOrStdout(), processor.Describe(processors))
+		return nil
+	},
+}
+
+// printProcessors writes one row per processor
+func printProcessors(out io.Writer, infos []processor.ProcessorInfo) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROCESSOR\tEXTENSIONS")
+	for _, info := range infos {
+		extensions := strings.Join(info.Extensions, ", ")
+		if extensions == "" {
+			extensions = "(decided by the processor)"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", info.Name, extensions)
+	}
+	w.Flush()
+}
+
+func init() {
+	describeCmd.Flags().StringArrayVar(&plugins, "plugin", nil, "include a processor from a Go plugin (.so) exporting NewProcessor (repeatable)")
+	describeCmd.Flags().StringArrayVar(&textExtensions, "text-ext", nil, "additional extension to analyze as text, e.g. .dat (repeatable)")
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// estimateSampleFiles is roughly how many files a dry run processes
+const estimateSampleFiles = 10
+
+// runEstimate is the outcome of a dry run
+type runEstimate struct {
+	totalFiles  int
+	totalBytes  int64
+	sampleFiles int
+	sampleBytes int64
+	sampleTime  time.Duration
+}
+
+// estimateRun counts and sizes the matching files, processes a random
+// handful of them, and measures how long they took
+func estimateRun(ctx context.Context, path string, processors []processor.Processor, filter utils.FileFilter, seed int64) (runEstimate, error) {
+	var est runEstimate
+	var err error
+	est.totalFiles, est.totalBytes, err = utils.CountAndSizeFiles(path, filter)
+	if err != nil {
+		return est, fmt.Errorf("failed to count files: %w", err)
+	}
+	if est.totalFiles == 0 {
+		return est, nil
+	}
+
+	fraction := float64(estimateSampleFiles) / float64(est.totalFiles)
+	if fraction > 1 {
+		fraction = 1
+	}
+	s := newSampler(fraction, seed)
+
+	err = utils.WalkFiles(path, filter, func(filePath string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !s.include() {
+			return nil
+		}
+
+		proc := handlerFor(processors, filePath)
+		if proc == nil {
+			return nil
+		}
+		start := time.Now()
+		result, err := proc.Process(ctx, filePath)
+		elapsed := time.Since(start)
+		if err != nil {
+			logrus.Debugf("Not sampling %s: %v", filePath, err)
+			return nil
+		}
+
+		est.sampleFiles++
+		est.sampleBytes += result.Size
+		est.sampleTime += elapsed
+		return nil
+	})
+	if err != nil {
+		return est, fmt.Errorf("failed to sample files: %w", err)
+	}
+	return est, nil
+}
+
+// duration extrapolates the sample's processing rate to every matching file
+// The rate is per byte; a sample of empty files falls back to a per-file rate
+func (e runEstimate) duration() time.Duration {
+	switch {
+	case e.sampleBytes > 0:
+		return time.Duration(float64(e.sampleTime) * float64(e.totalBytes) / float64(e.sampleBytes))
+	case e.sampleFiles > 0:
+		return time.Duration(float64(e.sampleTime) * float64(e.totalFiles) / float64(e.sampleFiles))
+	}
+	return 0
+}
+
+// printEstimate writes the dry-run totals and the estimated duration
+func printEstimate(out io.Writer, e runEstimate) {
+	fmt.Fprintln(out, "Dry run estimate:")
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  Matching files\t%d (%s)\n", e.totalFiles, utils.FormatBytes(e.totalBytes))
+	fmt.Fprintf(w, "  Sampled\t%d files (%s) in %v\n",
+		e.sampleFiles, utils.FormatBytes(e.sampleBytes), roundDuration(e.sampleTime))
+	if e.sampleFiles > 0 {
+		fmt.Fprintf(w, "  Estimated time\t~%v (approximate)\n", roundDuration(e.duration()))
+	} else {
+		fmt.Fprintf(w, "  Estimated time\tunknown (no file could be sampled)\n")
+	}
+	w.Flush()
+}
+
+// roundDuration keeps about three significant digits, so both seconds and
+// hours read naturally
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Hour:
+		return d.Round(time.Minute)
+	case d >= time.Minute:
+		return d.Round(time.Second)
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// handlerFor returns the first processor that handles path, or nil
+func handlerFor(processors []processor.Processor, path string) processor.Processor {
+	for _, p := range processors {
+		if p.CanHandle(path) {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunEstimateDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		est  runEstimate
+		want time.Duration
+	}{
+		{"per byte", runEstimate{totalFiles: 100, totalBytes: 10000, sampleFiles: 2, sampleBytes: 100, sampleTime: time.Second}, 100 * time.Second},
+		{"empty sample", runEstimate{totalFiles: 50, sampleFiles: 5, sampleTime: time.Second}, 10 * time.Second},
+		{"nothing sampled", runEstimate{totalFiles: 50, totalBytes: 10}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.est.duration(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+)
+
+// errLimitReached stops the walk once --max-files or --max-bytes is exceeded
+var errLimitReached = errors.New("walk limit reached")
+
+// walkLimits caps how many files, and how many bytes of them, a run accepts
+// A zero limit is unlimited; a nil *walkLimits accepts everything
+type walkLimits struct {
+	maxFiles int
+	maxBytes int64
+	files    int
+	bytes    int64
+}
+
+// newWalkLimits returns the limits for a run, or nil when neither is set
+func newWalkLimits(maxFiles int, maxBytes int64) (*walkLimits, error) {
+	if maxFiles < 0 {
+		return nil, fmt.Errorf("invalid --max-files value %d: must not be negative", maxFiles)
+	}
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("invalid --max-bytes value %d: must not be negative", maxBytes)
+	}
+	if maxFiles == 0 && maxBytes == 0 {
+		return nil, nil
+	}
+	return &walkLimits{maxFiles: maxFiles, maxBytes: maxBytes}, nil
+}
+
+// accept counts path against the limits, returning an error wrapping
+// errLimitReached instead once a limit has been used up
+// The file that crosses the byte limit is still accepted
+func (l *walkLimits) accept(path string) error {
+	if l == nil {
+		return nil
+	}
+	if l.maxFiles > 0 && l.files >= l.maxFiles {
+		return fmt.Errorf("%w: --max-files %d", errLimitReached, l.maxFiles)
+	}
+	if l.maxBytes > 0 && l.bytes >= l.maxBytes {
+		return fmt.Errorf("%w: --max-bytes %s after %d files", errLimitReached, utils.FormatBytes(l.maxBytes), l.files)
+	}
+
+	l.files++
+	if l.maxBytes > 0 {
+		if info, err := os.Stat(path); err == nil {
+			l.bytes += info.Size()
+		}
+	}
+	return nil
+}
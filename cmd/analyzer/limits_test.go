@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWalkLimits(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "ten.txt")
+	if err := os.WriteFile(file, []byte("0123456789"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// No limits at all is nil and accepts everything
+	none, err := newWalkLimits(0, 0)
+	if err != nil || none != nil || none.accept(file) != nil {
+		t.Errorf("Expected no limits, got %+v, %v", none, err)
+	}
+	if _, err := newWalkLimits(-1, 0); err == nil {
+		t.Error("Expected an error for a negative --max-files")
+	}
+
+	files, _ := newWalkLimits(2, 0)
+	for i := 0; i < 2; i++ {
+		if err := files.accept(file); err != nil {
+			t.Fatalf("Expected file %d to be accepted, got %v", i+1, err)
+		}
+	}
+	if err := files.accept(file); !errors.Is(err, errLimitReached) {
+		t.Errorf("Expected errLimitReached after 2 files, got %v", err)
+	}
+
+	// The file crossing the byte limit is accepted, the next one is not
+	bytes, _ := newWalkLimits(0, 15)
+	for i := 0; i < 2; i++ {
+		if err := bytes.accept(file); err != nil {
+			t.Fatalf("Expected file %d to be accepted, got %v", i+1, err)
+		}
+	}
+	if err := bytes.accept(file); !errors.Is(err, errLimitReached) {
+		t.Errorf("Expected errLimitReached after 20 bytes, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,6 +19,10 @@ var (
 	// Debug mode flag
 	debugMode bool
 
+	// verbosity is the number of -v flags; quietMode limits output to errors
+	verbosity int
+	quietMode bool
+
 	// Version information
 	version = "1.0.0"
 )
@@ -25,54 +30,89 @@ var (
 // Constants demonstration
 const (
 	// Default configuration values
-	defaultConfigPath = "configs/config.yaml"
-	defaultBufferSize = 4096
+	defaultConfigPath    = "configs/config.yaml"
+	defaultBufferSize    = 4096
+	defaultMaxBufferSize = 1 << 20
 
 	// File processing modes
 	ModeSingle  = "single"
 	ModeWatch   = "watch"
 	ModeAnalyze = "analyze"
+
+	// Process exit codes
+	exitCodeError     = 1
+	exitCodeDeadline  = 2
+	exitCodeLimit     = 3
+	exitCodeNoMatches = 4
 )
 
+// exitError carries a specific process exit code out of a command
+type exitError struct {
+	code int
+	err  error
+}
+
+// Error implements the error interface
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap exposes the underlying error
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
 func main() {
-	// Initialize the root command
-	rootCmd := &cobra.Command{
-		Use:   "analyzer",
-		Short: "File Analytics System",
-		Long: `A robust file processing and analytics system that demonstrates 
-		various Go programming concepts while providing useful file analysis capabilities.`,
-		Version: version,
-		RunE:    run,
+	// Configure the root command; subcommands are registered in commands.go
+	rootCmd.Short = "File Analytics System"
+	rootCmd.Long = `A robust file processing and analytics system that demonstrates 
+		various Go programming concepts while providing useful file analysis capabilities.`
+	rootCmd.Version = version
+	rootCmd.RunE = run
+	// Errors are reported once by main, with the matching exit code
+	rootCmd.SilenceErrors = true
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Shell completion requests must work without a config file
+		if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+			return nil
+		}
+		if err := initConfig(); err != nil {
+			return fmt.Errorf("failed to initialize config: %w", err)
+		}
+		setupLogging()
+		return nil
 	}
 
 	// Command-line flags demonstration
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", defaultConfigPath, "config file path")
 	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "enable debug mode")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "increase log verbosity: -v info (per-file results), -vv debug, -vvv trace")
+	rootCmd.PersistentFlags().BoolVarP(&quietMode, "quiet", "q", false, "only log errors")
 
 	// Execute the root command
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+
+		// Demonstrates errors.As for exit code selection
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		os.Exit(exitCodeError)
 	}
 }
 
 // run implements the main logic of the application
 // Demonstrates multiple return values
 func run(cmd *cobra.Command, args []string) error {
-	// Initialize configuration
-	if err := initConfig(); err != nil {
-		return fmt.Errorf("failed to initialize config: %w", err)
-	}
-
-	// Setup logging
-	setupLogging()
-
 	logrus.Info("File Analytics System started")
 	return nil
 }
 
 // initConfig demonstrates error handling and file operations
 func initConfig() error {
+	setConfigDefaults(viper.GetViper())
+
 	if configFile != "" {
 		// Get the absolute path
 		absPath, err := filepath.Abs(configFile)
@@ -102,15 +142,37 @@ func initConfig() error {
 	return nil
 }
 
+// setConfigDefaults sets the values that apply when the config file omits
+// a key or is missing
+func setConfigDefaults(v *viper.Viper) {
+	v.SetDefault("processing.buffer_size", defaultBufferSize)
+	v.SetDefault("processing.max_buffer_size", defaultMaxBufferSize)
+}
+
+// logLevel maps the verbosity flags to a logrus level
+// Without flags only warnings and errors are logged; each -v adds a level
+// (info, debug, trace). --quiet wins over -v, and both win over --debug
+func logLevel(verbosity int, quiet, debug bool) logrus.Level {
+	switch {
+	case quiet:
+		return logrus.ErrorLevel
+	case verbosity >= 3:
+		return logrus.TraceLevel
+	case verbosity == 2:
+		return logrus.DebugLevel
+	case verbosity == 1:
+		return logrus.InfoLevel
+	case debug:
+		return logrus.DebugLevel
+	default:
+		return logrus.WarnLevel
+	}
+}
+
 // setupLogging demonstrates conditional logic and configuration
 func setupLogging() {
-	// If/else demonstration
-	if debugMode {
-		logrus.SetLevel(logrus.DebugLevel)
-		logrus.Debug("Debug mode enabled")
-	} else {
-		logrus.SetLevel(logrus.InfoLevel)
-	}
+	logrus.SetLevel(logLevel(verbosity, quietMode, debugMode))
+	logrus.Debugf("Log level set to %s", logrus.GetLevel())
 
 	// Customize logging format
 	logrus.SetFormatter(&logrus.TextFormatter{
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogLevel(t *testing.T) {
+	tests := []struct {
+		verbosity int
+		quiet     bool
+		debug     bool
+		want      logrus.Level
+	}{
+		{0, false, false, logrus.WarnLevel},
+		{1, false, false, logrus.InfoLevel},
+		{2, false, false, logrus.DebugLevel},
+		{3, false, false, logrus.TraceLevel},
+		{5, false, false, logrus.TraceLevel},
+		{0, false, true, logrus.DebugLevel},
+		// -v overrides --debug
+		{1, false, true, logrus.InfoLevel},
+		// --quiet overrides everything
+		{0, true, false, logrus.ErrorLevel},
+		{3, true, true, logrus.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		if got := logLevel(tt.verbosity, tt.quiet, tt.debug); got != tt.want {
+			t.Errorf("logLevel(%d, %v, %v) = %s, want %s", tt.verbosity, tt.quiet, tt.debug, got, tt.want)
+		}
+	}
+}
+
+func TestNoMatches(t *testing.T) {
+	if n := matchedFiles(templates.Statistics{}, nil); n != 0 {
+		t.Errorf("Expected no matches for an empty run, got %d", n)
+	}
+	// Failed and vanished files still matched
+	if n := matchedFiles(templates.Statistics{TotalFiles: 1, VanishedCount: 1}, nil); n != 2 {
+		t.Errorf("Expected 2 matches, got %d", n)
+	}
+	// So did files left out of a sample
+	if n := matchedFiles(templates.Statistics{}, &sampler{matched: 3}); n != 3 {
+		t.Errorf("Expected 3 sampled matches, got %d", n)
+	}
+
+	text := processor.NewTextProcessor(4096)
+	text.AddExtension(".csv")
+	got := analyzedExtensions([]processor.Processor{text, processor.NewCSVProcessor(4096)})
+	if want := ".txt .log .md .csv .tsv"; strings.Join(got, " ") != want {
+		t.Errorf("Expected extensions %q, got %q", want, got)
+	}
+}
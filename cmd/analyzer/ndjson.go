@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+)
+
+// ndjsonSummary is the last line of --ndjson output; consumers tell it apart
+// from the per-file results by its summary field
+type ndjsonSummary struct {
+	Summary bool `json:"summary"`
+	// Partial says why the run stopped early, when it did
+	Partial    string               `json:"partial,omitempty"`
+	Statistics templates.Statistics `json:"statistics"`
+}
+
+// ndjsonWriter streams one JSON object per processed file, then a summary
+// A nil *ndjsonWriter writes nothing
+type ndjsonWriter struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	summary bool
+}
+
+// newNDJSONWriter creates a writer streaming to out
+func newNDJSONWriter(out io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{enc: json.NewEncoder(out)}
+}
+
+// write emits result as a single line
+func (w *ndjsonWriter) write(result models.ProcessResult) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(result); err != nil {
+		return fmt.Errorf("failed to write NDJSON result: %w", err)
+	}
+	return nil
+}
+
+// writeSummary emits the closing summary line once; a non-empty note marks
+// the statistics as partial
+func (w *ndjsonWriter) writeSummary(stats templates.Statistics, note string) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.summary {
+		return nil
+	}
+	w.summary = true
+	if err := w.enc.Encode(ndjsonSummary{Summary: true, Partial: note, Statistics: stats}); err != nil {
+		return fmt.Errorf("failed to write NDJSON summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newNDJSONWriter(&out)
+	w.write(models.ProcessResult{FileInfo: models.FileInfo{Path: "a.txt"}, Words: 3})
+	w.write(models.ProcessResult{FileInfo: models.FileInfo{Path: "b.txt"}, Words: 1})
+	w.writeSummary(templates.Statistics{TotalFiles: 2, TotalWords: 4}, "run was interrupted")
+	w.writeSummary(templates.Statistics{}, "")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 2 results and 1 summary line, got:\n%s", out.String())
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &result); err != nil {
+		t.Fatalf("Failed to parse result line: %v", err)
+	}
+	if _, ok := result["summary"]; ok || result["path"] != "a.txt" {
+		t.Errorf("Unexpected result line: %s", lines[0])
+	}
+
+	var summary ndjsonSummary
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+		t.Fatalf("Failed to parse summary line: %v", err)
+	}
+	if !summary.Summary || summary.Partial != "run was interrupted" || summary.Statistics.TotalWords != 4 {
+		t.Errorf("Unexpected summary line: %s", lines[2])
+	}
+
+	// Without --ndjson the writer is nil and silent
+	var disabled *ndjsonWriter
+	if disabled.write(models.ProcessResult{}) != nil || disabled.writeSummary(templates.Statistics{}, "") != nil {
+		t.Error("Expected a nil writer to do nothing")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// checkOrderKey validates a --order value; empty keeps walk order
+func checkOrderKey(key string) error {
+	switch key {
+	case "", "size", "mtime", "name":
+		return nil
+	}
+	return fmt.Errorf("invalid --order value %q: must be size, mtime or name", key)
+}
+
+// enumerateFiles lists every file under path that passes the filter, so
+// they can be ordered before any is processed
+// The whole list is held in memory, one path per matching file, instead of
+// handing each file over as the walk reaches it
+func enumerateFiles(ctx context.Context, path string, opts analyzeOptions, strict bool) ([]string, error) {
+	files := []string{}
+	collect := func(filePath string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		files = append(files, filePath)
+		return nil
+	}
+
+	if strict {
+		err := utils.WalkFiles(path, opts.filter, collect)
+		return files, err
+	}
+	skipped, err := utils.WalkFilesLenient(path, opts.filter, collect)
+	recordSkipped(opts, skipped)
+	return files, err
+}
+
+// recordSkipped logs the paths a lenient walk couldn't read and counts them
+// as errors
+func recordSkipped(opts analyzeOptions, skipped *apperrors.ErrorCollection) {
+	for _, walkErr := range skipped.Errors() {
+		logrus.Warnf("%v", walkErr)
+		var processErr *apperrors.ProcessError
+		if apperrors.As(walkErr, &processErr) {
+			opts.stats.AddError(processErr.File, processErr.Cause)
+		}
+	}
+}
+
+// orderedFile is a file to be processed with the attributes --order sorts by
+type orderedFile struct {
+	path     string
+	size     int64
+	modified time.Time
+}
+
+// orderFiles sorts files by key: size (largest first), mtime (newest first)
+// or name (base name, then path); ties and files that can't be stat'ed fall
+// back to path order
+func orderFiles(files []string, key string) []string {
+	entries := make([]orderedFile, len(files))
+	for i, file := range files {
+		entries[i].path = file
+		if info, err := os.Stat(file); err == nil {
+			entries[i].size, entries[i].modified = info.Size(), info.ModTime()
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if key == "mtime" && !a.modified.Equal(b.modified) {
+			return a.modified.After(b.modified)
+		}
+		return outputLess(key, a.path, a.size, b.path, b.size)
+	})
+
+	ordered := make([]string, len(entries))
+	for i, entry := range entries {
+		ordered[i] = entry.path
+	}
+	return ordered
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+)
+
+func TestOrderFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []struct {
+		name    string
+		content string
+		age     time.Duration
+	}{
+		{"a/small.txt", "1", time.Hour},
+		{"b/large.txt", "1234567890", 2 * time.Hour},
+		{"c/medium.txt", "12345", 0},
+	}
+
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(f.content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		modified := time.Now().Add(-f.age)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+
+	processors := []processor.Processor{processor.NewTextProcessor(4096)}
+	opts := analyzeOptions{
+		filter: handledByAny(processors),
+		stats:  templates.NewStatsAccumulator(),
+	}
+	walked, err := enumerateFiles(context.Background(), dir, opts, false)
+	if err != nil {
+		t.Fatalf("Failed to enumerate files: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"size", []string{"b/large.txt", "c/medium.txt", "a/small.txt"}},
+		{"mtime", []string{"c/medium.txt", "a/small.txt", "b/large.txt"}},
+		{"name", []string{"b/large.txt", "c/medium.txt", "a/small.txt"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, path := range orderFiles(walked, tt.key) {
+			rel, _ := filepath.Rel(dir, path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("--order %s: expected %v, got %v", tt.key, tt.want, got)
+		}
+	}
+
+	// The processed results follow the listed order
+	opts.files = orderFiles(walked, "size")
+	results, err := processFiles(context.Background(), dir, processors, opts)
+	if err != nil {
+		t.Fatalf("Failed to process files: %v", err)
+	}
+	if len(results) != 3 || results[0].Size != 10 || results[2].Size != 1 {
+		t.Errorf("Expected results largest first, got %+v", results)
+	}
+
+	if err := checkOrderKey("path"); err == nil {
+		t.Error("Expected an error for an unknown order key")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readPathList reads the --paths-from list at name, or from stdin for "-":
+// one path per line, skipping blank lines and lines starting with #
+// Paths are used as written, so relative ones are relative to the working
+// directory
+func readPathList(name string, stdin io.Reader) ([]string, error) {
+	in := stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open path list: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	// Non-nil even when empty, so the run doesn't fall back to walking
+	paths := []string{}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read path list: %w", err)
+	}
+	return paths, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+)
+
+func TestReadPathList(t *testing.T) {
+	list := "# nightly manifest\n\ndata/a.txt\n  data/b.csv  \r\n#data/skipped.txt\n"
+	want := []string{"data/a.txt", "data/b.csv"}
+
+	paths, err := readPathList("-", strings.NewReader(list))
+	if err != nil {
+		t.Fatalf("Failed to read path list: %v", err)
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected %v from stdin, got %v", want, paths)
+	}
+
+	file := filepath.Join(t.TempDir(), "files.txt")
+	if err := os.WriteFile(file, []byte(list), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if paths, err = readPathList(file, nil); err != nil || !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected %v from the file, got %v (%v)", want, paths, err)
+	}
+
+	// An empty list is still a list, not a request to walk
+	if paths, err := readPathList("-", strings.NewReader("# nothing\n")); err != nil || paths == nil || len(paths) != 0 {
+		t.Errorf("Expected an empty non-nil list, got %#v (%v)", paths, err)
+	}
+
+	if _, err := readPathList(filepath.Join(t.TempDir(), "missing.txt"), nil); err == nil {
+		t.Error("Expected an error for a missing list")
+	}
+}
+
+func TestProcessListedFiles(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "present.txt")
+	if err := os.WriteFile(present, []byte("one two\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processors := []processor.Processor{processor.NewTextProcessor(4096)}
+	opts := analyzeOptions{
+		filter: handledByAny(processors),
+		stats:  templates.NewStatsAccumulator(),
+		files:  []string{present, filepath.Join(dir, "missing.txt"), dir},
+	}
+	results, err := processFiles(context.Background(), dir, processors, opts)
+	if err != nil {
+		t.Fatalf("Expected missing paths not to abort the run, got %v", err)
+	}
+	if len(results) != 1 || results[0].Path != present {
+		t.Errorf("Expected only %s to be analyzed, got %+v", present, results)
+	}
+	if stats := opts.stats.Statistics(); stats.ErrorCount != 2 {
+		t.Errorf("Expected the missing file and the directory counted as errors, got %d", stats.ErrorCount)
+	}
+}
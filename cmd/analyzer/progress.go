@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// runProgress draws an overall progress bar on stderr during an analyze run
+// A nil *runProgress is a disabled bar, so callers needn't check
+type runProgress struct {
+	bar      *progressbar.ProgressBar
+	level    logrus.Level
+	logOut   io.Writer
+	finished bool
+}
+
+// progressEnabled reports whether a bar should be drawn: both stdout and
+// stderr must be terminals, so redirected output stays free of escape codes
+func progressEnabled(disabled bool) bool {
+	return !disabled &&
+		term.IsTerminal(int(os.Stdout.Fd())) &&
+		term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// startProgress starts a bar counting up to total files
+// While it runs, per-file info logs are suppressed and remaining log lines
+// clear the bar before printing so the two don't interleave
+func startProgress(total int) *runProgress {
+	bar := progressbar.NewOptions(total,
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionSetDescription("Analyzing"),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetPredictTime(true),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionClearOnFinish(),
+	)
+
+	p := &runProgress{
+		bar:    bar,
+		level:  logrus.GetLevel(),
+		logOut: logrus.StandardLogger().Out,
+	}
+	if p.level > logrus.WarnLevel {
+		logrus.SetLevel(logrus.WarnLevel)
+	}
+	logrus.SetOutput(&barLogWriter{bar: bar, out: p.logOut})
+	return p
+}
+
+// Add advances the bar by one file
+func (p *runProgress) Add() {
+	if p == nil || p.finished {
+		return
+	}
+	p.bar.Add(1)
+}
+
+// Finish removes the bar and restores logging; it is safe to call twice
+func (p *runProgress) Finish() {
+	if p == nil || p.finished {
+		return
+	}
+	p.finished = true
+	p.bar.Finish()
+	logrus.SetOutput(p.logOut)
+	logrus.SetLevel(p.level)
+}
+
+// barLogWriter clears the progress bar before each log line; the bar is
+// redrawn on its next update
+type barLogWriter struct {
+	bar *progressbar.ProgressBar
+	out io.Writer
+}
+
+// Write implements io.Writer
+func (w *barLogWriter) Write(p []byte) (int, error) {
+	w.bar.Clear()
+	return w.out.Write(p)
+}
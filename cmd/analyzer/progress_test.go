@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRunProgressRestoresLogging(t *testing.T) {
+	var out bytes.Buffer
+	logrus.SetOutput(&out)
+	logrus.SetLevel(logrus.InfoLevel)
+	defer logrus.SetOutput(os.Stderr)
+
+	// A disabled bar is nil and must be safe to use
+	var disabled *runProgress
+	disabled.Add()
+	disabled.Finish()
+
+	p := startProgress(2)
+	if logrus.GetLevel() != logrus.WarnLevel {
+		t.Errorf("expected info logs to be suppressed, level is %v", logrus.GetLevel())
+	}
+	logrus.Info("hidden")
+	p.Add()
+	p.Finish()
+	p.Finish()
+
+	if logrus.GetLevel() != logrus.InfoLevel {
+		t.Errorf("expected level to be restored to info, got %v", logrus.GetLevel())
+	}
+	if logrus.StandardLogger().Out != &out {
+		t.Error("expected log output to be restored")
+	}
+	if bytes.Contains(out.Bytes(), []byte("hidden")) {
+		t.Error("expected info log to be suppressed while the bar is active")
+	}
+}
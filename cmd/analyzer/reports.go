@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// reportTitle is the title of the overall HTML report
+const reportTitle = "File Analysis Report"
+
+// writeReports writes the overall report to dir/report.html and one
+// report-<type>.html per file type, returning the paths written
+func writeReports(dir string, data templates.ReportData) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var written []string
+	write := func(name string, report templates.ReportData) error {
+		html, err := templates.GenerateHTMLReport(report)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		path := filepath.Join(dir, name)
+		if err := utils.WriteFileAtomic(path, []byte(html), 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		logrus.Debugf("Wrote %s", path)
+		written = append(written, path)
+		return nil
+	}
+
+	if err := write("report.html", data); err != nil {
+		return written, err
+	}
+
+	// Sorted so the files are written, and listed, in a stable order
+	byType := data.SplitByType()
+	types := make([]string, 0, len(byType))
+	for fileType := range byType {
+		types = append(types, fileType)
+	}
+	sort.Strings(types)
+
+	for _, fileType := range types {
+		if err := write(reportFileName(fileType), byType[fileType]); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// writeJSONReport writes data as a JSON report to path, or to stdout for "-"
+func writeJSONReport(path string, data templates.ReportData, pretty bool) error {
+	report, err := templates.EncodeJSONReport(data, pretty)
+	if err != nil {
+		return fmt.Errorf("failed to render JSON report: %w", err)
+	}
+
+	if path == "-" {
+		_, err = fmt.Fprintln(os.Stdout, report)
+	} else {
+		err = utils.WriteFileAtomic(path, []byte(report+"\n"), 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	return nil
+}
+
+// jsonReportPretty decides whether the JSON report is indented: --json-pretty
+// wins when given, otherwise the report is compact alongside --ndjson output
+// or when piped from stdout
+func jsonReportPretty(cmd *cobra.Command) bool {
+	switch {
+	case cmd.Flags().Changed("json-pretty"):
+		return jsonPretty
+	case ndjsonOutput:
+		return false
+	case jsonReport == "-":
+		return term.IsTerminal(int(os.Stdout.Fd()))
+	}
+	return jsonPretty
+}
+
+// summaryWriter returns where the text summary goes, moving it to stderr
+// when stdout carries the JSON report or NDJSON results
+func summaryWriter() io.Writer {
+	if jsonReport == "-" || ndjsonOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// reportFileName returns the per-type report file name, replacing characters
+// a plugin's type name could use to escape the output directory
+func reportFileName(fileType string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, fileType)
+	if safe == "" {
+		safe = "unknown"
+	}
+	return "report-" + safe + ".html"
+}
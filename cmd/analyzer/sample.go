@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"text/tabwriter"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+)
+
+// sampler picks a random subset of the matching files for a sampled run
+// It is used from the sequential walk callback, so it needs no locking
+type sampler struct {
+	fraction float64
+	rng      *rand.Rand
+	matched  int
+	selected int
+}
+
+// newSampler creates a sampler that includes each file with probability fraction
+func newSampler(fraction float64, seed int64) *sampler {
+	return &sampler{
+		fraction: fraction,
+		rng:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// include records a matching file and reports whether it is in the sample
+func (s *sampler) include() bool {
+	s.matched++
+	if s.rng.Float64() < s.fraction {
+		s.selected++
+		return true
+	}
+	return false
+}
+
+// scale is the factor that extrapolates sample totals to all matching files
+func (s *sampler) scale() float64 {
+	if s.selected == 0 {
+		return 0
+	}
+	return float64(s.matched) / float64(s.selected)
+}
+
+// printSampleSummary writes the sample size and totals extrapolated from it
+func printSampleSummary(out io.Writer, s *sampler, stats templates.Statistics) {
+	scale := s.scale()
+	fmt.Fprintf(out, "Sample: %d of %d matching files (%.1f%%)\n",
+		s.selected, s.matched, percent(s.selected, s.matched))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  Estimated size\t%s\n", utils.FormatBytes(int64(float64(stats.TotalSize)*scale)))
+	fmt.Fprintf(w, "  Estimated lines\t%.0f\n", float64(stats.TotalLines)*scale)
+	fmt.Fprintf(w, "  Estimated words\t%.0f\n", float64(stats.TotalWords)*scale)
+	w.Flush()
+}
+
+// percent returns part as a percentage of total, or 0 when total is 0
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
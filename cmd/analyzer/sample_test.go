@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestSampler(t *testing.T) {
+	run := func(seed int64) []bool {
+		s := newSampler(0.1, seed)
+		picks := make([]bool, 10000)
+		for i := range picks {
+			picks[i] = s.include()
+		}
+		if s.matched != 10000 {
+			t.Fatalf("Expected 10000 matched files, got %d", s.matched)
+		}
+		if s.selected < 800 || s.selected > 1200 {
+			t.Errorf("Expected roughly 10%% selected, got %d", s.selected)
+		}
+		if scale := s.scale(); scale < 8 || scale > 12.5 {
+			t.Errorf("Expected a scale near 10, got %v", scale)
+		}
+		return picks
+	}
+
+	// The same seed must select the same files
+	first, second := run(42), run(42)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Seeded runs diverged at file %d", i)
+		}
+	}
+
+	if scale := newSampler(0.5, 1).scale(); scale != 0 {
+		t.Errorf("Expected scale 0 for an empty sample, got %v", scale)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// changedFiles returns the files under path that differ from the git ref,
+// as paths starting with path like the walk produces
+// Committed, staged and unstaged changes all count; deleted and untracked
+// files don't
+func changedFiles(ctx context.Context, path, ref string) ([]string, error) {
+	target, err := filepath.Abs(path)
+	if err == nil {
+		target, err = filepath.EvalSymlinks(target)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	dir := target
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		dir = filepath.Dir(target)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("--since needs git: %w", err)
+	}
+	root, err := gitOutput(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("--since needs a git repository, but %s is not in one: %w", path, err)
+	}
+	root = strings.TrimSpace(root)
+	if _, err := gitOutput(ctx, root, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("invalid --since ref %q: not a commit in %s", ref, root)
+	}
+
+	out, err := gitOutput(ctx, root, "diff", "--name-only", "-z", "--diff-filter=d", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed since %s: %w", ref, err)
+	}
+
+	// Non-nil even when nothing changed, so the run doesn't fall back to walking
+	files := []string{}
+	for _, name := range strings.Split(out, "\x00") {
+		if name == "" {
+			continue
+		}
+		rel, err := filepath.Rel(target, filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		files = append(files, filepath.Join(path, rel))
+	}
+	return files, nil
+}
+
+// gitOutput runs git in dir and returns its output; failures carry git's
+// own message
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
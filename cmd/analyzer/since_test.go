@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if _, err := gitOutput(ctx, repo, args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	git("init", "-q")
+	write("docs/a.txt", "one")
+	write("docs/b.txt", "two")
+	write("root.txt", "three")
+	write("gone.txt", "four")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	git("tag", "base")
+
+	// A committed change, an unstaged one and a deletion
+	write("docs/a.txt", "one more")
+	git("commit", "-q", "-am", "change")
+	write("root.txt", "three more")
+	if err := os.Remove(filepath.Join(repo, "gone.txt")); err != nil {
+		t.Fatalf("Failed to delete test file: %v", err)
+	}
+
+	files, err := changedFiles(ctx, repo, "base")
+	if err != nil {
+		t.Fatalf("Failed to list changed files: %v", err)
+	}
+	want := []string{filepath.Join(repo, "docs", "a.txt"), filepath.Join(repo, "root.txt")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+
+	// Only files under the analyzed path are kept
+	docs := filepath.Join(repo, "docs")
+	if files, err := changedFiles(ctx, docs, "base"); err != nil || !reflect.DeepEqual(files, want[:1]) {
+		t.Errorf("Expected %v under docs, got %v (%v)", want[:1], files, err)
+	}
+
+	if _, err := changedFiles(ctx, repo, "no-such-ref"); err == nil {
+		t.Error("Expected an error for an unknown ref")
+	}
+	if _, err := changedFiles(ctx, t.TempDir(), "base"); err == nil {
+		t.Error("Expected an error outside a repository")
+	}
+}
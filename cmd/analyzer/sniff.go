@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// contentMismatches collects the files whose content contradicts their
+// extension, for --sniff
+// A nil *contentMismatches checks nothing
+type contentMismatches struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+// check sniffs path and returns the mismatch description, or "" when its
+// content matches its extension or it can't be read
+func (m *contentMismatches) check(path string) string {
+	if m == nil {
+		return ""
+	}
+	detail, err := processor.ContentMismatch(path)
+	if err != nil {
+		logrus.Debugf("Failed to sniff %s: %v", path, err)
+		return ""
+	}
+	if detail != "" {
+		logrus.Warnf("Content mismatch: %s: %s", path, detail)
+		m.mu.Lock()
+		m.entries = append(m.entries, path+": "+detail)
+		m.mu.Unlock()
+	}
+	return detail
+}
+
+// addMismatch records a content mismatch found by check on result
+func addMismatch(result *models.ProcessResult, detail string) {
+	if detail != "" {
+		result.AddAnomaly("content_mismatch", detail, 0)
+	}
+}
+
+// print lists the mismatched files after the summary, if there were any
+func (m *contentMismatches) print(out io.Writer) {
+	if m == nil || len(m.entries) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\nWARNING: %d files with content that doesn't match their extension:\n", len(m.entries))
+	for _, entry := range m.entries {
+		fmt.Fprintf(out, "  %s\n", entry)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+)
+
+// checkSortKey validates a --sort-output value; empty keeps walk order
+func checkSortKey(key string) error {
+	switch key {
+	case "", "path", "size", "name":
+		return nil
+	}
+	return fmt.Errorf("invalid --sort-output value %q: must be path, size or name", key)
+}
+
+// outputLess orders two files by key: path, name (base name, then path) or
+// size (largest first, then path)
+func outputLess(key string, pathA string, sizeA int64, pathB string, sizeB int64) bool {
+	switch key {
+	case "size":
+		if sizeA != sizeB {
+			return sizeA > sizeB
+		}
+	case "name":
+		if a, b := filepath.Base(pathA), filepath.Base(pathB); a != b {
+			return a < b
+		}
+	}
+	return pathA < pathB
+}
+
+// sortResults orders results by key
+func sortResults(results []models.ProcessResult, key string) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return outputLess(key, results[i].Path, results[i].Size, results[j].Path, results[j].Size)
+	})
+}
+
+// sortReport orders the report's files by key and its errors by path, so
+// reports from separate runs over the same tree list files identically
+func sortReport(report *templates.ReportData, key string) {
+	sort.SliceStable(report.Files, func(i, j int) bool {
+		a, b := report.Files[i], report.Files[j]
+		return outputLess(key, a.Name, a.Size, b.Name, b.Size)
+	})
+	sort.Strings(report.Errors)
+}
+
+// sortedOutput holds the per-file log lines and --ndjson results back until
+// the walk ends, then emits them ordered by key
+// A nil *sortedOutput holds nothing, so results are emitted as they arrive
+type sortedOutput struct {
+	key     string
+	mu      sync.Mutex
+	results []models.ProcessResult
+	// logged marks the results whose log lines were held back
+	logged map[string]bool
+}
+
+// newSortedOutput creates a buffer emitting in key order
+func newSortedOutput(key string) *sortedOutput {
+	return &sortedOutput{key: key, logged: make(map[string]bool)}
+}
+
+// hold keeps result until flush, logging it then when log is set
+func (s *sortedOutput) hold(result models.ProcessResult, log bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, result)
+	if log {
+		s.logged[result.Path] = true
+	}
+}
+
+// flush logs and streams the held results in order
+func (s *sortedOutput) flush(ndjson *ndjsonWriter) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sortResults(s.results, s.key)
+	for _, result := range s.results {
+		if s.logged[result.Path] {
+			logResult(result)
+		}
+		if err := ndjson.write(result); err != nil {
+			return err
+		}
+	}
+	s.results = nil
+	return nil
+}
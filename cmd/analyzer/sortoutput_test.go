@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+func TestSortedOutput(t *testing.T) {
+	results := []models.ProcessResult{
+		{FileInfo: models.FileInfo{Path: "b/a.txt", Size: 10}},
+		{FileInfo: models.FileInfo{Path: "a/c.txt", Size: 30}},
+		{FileInfo: models.FileInfo{Path: "c/b.txt", Size: 10}},
+		{FileInfo: models.FileInfo{Path: "a/b.txt", Size: 20}},
+	}
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"path", []string{"a/b.txt", "a/c.txt", "b/a.txt", "c/b.txt"}},
+		{"name", []string{"b/a.txt", "a/b.txt", "c/b.txt", "a/c.txt"}},
+		{"size", []string{"a/c.txt", "a/b.txt", "b/a.txt", "c/b.txt"}},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		sorted := newSortedOutput(tt.key)
+		for _, result := range results {
+			sorted.hold(result, false)
+		}
+		if err := sorted.flush(newNDJSONWriter(&out)); err != nil {
+			t.Fatalf("Failed to flush: %v", err)
+		}
+
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			var result models.ProcessResult
+			if err := json.Unmarshal([]byte(line), &result); err != nil {
+				t.Fatalf("Failed to parse result line: %v", err)
+			}
+			got = append(got, result.Path)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("--sort-output %s: expected %v, got %v", tt.key, tt.want, got)
+		}
+	}
+
+	if err := checkSortKey("mtime"); err == nil {
+		t.Error("Expected an unknown sort key to be rejected")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+
+	// Pure Go SQLite driver, registered as "sqlite", so no cgo is needed
+	_ "modernc.org/sqlite"
+)
+
+// sqliteBatchSize is how many rows are inserted per transaction
+const sqliteBatchSize = 500
+
+// sqliteSchema replaces the files table, so each run's database holds that
+// run's results only
+const sqliteSchema = `
+DROP TABLE IF EXISTS files;
+CREATE TABLE files (
+	path        TEXT NOT NULL,
+	type        TEXT,
+	size        INTEGER,
+	lines       INTEGER,
+	words       INTEGER,
+	bytes       INTEGER,
+	hash        TEXT,
+	duration_ms REAL,
+	error       TEXT
+);`
+
+// sqliteInsert adds one result to the files table
+const sqliteInsert = `INSERT INTO files (path, type, size, lines, words, bytes, hash, duration_ms, error)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// sqliteSink writes one row per processed file to a SQLite database for
+// --sqlite, batching rows into transactions
+// A nil *sqliteSink writes nothing
+type sqliteSink struct {
+	mu   sync.Mutex
+	db   *sql.DB
+	tx   *sql.Tx
+	stmt *sql.Stmt
+	rows int
+}
+
+// openSQLiteSink creates or opens the database at path and replaces its
+// files table
+func openSQLiteSink(path string) (*sqliteSink, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create SQLite files table: %w", err)
+	}
+	return &sqliteSink{db: db}, nil
+}
+
+// insert adds result to the current batch, committing it once full
+func (s *sqliteSink) insert(result models.ProcessResult) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx == nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin SQLite transaction: %w", err)
+		}
+		stmt, err := tx.Prepare(sqliteInsert)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to prepare SQLite insert: %w", err)
+		}
+		s.tx, s.stmt = tx, stmt
+	}
+
+	var errText sql.NullString
+	if result.Error != nil {
+		errText = sql.NullString{String: result.Error.Error(), Valid: true}
+	}
+	_, err := s.stmt.Exec(result.Path, result.Type, result.Size, result.Lines, result.Words, result.Bytes,
+		sql.NullString{String: result.Hash, Valid: result.Hash != ""},
+		float64(result.Duration.Microseconds())/1000, errText)
+	if err != nil {
+		return fmt.Errorf("failed to insert %s into SQLite: %w", result.Path, err)
+	}
+
+	if s.rows++; s.rows >= sqliteBatchSize {
+		return s.commit()
+	}
+	return nil
+}
+
+// commit ends the current batch; the caller holds s.mu
+func (s *sqliteSink) commit() error {
+	if s.tx == nil {
+		return nil
+	}
+	s.stmt.Close()
+	err := s.tx.Commit()
+	s.tx, s.stmt, s.rows = nil, nil, 0
+	if err != nil {
+		return fmt.Errorf("failed to commit SQLite rows: %w", err)
+	}
+	return nil
+}
+
+// close commits the last batch and closes the database; later calls do nothing
+func (s *sqliteSink) close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	err := s.commit()
+	if closeErr := s.db.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close SQLite database: %w", closeErr)
+	}
+	s.db = nil
+	return err
+}
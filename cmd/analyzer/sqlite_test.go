@@ -0,0 +1,73 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+func TestSQLiteSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+
+	// More rows than one batch, plus a failed file
+	write := func(n int) {
+		t.Helper()
+		sink, err := openSQLiteSink(path)
+		if err != nil {
+			t.Fatalf("Failed to open SQLite sink: %v", err)
+		}
+		for i := 0; i < n; i++ {
+			result := models.ProcessResult{
+				FileInfo: models.FileInfo{Path: fmt.Sprintf("data/%d.txt", i), Type: "text", Size: int64(i)},
+				Lines:    i, Words: 2 * i, Bytes: i, Duration: 1500 * time.Microsecond,
+			}
+			if i == 0 {
+				result.Error = errors.New("bad file")
+			}
+			if err := sink.insert(result); err != nil {
+				t.Fatalf("Failed to insert row: %v", err)
+			}
+		}
+		if err := sink.close(); err != nil {
+			t.Fatalf("Failed to close SQLite sink: %v", err)
+		}
+		if err := sink.close(); err != nil {
+			t.Errorf("Expected a second close to do nothing, got %v", err)
+		}
+	}
+	write(sqliteBatchSize + 10)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var rows, failed int
+	var maxWords int
+	var duration float64
+	err = db.QueryRow(`SELECT COUNT(*), COUNT(error), MAX(words), MAX(duration_ms) FROM files`).Scan(&rows, &failed, &maxWords, &duration)
+	if err != nil {
+		t.Fatalf("Failed to query files: %v", err)
+	}
+	if rows != sqliteBatchSize+10 || failed != 1 || maxWords != 2*(sqliteBatchSize+9) || duration != 1.5 {
+		t.Errorf("Unexpected rows: %d rows, %d failed, max words %d, duration %v ms", rows, failed, maxWords, duration)
+	}
+
+	// A later run replaces the table
+	write(3)
+	if err := db.QueryRow(`SELECT COUNT(*) FROM files`).Scan(&rows); err != nil || rows != 3 {
+		t.Errorf("Expected 3 rows after the second run, got %d (%v)", rows, err)
+	}
+
+	// A nil sink stores nothing
+	var none *sqliteSink
+	if err := none.insert(models.ProcessResult{}); err != nil || none.close() != nil {
+		t.Error("Expected a nil sink to do nothing")
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// tailInterval is how often watch-file prints the lines added
+var tailInterval time.Duration
+
+// watchFileCmd follows a single growing file
+var watchFileCmd = &cobra.Command{
+	Use:   "watch-file [file]",
+	Short: "Follow a growing file, printing running line and word counts",
+	Long: `Count the lines and words of a file, then follow it like tail -f,
+printing the lines added every interval along with the running totals.
+
+A truncated file, or one replaced by log rotation, is counted again from
+the start. Only complete lines are counted, so a line still being written
+shows up once its newline arrives. Press Ctrl-C to stop.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if tailInterval <= 0 {
+			return fmt.Errorf("invalid --interval %v: must be positive", tailInterval)
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return followFile(ctx, args[0], tailInterval, cmd.OutOrStdout())
+	},
+}
+
+// tailer keeps running counts of a file read from a tracked offset
+// Lines are newlines seen and words are runs of non-whitespace, as wc counts
+type tailer struct {
+	path   string
+	file   *os.File
+	offset int64
+	lines  int
+	words  int
+	inWord bool
+	buf    []byte
+}
+
+// newTailer creates a tailer that opens path on the first poll
+func newTailer(path string) *tailer {
+	return &tailer{path: path, buf: make([]byte, 32*1024)}
+}
+
+// poll counts whatever was appended since the last call and returns the
+// lines added; restarted reports that the file was truncated or replaced,
+// so the counts began again from its start
+// A file that is briefly missing during rotation is not an error
+func (t *tailer) poll() (added int, restarted bool, err error) {
+	info, err := os.Stat(t.path)
+	if errors.Is(err, os.ErrNotExist) && t.file != nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	if t.file != nil {
+		current, err := t.file.Stat()
+		switch {
+		case err != nil || !os.SameFile(current, info):
+			t.file.Close()
+			t.file = nil
+		case info.Size() < t.offset:
+			t.reset()
+			restarted = true
+		}
+	}
+	if t.file == nil {
+		file, err := os.Open(t.path)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to open file: %w", err)
+		}
+		restarted = t.offset > 0 || t.lines > 0 || t.words > 0
+		t.file = file
+		t.reset()
+	}
+
+	if _, err := t.file.Seek(t.offset, io.SeekStart); err != nil {
+		return 0, restarted, fmt.Errorf("failed to seek: %w", err)
+	}
+	before := t.lines
+	for {
+		n, err := t.file.Read(t.buf)
+		t.count(t.buf[:n])
+		t.offset += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return t.lines - before, restarted, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	return t.lines - before, restarted, nil
+}
+
+// count adds the lines and words in p, carrying a word across reads
+func (t *tailer) count(p []byte) {
+	for _, b := range p {
+		switch b {
+		case '\n':
+			t.lines++
+			t.inWord = false
+		case ' ', '\t', '\r', '\v', '\f':
+			t.inWord = false
+		default:
+			if !t.inWord {
+				t.words++
+				t.inWord = true
+			}
+		}
+	}
+}
+
+// reset starts counting again from the start of the file
+func (t *tailer) reset() {
+	t.offset, t.lines, t.words, t.inWord = 0, 0, 0, false
+}
+
+// Close releases the open file
+func (t *tailer) Close() error {
+	if t.file == nil {
+		return nil
+	}
+	return t.file.Close()
+}
+
+// followFile prints the counts of path and then, every interval, the lines
+// added since, until ctx is done
+// Changes are read as fsnotify reports them; the interval also polls, in
+// case an event is missed
+func followFile(ctx context.Context, path string, interval time.Duration, out io.Writer) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch the directory, since rotation replaces the file itself
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+
+	t := newTailer(path)
+	defer t.Close()
+	if _, _, err := t.poll(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s: %d lines, %d words\n", path, t.lines, t.words)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var delta int
+	var restarted bool
+	poll := func() error {
+		added, reset, err := t.poll()
+		if reset {
+			delta, restarted = 0, true
+		}
+		delta += added
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == path {
+				if err := poll(); err != nil {
+					return err
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("watch failed: %w", err)
+		case now := <-ticker.C:
+			if err := poll(); err != nil {
+				return err
+			}
+			note := ""
+			if restarted {
+				note = " (file truncated or replaced, counting from its start)"
+			}
+			fmt.Fprintf(out, "%s +%d lines: %d lines, %d words%s\n",
+				now.Format("15:04:05"), delta, t.lines, t.words, note)
+			delta, restarted = 0, false
+		}
+	}
+}
+
+func init() {
+	watchFileCmd.Flags().DurationVar(&tailInterval, "interval", 5*time.Second, "how often to print the lines added")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTailer(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("one two\nthree"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tail := newTailer(path)
+	defer tail.Close()
+
+	poll := func(wantAdded int, wantRestarted bool, wantLines, wantWords int) {
+		t.Helper()
+		added, restarted, err := tail.poll()
+		if err != nil {
+			t.Fatalf("Failed to poll: %v", err)
+		}
+		if added != wantAdded || restarted != wantRestarted || tail.lines != wantLines || tail.words != wantWords {
+			t.Errorf("Expected +%d (restarted %v) for %d lines and %d words, got +%d (restarted %v) for %d lines and %d words",
+				wantAdded, wantRestarted, wantLines, wantWords, added, restarted, tail.lines, tail.words)
+		}
+	}
+	appendTo := func(content string) {
+		t.Helper()
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("Failed to open test file: %v", err)
+		}
+		defer file.Close()
+		if _, err := file.WriteString(content); err != nil {
+			t.Fatalf("Failed to append: %v", err)
+		}
+	}
+
+	// The unfinished last line counts once its newline arrives, and a word
+	// split across appends counts once
+	poll(1, false, 1, 3)
+	appendTo("-four\nfive\n")
+	poll(2, false, 3, 4)
+	poll(0, false, 3, 4)
+
+	// Truncation starts the counts again
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatalf("Failed to truncate test file: %v", err)
+	}
+	poll(1, true, 1, 1)
+
+	// So does rotation, once the new file appears
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Failed to rotate test file: %v", err)
+	}
+	poll(0, false, 1, 1)
+	if err := os.WriteFile(path, []byte("a b\nc\n"), 0644); err != nil {
+		t.Fatalf("Failed to create rotated file: %v", err)
+	}
+	poll(2, true, 2, 3)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// trendOutput is where the trend report is written, or - for stdout
+var trendOutput string
+
+// trendCmd renders a trend report from JSON reports of earlier runs
+var trendCmd = &cobra.Command{
+	Use:   "trend <report.json> <report.json>...",
+	Short: "Chart total files, bytes and errors across saved JSON reports",
+	Long: `Load JSON reports written by analyze --json-report and render an HTML
+report charting total files, total bytes and error counts across the runs,
+ordered by each report's timestamp, with the change since the previous run.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reports, err := loadJSONReports(args)
+		if err != nil {
+			return err
+		}
+
+		html, err := templates.GenerateTrendHTMLReport(templates.NewTrend("File Analysis Trend", reports))
+		if err != nil {
+			return fmt.Errorf("failed to render trend report: %w", err)
+		}
+
+		if trendOutput == "-" {
+			_, err = fmt.Fprint(cmd.OutOrStdout(), html)
+		} else {
+			err = utils.WriteFileAtomic(trendOutput, []byte(html), 0644)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write trend report: %w", err)
+		}
+		if trendOutput != "-" {
+			logrus.Infof("Trend of %d runs written to %s", len(reports), trendOutput)
+		}
+		return nil
+	},
+}
+
+// loadJSONReports reads the JSON reports at paths
+func loadJSONReports(paths []string) ([]templates.ReportData, error) {
+	reports := make([]templates.ReportData, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report: %w", err)
+		}
+		report, err := templates.DecodeJSONReport(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func init() {
+	trendCmd.Flags().StringVarP(&trendOutput, "out", "o", "trend.html", "write the trend report to this file, or - for stdout")
+}
@@ -13,20 +13,45 @@ import (
 
 	"github.com/RaihanurRahman2022/file-analytics/internal/api"
 	"github.com/RaihanurRahman2022/file-analytics/internal/monitor"
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/sirupsen/logrus"
 )
 
 var (
 	port = flag.Int("port", 8080, "Server port")
+	// maxConcurrent caps files read at once across all requests
+	maxConcurrent = flag.Int("max-concurrent", 4, "Maximum files read concurrently (0 means unlimited)")
+	// analyzeWorkers is the default concurrency of an analyze request
+	analyzeWorkers = flag.Int("analyze-workers", 4, "Files an analyze request processes concurrently unless it asks for a number (max 32)")
+	// Per-request analyze limits bound the work a small request can trigger
+	maxPathDepth    = flag.Int("max-path-depth", 0, "Fail analyze requests listing files more than this many directories below the path (0 means unlimited)")
+	maxRequestFiles = flag.Int("max-request-files", 0, "Fail analyze requests listing more than this many files (0 means unlimited)")
+	maxRequestBytes = flag.Int64("max-request-bytes", 0, "Fail analyze requests whose files total more than this many bytes (0 means unlimited)")
+	// logJSON makes the structured access log one JSON object per line
+	logJSON = flag.Bool("log-json", false, "Write the access log as JSON lines instead of key=value text")
+	// durationSample trades duration metric accuracy for lower overhead
+	durationSample = flag.Int("duration-sample", 1, "Record 1 in N processing durations in the metrics (1 records all)")
 )
 
 func main() {
 	flag.Parse()
+	if *logJSON {
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	}
+	processor.SetMaxConcurrency(*maxConcurrent)
 
 	// Initialize metrics
 	metrics := monitor.NewMetrics()
+	metrics.SetDurationSampling(*durationSample)
 
 	// Create API handlers
 	handlers := api.NewHandlers(metrics)
+	handlers.SetAnalyzeWorkers(*analyzeWorkers)
+	handlers.SetAnalyzeLimits(api.AnalyzeLimits{
+		MaxDepth: *maxPathDepth,
+		MaxFiles: *maxRequestFiles,
+		MaxBytes: *maxRequestBytes,
+	})
 
 	// Create server
 	srv := &http.Server{
@@ -47,13 +72,17 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	// Graceful shutdown
-	log.Println("Shutting down server...")
+	// Graceful shutdown: stop accepting requests, then let running analyses finish
+	log.Printf("Shutting down server, %d analyze requests in flight...", handlers.InFlight())
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	shutdownErr := srv.Shutdown(ctx)
+	if running := handlers.Drain(ctx); running > 0 {
+		log.Printf("Shutdown timed out with %d analyze requests still running", running)
+	}
+	if shutdownErr != nil {
+		log.Fatalf("Server forced to shutdown: %v", shutdownErr)
 	}
 
 	log.Println("Server exited properly")
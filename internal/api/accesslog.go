@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// accessRecord collects what the access log reports about a response
+// Handlers reach it through the request context to add their own fields
+type accessRecord struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+	// filesProcessed is set by the analyze handlers; -1 leaves it out
+	filesProcessed int
+}
+
+// WriteHeader records the status before passing it on
+func (a *accessRecord) WriteHeader(status int) {
+	if a.status == 0 {
+		a.status = status
+	}
+	a.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the bytes written, implying a 200 status as net/http does
+func (a *accessRecord) Write(b []byte) (int, error) {
+	if a.status == 0 {
+		a.status = http.StatusOK
+	}
+	n, err := a.ResponseWriter.Write(b)
+	a.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap gives http.ResponseController the underlying writer
+func (a *accessRecord) Unwrap() http.ResponseWriter {
+	return a.ResponseWriter
+}
+
+// accessRecordKey is the context key under which the access record is stored
+type accessRecordKey struct{}
+
+// accessLog writes one structured access log entry per request once the
+// handler has returned, with the fields request_id, method, path, status,
+// duration_ms, bytes_out and remote_ip, plus files_processed for analyze
+// requests
+// It must run inside requestID so the entry carries the request ID
+func accessLog(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		record := &accessRecord{ResponseWriter: w, filesProcessed: -1}
+		next(record, r.WithContext(context.WithValue(r.Context(), accessRecordKey{}, record)))
+		logAccess(r, record, time.Since(start))
+	}
+}
+
+// logAccess writes the access log entry for a served request
+func logAccess(r *http.Request, record *accessRecord, duration time.Duration) {
+	status := record.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	fields := logrus.Fields{
+		"request_id":  RequestIDFromContext(r.Context()),
+		"method":      r.Method,
+		"path":        r.URL.Path,
+		"status":      status,
+		"duration_ms": float64(duration.Microseconds()) / 1000,
+		"bytes_out":   record.bytes,
+		"remote_ip":   remoteIP,
+	}
+	if record.filesProcessed >= 0 {
+		fields["files_processed"] = record.filesProcessed
+	}
+	logrus.WithFields(fields).Info("access")
+}
+
+// setFilesProcessed records in the access log entry of the request carrying
+// ctx how many files it processed
+func setFilesProcessed(ctx context.Context, n int) {
+	if record, ok := ctx.Value(accessRecordKey{}).(*accessRecord); ok {
+		record.filesProcessed = n
+	}
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+)
+
+const (
+	// maxBatchFiles caps the number of files in a single batch request
+	maxBatchFiles = 100
+
+	// defaultAnalyzeWorkers is how many files a request processes concurrently
+	// unless the server or the request says otherwise
+	defaultAnalyzeWorkers = 4
+
+	// maxAnalyzeWorkers caps the workers a request may ask for
+	maxAnalyzeWorkers = 32
+
+	// maxAnalyzeBody limits the size of an analyze request body
+	maxAnalyzeBody = 1 << 20
+)
+
+// analyzeRequest selects the files to analyze: either an explicit list of
+// files or a directory to walk
+// Workers optionally sets how many files are processed concurrently;
+// TuneWorkers instead measures 1, 2, 4, … workers on the first files and
+// uses the fastest count, up to Workers when given, for the rest
+type analyzeRequest struct {
+	Path        string   `json:"path,omitempty"`
+	Files       []string `json:"files,omitempty"`
+	Workers     int      `json:"workers,omitempty"`
+	TuneWorkers bool     `json:"tune_workers,omitempty"`
+}
+
+// analyzeResponse holds one result per file, in request order
+// Failed files carry their error inline instead of failing the request
+// Total counts every file in the request; Next is the offset of the
+// following page and is omitted on the last page; Workers is the number of
+// workers actually used, after capping or tuning; Tuning lists the
+// throughput measured for each worker count when tuning
+type analyzeResponse struct {
+	Results []models.ProcessResult      `json:"results"`
+	Total   int                         `json:"total"`
+	Next    int                         `json:"next,omitempty"`
+	Workers int                         `json:"workers"`
+	Tuning  []processor.WorkerBenchmark `json:"tuning,omitempty"`
+}
+
+// defaultProcessors returns the processors used by the analyze endpoint
+func defaultProcessors() []processor.Processor {
+	return []processor.Processor{
+		processor.NewTextProcessor(models.DefaultBufferSize),
+		processor.NewJSONProcessor(models.DefaultBufferSize),
+		processor.NewNDJSONProcessor(models.DefaultBufferSize),
+		processor.NewCSVProcessor(models.DefaultBufferSize),
+	}
+}
+
+// handleAnalyze handles file analysis requests
+// The optional limit and offset query parameters select a page of the files;
+// directories are listed sorted by path so pages are stable
+// Requests exceeding the server's AnalyzeLimits fail with a JSON error
+// naming the limit
+func (h *Handlers) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer h.inflight.start()()
+
+	offset, limit, err := parsePage(r)
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req analyzeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAnalyzeBody)).Decode(&req); err != nil {
+		httpError(w, r, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	workers, err := h.analyzeWorkersFor(req.Workers)
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	files := req.Files
+	if req.Path != "" && len(files) == 0 {
+		var err error
+		if files, err = h.collectFiles(req.Path); err != nil {
+			analyzeError(w, r, err, http.StatusBadRequest)
+			return
+		}
+	} else {
+		switch {
+		case len(files) == 0:
+			httpError(w, r, "No files to analyze", http.StatusBadRequest)
+			return
+		case len(files) > maxBatchFiles:
+			httpError(w, r, fmt.Sprintf("Too many files: %d (max %d)", len(files), maxBatchFiles), http.StatusBadRequest)
+			return
+		}
+		if err := h.limits.checkFiles(len(files)); err != nil {
+			analyzeError(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats := templates.NewStatsAccumulator()
+	response := analyzeResponse{Total: len(files), Workers: workers}
+	page := files[min(offset, len(files)):]
+	if limit > 0 && limit < len(page) {
+		page = page[:limit]
+		response.Next = offset + limit
+	}
+	if err := h.limits.checkBytes(page); err != nil {
+		analyzeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if req.TuneWorkers {
+		maxWorkers := maxAnalyzeWorkers
+		if req.Workers > 0 {
+			maxWorkers = workers
+		}
+		var tuned []models.ProcessResult
+		best, rest, benchmarks := processor.TuneWorkers(r.Context(), page, maxWorkers, func(batch []string, n int) {
+			tuned = append(tuned, h.analyzeFiles(r.Context(), batch, n)...)
+		})
+		// Too few files to measure keeps the usual count
+		if best > 0 {
+			workers = best
+		}
+		page, response.Workers, response.Tuning = rest, workers, benchmarks
+		response.Results = append(tuned, h.analyzeFiles(r.Context(), page, workers)...)
+	} else {
+		response.Results = h.analyzeFiles(r.Context(), page, workers)
+	}
+	h.recordReport(stats, response.Results)
+	setFilesProcessed(r.Context(), len(response.Results))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SetAnalyzeWorkers sets how many files an analyze request processes
+// concurrently when it doesn't ask for a number, capped at maxAnalyzeWorkers
+func (h *Handlers) SetAnalyzeWorkers(n int) {
+	h.workers = min(max(n, 1), maxAnalyzeWorkers)
+}
+
+// analyzeWorkersFor returns the workers to use for a request asking for
+// requested, where 0 means the server default
+func (h *Handlers) analyzeWorkersFor(requested int) (int, error) {
+	switch {
+	case requested < 0:
+		return 0, fmt.Errorf("invalid workers: %d", requested)
+	case requested == 0:
+		return h.workers, nil
+	}
+	return min(requested, maxAnalyzeWorkers), nil
+}
+
+// analyzeError writes err as a JSON limit error when it is one, or as a
+// plain error with status otherwise
+func analyzeError(w http.ResponseWriter, r *http.Request, err error, status int) {
+	var limitErr *limitError
+	if errors.As(err, &limitErr) {
+		writeLimitError(w, r, limitErr)
+		return
+	}
+	httpError(w, r, err.Error(), status)
+}
+
+// collectFiles lists the regular files under dir that some processor can
+// handle, leaving out FIFOs, sockets and devices
+// The walk stops at the first file breaking the depth or file count limit
+func (h *Handlers) collectFiles(dir string) ([]string, error) {
+	files := []string{}
+	filter := utils.CombineFilters(
+		utils.CreateRegularFileFilter(),
+		func(path string) bool { return h.processorFor(path) != nil },
+	)
+	_, err := utils.WalkFilesLenient(dir, filter, func(path string) error {
+		if err := h.limits.checkDepth(dir, path); err != nil {
+			return err
+		}
+		files = append(files, path)
+		return h.limits.checkFiles(len(files))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// parsePage reads the limit and offset query parameters
+// A missing limit (0) means every remaining file
+func parsePage(r *http.Request) (offset, limit int, err error) {
+	query := r.URL.Query()
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset: %q", v)
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("invalid limit: %q", v)
+		}
+	}
+	return offset, limit, nil
+}
+
+// analyzeFiles processes files with up to workers goroutines
+// Results are returned in the same order as files
+func (h *Handlers) analyzeFiles(ctx context.Context, files []string, workers int) []models.ProcessResult {
+	results := make([]models.ProcessResult, len(files))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(files); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				results[idx] = h.analyzeFile(ctx, files[idx])
+			}
+		}()
+	}
+
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// analyzeFile processes a single file and records it in the metrics
+// A listed FIFO, socket or device fails without being opened
+func (h *Handlers) analyzeFile(ctx context.Context, path string) models.ProcessResult {
+	proc := h.processorFor(path)
+	if proc == nil {
+		h.metrics.IncrementErrors()
+		return models.ProcessResult{
+			FileInfo: models.FileInfo{Path: path},
+			Error:    apperrors.NewProcessError(apperrors.ErrorTypeValidation, path, "unsupported file type"),
+		}
+	}
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		h.metrics.IncrementErrors()
+		return models.ProcessResult{
+			FileInfo: models.FileInfo{Path: path},
+			Error:    apperrors.NewProcessError(apperrors.ErrorTypeValidation, path, "not a regular file"),
+		}
+	}
+
+	result, err := processor.SafeProcess(ctx, proc, path)
+	if err != nil {
+		result.Path = path
+		result.Error = err
+		h.metrics.IncrementErrors()
+		return result
+	}
+
+	h.metrics.IncrementProcessed()
+	h.metrics.AddDurationFor(path, result.Duration)
+	h.metrics.AddBytes(int64(result.Bytes))
+	return result
+}
+
+// processorFor returns the processor that handles path, or nil if none does
+func (h *Handlers) processorFor(path string) processor.Processor {
+	for _, p := range h.processors {
+		if p.CanHandle(path) {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+)
+
+const (
+	// maxArchiveUpload limits the size of an uploaded archive
+	maxArchiveUpload = 64 << 20
+
+	// maxArchiveDecompressed limits the total size of an uploaded archive's
+	// entries once decompressed
+	maxArchiveDecompressed = 256 << 20
+)
+
+// archiveResponse holds one result per archive entry, in archive order,
+// plus statistics over all of them
+// Failed entries carry their error inline instead of failing the request
+type archiveResponse struct {
+	Results    []models.ProcessResult `json:"results"`
+	Statistics templates.Statistics   `json:"statistics"`
+}
+
+// handleAnalyzeArchive analyzes each file in an uploaded zip or tar archive
+// The archive is the raw request body or, for multipart forms, the "file"
+// field; tar archives may be gzip, bzip2, xz, zstd or snappy compressed
+func (h *Handlers) handleAnalyzeArchive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer h.inflight.start()()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxArchiveUpload)
+	archive, err := receiveArchive(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpError(w, r, fmt.Sprintf("Archive too large (max %d bytes)", maxArchiveUpload), http.StatusRequestEntityTooLarge)
+			return
+		}
+		httpError(w, r, fmt.Sprintf("Invalid upload: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer os.Remove(archive)
+
+	response := archiveResponse{Results: []models.ProcessResult{}}
+	err = processor.ProcessArchive(r.Context(), archive, h.processors, maxArchiveDecompressed, func(result models.ProcessResult) error {
+		if result.Error != nil {
+			h.metrics.IncrementErrors()
+		} else {
+			h.metrics.IncrementProcessed()
+			h.metrics.AddDurationFor(result.Path, result.Duration)
+			h.metrics.AddBytes(int64(result.Bytes))
+		}
+		response.Results = append(response.Results, result)
+		return nil
+	})
+	switch {
+	case errors.Is(err, processor.ErrArchiveTooLarge):
+		httpError(w, r, fmt.Sprintf("Archive decompresses to more than %d bytes", maxArchiveDecompressed), http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		httpError(w, r, fmt.Sprintf("Failed to read archive: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	stats := templates.NewStatsAccumulator()
+	h.recordReport(stats, response.Results)
+	setFilesProcessed(r.Context(), len(response.Results))
+	response.Statistics = stats.Statistics()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// receiveArchive copies the uploaded archive to a temporary file, since zip
+// archives can't be read as a stream, and returns its path
+// The archive is the "file" field of a multipart form, or else the body
+func receiveArchive(r *http.Request) (string, error) {
+	upload := r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		upload = file
+	}
+
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, upload); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return tmp.Name(), nil
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// inflight tracks the analyze operations still running, so shutdown can
+// wait for them
+type inflight struct {
+	wg      sync.WaitGroup
+	running atomic.Int64
+}
+
+// start records a new operation and returns the function that ends it
+func (f *inflight) start() func() {
+	f.wg.Add(1)
+	f.running.Add(1)
+	return func() {
+		f.running.Add(-1)
+		f.wg.Done()
+	}
+}
+
+// InFlight returns the number of analyze requests currently running
+func (h *Handlers) InFlight() int {
+	return int(h.inflight.running.Load())
+}
+
+// Drain waits until every in-flight analyze request has finished or ctx is
+// done, and returns how many were still running, 0 when all finished
+// Call it after the HTTP server has stopped accepting requests
+func (h *Handlers) Drain(ctx context.Context) int {
+	done := make(chan struct{})
+	go func() {
+		h.inflight.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return 0
+	case <-ctx.Done():
+		return h.InFlight()
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AnalyzeLimits bounds the work a single analyze request can trigger, on top
+// of the request body size limit; zero fields are unlimited
+type AnalyzeLimits struct {
+	// MaxDepth caps how many directories below the requested path a file
+	// may be; files directly in the path are at depth 0
+	MaxDepth int
+	// MaxFiles caps the files a request lists, whether walked or given
+	MaxFiles int
+	// MaxBytes caps the total size of the files a request processes, so
+	// of the requested page when paging
+	MaxBytes int64
+}
+
+// Names of the analyze limits, as reported in limit errors
+const (
+	limitPathDepth = "max_path_depth"
+	limitFiles     = "max_files"
+	limitBytes     = "max_bytes"
+)
+
+// limitError reports the analyze limit a request exceeded
+type limitError struct {
+	limit   string
+	max     int64
+	status  int
+	message string
+}
+
+// Error implements the error interface
+func (e *limitError) Error() string {
+	return e.message
+}
+
+// limitErrorResponse is the JSON body of a limit error
+type limitErrorResponse struct {
+	Error     string `json:"error"`
+	Limit     string `json:"limit"`
+	Max       int64  `json:"max"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// SetAnalyzeLimits sets the per-request analyze limits
+func (h *Handlers) SetAnalyzeLimits(limits AnalyzeLimits) {
+	h.limits = limits
+}
+
+// checkDepth fails when path lies deeper below root than MaxDepth allows
+func (l AnalyzeLimits) checkDepth(root, path string) error {
+	if l.MaxDepth <= 0 {
+		return nil
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil
+	}
+	if depth := strings.Count(filepath.ToSlash(rel), "/"); depth > l.MaxDepth {
+		return &limitError{
+			limit:   limitPathDepth,
+			max:     int64(l.MaxDepth),
+			status:  http.StatusBadRequest,
+			message: fmt.Sprintf("Path too deep: %s is %d directories below %s (max %d)", path, depth, root, l.MaxDepth),
+		}
+	}
+	return nil
+}
+
+// checkFiles fails when a request lists more than MaxFiles files
+func (l AnalyzeLimits) checkFiles(n int) error {
+	if l.MaxFiles <= 0 || n <= l.MaxFiles {
+		return nil
+	}
+	return &limitError{
+		limit:   limitFiles,
+		max:     int64(l.MaxFiles),
+		status:  http.StatusRequestEntityTooLarge,
+		message: fmt.Sprintf("Too many files: more than %d", l.MaxFiles),
+	}
+}
+
+// checkBytes fails when files total more than MaxBytes
+// Files that can't be stat'ed are left to fail when processed
+func (l AnalyzeLimits) checkBytes(files []string) error {
+	if l.MaxBytes <= 0 {
+		return nil
+	}
+	var total int64
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if total += info.Size(); total > l.MaxBytes {
+			return &limitError{
+				limit:   limitBytes,
+				max:     l.MaxBytes,
+				status:  http.StatusRequestEntityTooLarge,
+				message: fmt.Sprintf("Too many bytes: files total more than %d bytes", l.MaxBytes),
+			}
+		}
+	}
+	return nil
+}
+
+// writeLimitError writes err as a JSON error naming the limit that was hit
+func writeLimitError(w http.ResponseWriter, r *http.Request, err *limitError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(err.status)
+	json.NewEncoder(w).Encode(limitErrorResponse{
+		Error:     err.message,
+		Limit:     err.limit,
+		Max:       err.max,
+		RequestID: RequestIDFromContext(r.Context()),
+	})
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/templates"
+)
+
+const (
+	// reportTitle is the title of reports built from analyze requests
+	reportTitle = "File Analysis Report"
+
+	// maxReportEntries caps the files and errors kept for the last report;
+	// statistics still cover every file
+	maxReportEntries = 1000
+)
+
+// reportFormats maps the format query parameter to its generator, content
+// type and download file name
+var reportFormats = map[string]struct {
+	generate    func(templates.ReportData) (string, error)
+	contentType string
+	fileName    string
+}{
+	"html": {templates.GenerateHTMLReport, "text/html; charset=utf-8", "report.html"},
+	"md":   {templates.GenerateMarkdownReport, "text/markdown; charset=utf-8", "report.md"},
+	"json": {templates.GenerateJSONReport, "application/json", "report.json"},
+}
+
+// lastReport retains the report of the most recent analyze request
+type lastReport struct {
+	mu   sync.RWMutex
+	data *templates.ReportData
+}
+
+// set replaces the retained report, trimming its file and error lists
+// The kept entries are copied, so the full lists can be garbage collected
+func (l *lastReport) set(data templates.ReportData) {
+	if len(data.Files) > maxReportEntries {
+		data.Files = slices.Clone(data.Files[:maxReportEntries])
+	}
+	if len(data.Errors) > maxReportEntries {
+		data.Errors = slices.Clone(data.Errors[:maxReportEntries])
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.data = &data
+}
+
+// get returns the retained report, or false if no analysis has run yet
+func (l *lastReport) get() (templates.ReportData, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.data == nil {
+		return templates.ReportData{}, false
+	}
+	return *l.data, true
+}
+
+// recordReport builds report data from an analyze request's results and
+// keeps it for the report endpoint
+func (h *Handlers) recordReport(stats *templates.StatsAccumulator, results []models.ProcessResult) {
+	for _, result := range results {
+		stats.Add(result)
+	}
+	stats.Finish()
+	h.report.set(stats.Report(reportTitle))
+}
+
+// handleReport renders the most recent analysis as a downloadable report
+// The format query parameter selects html (the default), md or json;
+// summary=true leaves out the per-file table
+func (h *Handlers) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "html"
+	}
+	format, ok := reportFormats[name]
+	if !ok {
+		httpError(w, r, fmt.Sprintf("Unsupported format: %s (use html, md or json)", name), http.StatusBadRequest)
+		return
+	}
+
+	var summaryOnly bool
+	if v := r.URL.Query().Get("summary"); v != "" {
+		var err error
+		if summaryOnly, err = strconv.ParseBool(v); err != nil {
+			httpError(w, r, fmt.Sprintf("Invalid summary: %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, ok := h.report.get()
+	if !ok {
+		httpError(w, r, "No analysis has been run yet", http.StatusNotFound)
+		return
+	}
+	data.SummaryOnly = summaryOnly
+
+	report, err := format.generate(data)
+	if err != nil {
+		httpError(w, r, fmt.Sprintf("Failed to generate report: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", format.fileName))
+	fmt.Fprint(w, report)
+}
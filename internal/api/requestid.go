@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds client-supplied IDs so they can't flood the logs
+const maxRequestIDLen = 128
+
+// requestIDKey is the context key under which the request ID is stored
+type requestIDKey struct{}
+
+// requestID tags each request with an ID, taken from the X-Request-ID header
+// or generated when absent, and echoes it back in the response
+// Processors serving the request log with the ID as a request_id field
+func requestID(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = processor.WithLogger(ctx, logrus.WithField("request_id", id))
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// RequestIDFromContext returns the request ID stored by the middleware,
+// or an empty string if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// httpError writes an error response that includes the request ID
+func httpError(w http.ResponseWriter, r *http.Request, message string, code int) {
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		message = fmt.Sprintf("%s (request_id=%s)", message, id)
+	}
+	http.Error(w, message, code)
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random (version 4) UUID
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand doesn't fail on supported platforms
+		panic(fmt.Sprintf("failed to generate request ID: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
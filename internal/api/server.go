@@ -3,11 +3,16 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/RaihanurRahman2022/file-analytics/internal/monitor"
+	"github.com/RaihanurRahman2022/file-analytics/internal/processor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
 )
 
 // Server represents the HTTP API server
@@ -57,19 +62,18 @@ type Middleware func(http.HandlerFunc) http.HandlerFunc
 // withMiddleware applies common middleware to handlers
 func (s *Server) withMiddleware(handler http.HandlerFunc) http.HandlerFunc {
 	// Apply middleware in order
-	return s.logRequest(
-		s.timeRequest(
-			s.recoverPanic(handler),
+	return requestID(
+		s.logRequest(
+			s.timeRequest(
+				s.recoverPanic(handler),
+			),
 		),
 	)
 }
 
-// logRequest logs incoming HTTP requests
+// logRequest writes a structured access log entry for each HTTP request
 func (s *Server) logRequest(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s %s %s", r.RemoteAddr, r.Method, r.URL)
-		next(w, r)
-	}
+	return accessLog(next)
 }
 
 // timeRequest measures request duration
@@ -88,7 +92,7 @@ func (s *Server) recoverPanic(next http.HandlerFunc) http.HandlerFunc {
 		defer func() {
 			if err := recover(); err != nil {
 				log.Printf("Panic recovered: %v", err)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				httpError(w, r, "Internal Server Error", http.StatusInternalServerError)
 			}
 		}()
 		next(w, r)
@@ -123,58 +127,113 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 
 // Handlers represents the API handlers
 type Handlers struct {
-	metrics *monitor.MetricsCollector
-	mux     *http.ServeMux
+	metrics    *monitor.MetricsCollector
+	processors []processor.Processor
+	mux        *http.ServeMux
+	report     lastReport
+	workers    int
+	limits     AnalyzeLimits
+	inflight   inflight
 }
 
 // NewHandlers creates new API handlers
 func NewHandlers(metrics *monitor.MetricsCollector) *Handlers {
 	h := &Handlers{
-		metrics: metrics,
-		mux:     http.NewServeMux(),
+		metrics:    metrics,
+		processors: defaultProcessors(),
+		mux:        http.NewServeMux(),
+		workers:    defaultAnalyzeWorkers,
 	}
 	h.setupRoutes()
 	return h
 }
 
 // Router returns the HTTP router
+// Every request is tagged with a request ID and access logged
 func (h *Handlers) Router() http.Handler {
-	return h.mux
+	return requestID(accessLog(h.mux.ServeHTTP))
 }
 
 // setupRoutes configures API routes
 func (h *Handlers) setupRoutes() {
 	h.mux.HandleFunc("/api/v1/analyze", h.handleAnalyze)
+	h.mux.HandleFunc("/api/v1/analyze/archive", h.handleAnalyzeArchive)
 	h.mux.HandleFunc("/api/v1/hash", h.handleHash)
+	h.mux.HandleFunc("/api/v1/report", h.handleReport)
 	h.mux.HandleFunc("/api/v1/metrics", h.handleMetrics)
+	h.mux.HandleFunc("/metrics", h.handleOpenMetrics)
 }
 
-// handleAnalyze handles file analysis requests
-func (h *Handlers) handleAnalyze(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-	w.WriteHeader(http.StatusOK)
+// hashRequest names the file to hash and the algorithm to use
+type hashRequest struct {
+	File      string `json:"file"`
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// hashResponse carries the hex-encoded digest of a file
+type hashResponse struct {
+	File      string `json:"file"`
+	Algorithm string `json:"algorithm"`
+	Hash      string `json:"hash"`
 }
 
 // handleHash handles file hash requests
+// SHA-256 is the only supported algorithm and the default
 func (h *Handlers) handleHash(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		httpError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req hashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.File == "" {
+		httpError(w, r, "Invalid request body: a file is required", http.StatusBadRequest)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	if req.Algorithm == "" {
+		req.Algorithm = "sha256"
+	}
+	if req.Algorithm != "sha256" {
+		httpError(w, r, fmt.Sprintf("Unsupported algorithm: %s", req.Algorithm), http.StatusBadRequest)
+		return
+	}
+
+	hash, err := utils.HashFile(req.File)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, os.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+		httpError(w, r, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hashResponse{File: req.File, Algorithm: req.Algorithm, Hash: hash})
 }
 
 // handleMetrics handles metrics requests
 func (h *Handlers) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	processed, errors, avgDuration := h.metrics.GetMetrics()
+	retries, permanentErrors := h.metrics.GetRetryMetrics()
+	bytes, bytesPerSecond := h.metrics.GetThroughput()
 	metrics := map[string]interface{}{
-		"processed": processed,
-		"errors":    errors,
-		"duration":  avgDuration.String(),
+		"processed":             processed,
+		"errors":                errors,
+		"retries":               retries,
+		"permanent_errors":      permanentErrors,
+		"duration":              avgDuration.String(),
+		"bytes_processed_total": bytes,
+		"bytes_per_second":      bytesPerSecond,
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(metrics)
 }
+
+// handleOpenMetrics exposes metrics in OpenMetrics text format for scraping
+func (h *Handlers) handleOpenMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", monitor.OpenMetricsContentType)
+	if err := h.metrics.WriteOpenMetrics(w); err != nil {
+		log.Printf("Failed to write metrics: %v", err)
+	}
+}
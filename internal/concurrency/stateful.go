@@ -2,10 +2,21 @@ package concurrency
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrPoolStopped is returned when submitting to a pool that has been stopped
+var ErrPoolStopped = errors.New("stateful pool is stopped")
+
+// ErrStopTimeout is returned by StopTimeout when workers are still running
+// once the timeout has passed
+var ErrStopTimeout = errors.New("stateful pool stop timed out")
+
 // StatefulWorker represents a worker that maintains state
 type StatefulWorker struct {
 	ID        int
@@ -13,18 +24,37 @@ type StatefulWorker struct {
 	LastWork  time.Time
 	WorkCount int64
 	mu        sync.RWMutex
+	// live is false while the worker's goroutine has exited for idleness;
+	// it is guarded by the pool's liveMu
+	live bool
 }
 
 // StatefulPool manages a pool of stateful workers
 type StatefulPool struct {
-	workers     []*StatefulWorker
-	tasks       chan interface{}
-	results     chan interface{}
-	done        chan struct{}
-	wg          sync.WaitGroup
+	workers []*StatefulWorker
+	tasks   chan interface{}
+	results chan interface{}
+	done    chan struct{}
+	// stopped is closed once every worker has exited and results is closed
+	stopped chan struct{}
+	wg      sync.WaitGroup
+	// mu guards the task channel against being closed during a Submit
+	mu          sync.RWMutex
+	stopOnce    sync.Once
 	ctx         context.Context
 	cancel      context.CancelFunc
 	rateLimiter chan struct{}
+	rateLimit   time.Duration
+	// idleTimeout stops workers that wait this long for a task (0 keeps them)
+	idleTimeout time.Duration
+	// liveMu serializes idle exits against respawns in Submit
+	liveMu sync.Mutex
+
+	// Pool-level counters, updated atomically by workers and Submit
+	processed   atomic.Int64
+	errored     atomic.Int64
+	dropped     atomic.Int64
+	rateLimitNs atomic.Int64
 }
 
 // NewStatefulPool creates a new pool of stateful workers
@@ -36,17 +66,19 @@ func NewStatefulPool(workers int, queueSize int, rateLimit time.Duration) *State
 		tasks:       make(chan interface{}, queueSize),
 		results:     make(chan interface{}, queueSize),
 		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
 		ctx:         ctx,
 		cancel:      cancel,
 		rateLimiter: make(chan struct{}, workers),
+		rateLimit:   rateLimit,
 	}
 
 	// Initialize workers
 	for i := 0; i < workers; i++ {
 		pool.workers[i] = &StatefulWorker{
 			ID:        i,
-			LastWork:  time.Now(),
 			WorkCount: 0,
+			live:      true,
 		}
 		pool.rateLimiter <- struct{}{}
 	}
@@ -54,6 +86,13 @@ func NewStatefulPool(workers int, queueSize int, rateLimit time.Duration) *State
 	return pool
 }
 
+// SetIdleTimeout makes a worker exit after waiting this long without a task;
+// it is started again when a task is submitted. Zero keeps workers running
+// Call it before Start
+func (p *StatefulPool) SetIdleTimeout(timeout time.Duration) {
+	p.idleTimeout = timeout
+}
+
 // Start launches the worker pool
 func (p *StatefulPool) Start() {
 	for i, worker := range p.workers {
@@ -64,20 +103,73 @@ func (p *StatefulPool) Start() {
 
 // Submit adds a task to the pool
 func (p *StatefulPool) Submit(task interface{}) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// Reject once stopping has begun; the task channel may already be closed
+	select {
+	case <-p.done:
+		p.dropped.Add(1)
+		return ErrPoolStopped
+	default:
+	}
+
 	select {
 	case p.tasks <- task:
+		p.respawnWorker()
 		return nil
+	case <-p.done:
+		p.dropped.Add(1)
+		return ErrPoolStopped
 	case <-p.ctx.Done():
+		p.dropped.Add(1)
 		return p.ctx.Err()
 	}
 }
 
 // Stop gracefully shuts down the pool
+// Queued tasks are abandoned; tasks already running finish, and their results
+// are delivered if Results has room and dropped otherwise, so Stop never
+// waits on a caller that isn't reading Results
 func (p *StatefulPool) Stop() {
-	p.cancel()
-	close(p.tasks)
-	p.wg.Wait()
-	close(p.results)
+	<-p.shutdown()
+}
+
+// StopTimeout cancels the pool's context, abandoning queued tasks, and waits
+// at most d for running tasks to finish
+// On timeout it returns an error wrapping ErrStopTimeout; the remaining
+// workers exit in the background and Results is closed once they have
+func (p *StatefulPool) StopTimeout(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-p.shutdown():
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w after %v", ErrStopTimeout, d)
+	}
+}
+
+// shutdown starts stopping the pool the first time it is called, cancelling
+// the workers
+// The returned channel is closed once the pool has stopped
+func (p *StatefulPool) shutdown() <-chan struct{} {
+	p.stopOnce.Do(func() {
+		// Wake blocked submitters, then close the queue once none are sending
+		close(p.done)
+		p.mu.Lock()
+		close(p.tasks)
+		p.mu.Unlock()
+		p.cancel()
+
+		go func() {
+			p.wg.Wait()
+			close(p.results)
+			close(p.stopped)
+		}()
+	})
+	return p.stopped
 }
 
 // Results returns the channel for receiving task results
@@ -85,37 +177,136 @@ func (p *StatefulPool) Results() <-chan interface{} {
 	return p.results
 }
 
+// respawnWorker restarts one worker that exited for idleness, if any, so a
+// newly queued task is picked up
+// Submit calls it while holding p.mu, so Stop can't be waiting on p.wg yet
+func (p *StatefulPool) respawnWorker() {
+	if p.idleTimeout <= 0 {
+		return
+	}
+
+	p.liveMu.Lock()
+	defer p.liveMu.Unlock()
+	for i, worker := range p.workers {
+		if !worker.live {
+			worker.live = true
+			p.wg.Add(1)
+			go p.runWorker(i, worker)
+			return
+		}
+	}
+}
+
+// idleExit marks worker as stopped for idleness unless a task is waiting
+// A task queued before this check is seen here; one queued after it makes
+// Submit respawn the worker
+func (p *StatefulPool) idleExit(worker *StatefulWorker) bool {
+	p.liveMu.Lock()
+	defer p.liveMu.Unlock()
+	if len(p.tasks) > 0 {
+		return false
+	}
+	worker.live = false
+	return true
+}
+
 // runWorker runs a single stateful worker
 func (p *StatefulPool) runWorker(id int, worker *StatefulWorker) {
 	defer p.wg.Done()
 
+	// A nil channel never fires, so without a timeout workers never idle out
+	var idle <-chan time.Time
+	var idleTimer *time.Timer
+	if p.idleTimeout > 0 {
+		idleTimer = time.NewTimer(p.idleTimeout)
+		defer idleTimer.Stop()
+	}
+
 	for {
+		waitStart := time.Now()
 		select {
 		case <-p.ctx.Done():
 			return
 		case <-p.rateLimiter:
-			// Process task with rate limiting
-			select {
-			case task := <-p.tasks:
-				// Update worker state
-				worker.mu.Lock()
-				worker.LastWork = time.Now()
-				worker.WorkCount++
-				worker.mu.Unlock()
-
-				// Process task
-				result := p.processTask(worker, task)
-				p.results <- result
-
-				// Return token to rate limiter
+			// Record how long this worker waited for a rate limiter token
+			p.rateLimitNs.Add(int64(time.Since(waitStart)))
+		}
+
+		if idleTimer != nil {
+			// Drop a tick that fired while the last task was running
+			if !idleTimer.Stop() {
+				select {
+				case <-idleTimer.C:
+				default:
+				}
+			}
+			idleTimer.Reset(p.idleTimeout)
+			idle = idleTimer.C
+		}
+
+		// Process task with rate limiting
+		select {
+		case <-idle:
+			if p.idleExit(worker) {
+				// Hand back the token this worker was holding
 				p.rateLimiter <- struct{}{}
+				return
+			}
+			p.rateLimiter <- struct{}{}
+		case task, ok := <-p.tasks:
+			if !ok {
+				// Queue closed
+				return
+			}
+			// A stop may race the receive; drop the task
+			if p.ctx.Err() != nil {
+				return
+			}
+
+			// Process task
+			result := p.processTask(worker, task)
+
+			// Update worker state once the task has completed
+			worker.mu.Lock()
+			worker.LastWork = time.Now()
+			worker.WorkCount++
+			worker.mu.Unlock()
+			p.processed.Add(1)
+			if _, isErr := result.(error); isErr {
+				p.errored.Add(1)
+			}
+
+			select {
+			case p.results <- result:
 			case <-p.ctx.Done():
+				// Stopping: keep the result if there is room, drop it otherwise
+				select {
+				case p.results <- result:
+				default:
+				}
 				return
 			}
+
+			// Return token to rate limiter once the rate limit interval has passed
+			p.releaseToken()
+		case <-p.ctx.Done():
+			return
 		}
 	}
 }
 
+// releaseToken hands a rate limiter token back after the configured interval
+// The channel has one slot per worker, so the send never blocks
+func (p *StatefulPool) releaseToken() {
+	if p.rateLimit <= 0 {
+		p.rateLimiter <- struct{}{}
+		return
+	}
+	time.AfterFunc(p.rateLimit, func() {
+		p.rateLimiter <- struct{}{}
+	})
+}
+
 // processTask processes a single task and updates worker state
 func (p *StatefulPool) processTask(worker *StatefulWorker, task interface{}) interface{} {
 	// Example task processing
@@ -124,24 +315,97 @@ func (p *StatefulPool) processTask(worker *StatefulWorker, task interface{}) int
 	return task
 }
 
-// GetWorkerStats returns statistics for all workers
+// GetWorkerStats returns statistics for the live workers, leaving out
+// those that have exited for idleness
 func (p *StatefulPool) GetWorkerStats() []WorkerStats {
-	stats := make([]WorkerStats, len(p.workers))
-	for i, worker := range p.workers {
+	p.liveMu.Lock()
+	defer p.liveMu.Unlock()
+
+	stats := make([]WorkerStats, 0, len(p.workers))
+	for _, worker := range p.workers {
+		if !worker.live {
+			continue
+		}
 		worker.mu.RLock()
-		stats[i] = WorkerStats{
+		stats = append(stats, WorkerStats{
 			ID:        worker.ID,
 			LastWork:  worker.LastWork,
 			WorkCount: worker.WorkCount,
-		}
+		})
 		worker.mu.RUnlock()
 	}
 	return stats
 }
 
+// liveWorkers counts the workers that haven't exited for idleness
+func (p *StatefulPool) liveWorkers() int {
+	p.liveMu.Lock()
+	defer p.liveMu.Unlock()
+
+	live := 0
+	for _, worker := range p.workers {
+		if worker.live {
+			live++
+		}
+	}
+	return live
+}
+
+// GetPoolStats returns pool-wide counters for tuning worker count and rate limit
+func (p *StatefulPool) GetPoolStats() PoolStats {
+	return PoolStats{
+		Workers:       len(p.workers),
+		LiveWorkers:   p.liveWorkers(),
+		Processed:     p.processed.Load(),
+		Errored:       p.errored.Load(),
+		Dropped:       p.dropped.Load(),
+		RateLimitWait: time.Duration(p.rateLimitNs.Load()),
+		QueuedTasks:   len(p.tasks),
+	}
+}
+
+// PoolStats represents pool-wide statistics
+type PoolStats struct {
+	Workers int
+	// LiveWorkers excludes workers stopped by the idle timeout
+	LiveWorkers int
+	Processed   int64
+	// Errored counts tasks whose result was an error
+	Errored int64
+	// Dropped counts tasks rejected because the pool was stopped
+	Dropped int64
+	// RateLimitWait is the total time workers spent waiting for a token
+	RateLimitWait time.Duration
+	QueuedTasks   int
+}
+
 // WorkerStats represents statistics for a single worker
+// LastWork is zero until the worker has completed a task
 type WorkerStats struct {
-	ID        int
-	LastWork  time.Time
-	WorkCount int64
-} 
\ No newline at end of file
+	ID        int       `json:"id"`
+	LastWork  time.Time `json:"last_work"`
+	WorkCount int64     `json:"work_count"`
+}
+
+// idleDescription describes how long the worker has been idle as of now
+func (s WorkerStats) idleDescription(now time.Time) string {
+	if s.LastWork.IsZero() {
+		return "never worked"
+	}
+	return "idle for " + now.Sub(s.LastWork).Round(time.Millisecond).String()
+}
+
+// String formats the statistics for logging
+func (s WorkerStats) String() string {
+	return fmt.Sprintf("worker %d: %d tasks, %s", s.ID, s.WorkCount, s.idleDescription(time.Now()))
+}
+
+// MarshalJSON adds the human-readable idle time to the exported fields
+func (s WorkerStats) MarshalJSON() ([]byte, error) {
+	// The alias drops the methods so json.Marshal doesn't recurse
+	type workerStats WorkerStats
+	return json.Marshal(struct {
+		workerStats
+		Idle string `json:"idle"`
+	}{workerStats(s), s.idleDescription(time.Now())})
+}
@@ -1,6 +1,9 @@
 package concurrency
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -55,10 +58,11 @@ func TestStatefulPoolGracefulShutdown(t *testing.T) {
 	pool := NewStatefulPool(2, 10, 100*time.Millisecond)
 	pool.Start()
 
-	// Submit some tasks
+	// Submit some tasks and let the workers pick the first ones up
 	for i := 0; i < 5; i++ {
 		pool.Submit(i)
 	}
+	time.Sleep(20 * time.Millisecond)
 
 	// Stop the pool
 	pool.Stop()
@@ -73,7 +77,10 @@ func TestStatefulPoolGracefulShutdown(t *testing.T) {
 	results := make([]interface{}, 0)
 	for {
 		select {
-		case result := <-pool.Results():
+		case result, ok := <-pool.Results():
+			if !ok {
+				goto done
+			}
 			results = append(results, result)
 		case <-time.After(100 * time.Millisecond):
 			goto done
@@ -85,6 +92,68 @@ done:
 	}
 }
 
+func TestStatefulPoolStopUnreadResults(t *testing.T) {
+	// Results is never read and has room for one of the two running tasks
+	pool := NewStatefulPool(2, 1, 0)
+	pool.Start()
+	for i := 0; i < 3; i++ {
+		if err := pool.Submit(i); err != nil {
+			t.Fatalf("Failed to submit: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		pool.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked on undelivered results")
+	}
+}
+
+func TestStatefulPoolStopTimeout(t *testing.T) {
+	pool := NewStatefulPool(1, 10, 0)
+	pool.Start()
+
+	// Each task takes 100ms, so a 10ms timeout catches the first one running
+	for i := 0; i < 3; i++ {
+		if err := pool.Submit(i); err != nil {
+			t.Fatalf("Failed to submit: %v", err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	err := pool.StopTimeout(10 * time.Millisecond)
+	if !errors.Is(err, ErrStopTimeout) {
+		t.Fatalf("Expected ErrStopTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("StopTimeout took %v", elapsed)
+	}
+
+	// The worker exits in the background without taking the queued tasks
+	select {
+	case <-pool.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Worker didn't exit after its task finished")
+	}
+	if processed := pool.GetPoolStats().Processed; processed != 1 {
+		t.Errorf("Expected only the running task to finish, got %d", processed)
+	}
+
+	// An idle pool stops well within the timeout
+	idle := NewStatefulPool(2, 10, 0)
+	idle.Start()
+	if err := idle.StopTimeout(time.Second); err != nil {
+		t.Errorf("Expected an idle pool to stop, got %v", err)
+	}
+}
+
 func TestStatefulPoolRateLimiting(t *testing.T) {
 	pool := NewStatefulPool(2, 10, 200*time.Millisecond)
 	pool.Start()
@@ -107,3 +176,105 @@ func TestStatefulPoolRateLimiting(t *testing.T) {
 		t.Errorf("Expected duration >= 400ms, got %v", duration)
 	}
 }
+
+func TestStatefulPoolStats(t *testing.T) {
+	pool := NewStatefulPool(2, 10, 100*time.Millisecond)
+	pool.Start()
+	for _, worker := range pool.GetWorkerStats() {
+		if !worker.LastWork.IsZero() || !strings.HasSuffix(worker.String(), "never worked") {
+			t.Errorf("Expected a fresh worker to have never worked, got %s", worker)
+		}
+	}
+
+	tasks := []interface{}{1, errors.New("task failed"), 3}
+	for _, task := range tasks {
+		if err := pool.Submit(task); err != nil {
+			t.Fatalf("Failed to submit task: %v", err)
+		}
+	}
+	for range tasks {
+		<-pool.Results()
+	}
+
+	pool.Stop()
+	if err := pool.Submit(4); err == nil {
+		t.Error("Expected error when submitting to stopped pool")
+	}
+	for _, worker := range pool.GetWorkerStats() {
+		if (worker.WorkCount > 0) == worker.LastWork.IsZero() {
+			t.Errorf("Expected LastWork set exactly when tasks completed, got %s", worker)
+		}
+	}
+
+	stats := pool.GetPoolStats()
+	if stats.Processed != int64(len(tasks)) {
+		t.Errorf("Expected %d processed tasks, got %d", len(tasks), stats.Processed)
+	}
+	if stats.Errored != 1 {
+		t.Errorf("Expected 1 errored task, got %d", stats.Errored)
+	}
+	if stats.Dropped != 1 {
+		t.Errorf("Expected 1 dropped task, got %d", stats.Dropped)
+	}
+	if stats.Workers != 2 {
+		t.Errorf("Expected 2 workers, got %d", stats.Workers)
+	}
+}
+
+func TestWorkerStatsFormatting(t *testing.T) {
+	idle := WorkerStats{ID: 1, WorkCount: 3, LastWork: time.Now().Add(-time.Minute)}
+	if s := idle.String(); !strings.HasPrefix(s, "worker 1: 3 tasks, idle for 1m0") {
+		t.Errorf("Unexpected string: %q", s)
+	}
+
+	data, err := json.Marshal(WorkerStats{ID: 2})
+	if err != nil {
+		t.Fatalf("Failed to marshal worker stats: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal worker stats: %v", err)
+	}
+	if decoded["id"] != float64(2) || decoded["work_count"] != float64(0) || decoded["idle"] != "never worked" {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
+
+func TestStatefulPoolIdleTimeout(t *testing.T) {
+	pool := NewStatefulPool(3, 10, 0)
+	pool.SetIdleTimeout(50 * time.Millisecond)
+	pool.Start()
+	defer pool.Stop()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timeout waiting for %s", what)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Idle workers exit
+	waitFor("workers to idle out", func() bool { return pool.GetPoolStats().LiveWorkers == 0 })
+	if stats := pool.GetWorkerStats(); len(stats) != 0 {
+		t.Errorf("Expected no live worker stats, got %d", len(stats))
+	}
+
+	// A new task brings a worker back and is processed
+	if err := pool.Submit(1); err != nil {
+		t.Fatalf("Failed to submit task: %v", err)
+	}
+	select {
+	case <-pool.Results():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for result after respawn")
+	}
+	if live := pool.GetPoolStats().LiveWorkers; live < 1 {
+		t.Errorf("Expected a respawned worker, got %d live", live)
+	}
+
+	waitFor("respawned worker to idle out", func() bool { return pool.GetPoolStats().LiveWorkers == 0 })
+}
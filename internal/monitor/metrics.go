@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -12,10 +13,52 @@ type MetricsCollector struct {
 	processed atomic.Uint64
 	errors    atomic.Uint64
 	duration  atomic.Int64
+	bytes     atomic.Int64
+
+	// created is when the collector was made; throughput is averaged since
+	created time.Time
+
+	// Retry attempts made after transient failures, and failures that
+	// remained once retries ran out or weren't allowed
+	retries         atomic.Uint64
+	permanentErrors atomic.Uint64
+
+	// Duration histogram buckets, one counter per entry in durationBuckets
+	// plus a final +Inf bucket
+	buckets [len(durationBuckets) + 1]atomic.Uint64
+
+	// Only 1 in sampleEvery durations is recorded, weighted by sampleEvery;
+	// 0 or 1 records every duration. sampleSeq counts the calls
+	sampleEvery uint64
+	sampleSeq   atomic.Uint64
+
+	// Slowest file seen in the current exemplar window
+	slowMu     sync.Mutex
+	slowest    exemplar
+	slowWindow time.Duration
+
+	// reporter receives each periodic report (nil discards them)
+	reporter func(Report)
 
 	// Channels for control
 	stopChan chan struct{}
 	ticker   *time.Ticker
+	// loopDone is closed when the reporting goroutine exits
+	loopDone chan struct{}
+	started  atomic.Bool
+	stopOnce sync.Once
+}
+
+// Report is a point-in-time summary of the collected metrics
+type Report struct {
+	Timestamp       string
+	Processed       uint64
+	Errors          uint64
+	Retries         uint64
+	PermanentErrors uint64
+	AvgDuration     string
+	BytesProcessed  int64
+	BytesPerSecond  float64
 }
 
 // NewMetrics is an alias for NewMetricsCollector for backward compatibility
@@ -27,15 +70,29 @@ func NewMetrics() *MetricsCollector {
 // Demonstrates constructor pattern and ticker setup
 func NewMetricsCollector(reportInterval time.Duration) *MetricsCollector {
 	return &MetricsCollector{
-		stopChan: make(chan struct{}),
-		ticker:   time.NewTicker(reportInterval),
+		stopChan:   make(chan struct{}),
+		ticker:     time.NewTicker(reportInterval),
+		loopDone:   make(chan struct{}),
+		slowWindow: reportInterval,
+		created:    time.Now(),
 	}
 }
 
+// SetReporter sets the function that receives metrics reports
+// It must be called before Start
+func (m *MetricsCollector) SetReporter(fn func(Report)) {
+	m.reporter = fn
+}
+
 // Start begins periodic metrics reporting
 // Demonstrates goroutine and ticker usage
 func (m *MetricsCollector) Start() {
+	if !m.started.CompareAndSwap(false, true) {
+		return
+	}
+
 	go func() {
+		defer close(m.loopDone)
 		for {
 			select {
 			case <-m.ticker.C:
@@ -48,10 +105,21 @@ func (m *MetricsCollector) Start() {
 	}()
 }
 
-// Stop halts metrics reporting
+// Stop halts metrics reporting after emitting a final report, so the
+// metrics of the last partial interval aren't lost
+// It is safe to call Stop more than once
 // Demonstrates graceful shutdown
 func (m *MetricsCollector) Stop() {
-	close(m.stopChan)
+	m.stopOnce.Do(func() {
+		close(m.stopChan)
+		if m.started.Load() {
+			// Wait so the final report can't race a periodic one
+			<-m.loopDone
+		} else {
+			m.ticker.Stop()
+		}
+		m.reportMetrics()
+	})
 }
 
 // IncrementProcessed atomically increments the processed counter
@@ -65,9 +133,80 @@ func (m *MetricsCollector) IncrementErrors() {
 	m.errors.Add(1)
 }
 
+// IncrementRetries atomically counts one more attempt after a failure
+func (m *MetricsCollector) IncrementRetries() {
+	m.retries.Add(1)
+}
+
+// IncrementPermanentErrors atomically counts a failure that retrying didn't
+// or couldn't fix
+func (m *MetricsCollector) IncrementPermanentErrors() {
+	m.permanentErrors.Add(1)
+}
+
+// AddBytes atomically adds to the bytes processed
+func (m *MetricsCollector) AddBytes(n int64) {
+	m.bytes.Add(n)
+}
+
+// GetThroughput returns the bytes processed so far and their average rate
+// over the collector's lifetime
+func (m *MetricsCollector) GetThroughput() (bytes int64, bytesPerSecond float64) {
+	bytes = m.bytes.Load()
+	if elapsed := time.Since(m.created).Seconds(); elapsed > 0 {
+		bytesPerSecond = float64(bytes) / elapsed
+	}
+	return
+}
+
+// GetRetryMetrics returns the retry attempts and permanent errors so far
+func (m *MetricsCollector) GetRetryMetrics() (retries uint64, permanentErrors uint64) {
+	return m.retries.Load(), m.permanentErrors.Load()
+}
+
+// SetDurationSampling records only 1 in every n durations, bounding the cost
+// of AddDuration for high-throughput deployments; n <= 1 records every
+// duration, which is the default
+// Each recorded duration counts n times, so the average and the histogram
+// remain unbiased estimates, but percentiles derived from the histogram gain
+// variance, most of all in the tail where few durations fall, and the
+// slowest-file exemplar may miss the slowest file. Durations are picked by
+// position, so a workload that repeats with a period of n can skew them
+// It must be called before any duration is recorded
+func (m *MetricsCollector) SetDurationSampling(n int) {
+	m.sampleEvery = uint64(max(n, 1))
+}
+
 // AddDuration atomically adds to the total duration
 func (m *MetricsCollector) AddDuration(d time.Duration) {
-	m.duration.Add(int64(d))
+	if weight, ok := m.sample(); ok {
+		m.record(d, weight)
+	}
+}
+
+// AddDurationFor adds a file's processing duration and remembers the file
+// if it is the slowest seen in the current window, for use as an exemplar
+func (m *MetricsCollector) AddDurationFor(path string, d time.Duration) {
+	weight, ok := m.sample()
+	if !ok {
+		return
+	}
+	m.record(d, weight)
+	m.observeSlowest(path, d, time.Now())
+}
+
+// sample decides whether to record the next duration and returns its weight
+func (m *MetricsCollector) sample() (uint64, bool) {
+	if m.sampleEvery <= 1 {
+		return 1, true
+	}
+	return m.sampleEvery, m.sampleSeq.Add(1)%m.sampleEvery == 0
+}
+
+// record adds a duration counted weight times to the total and histogram
+func (m *MetricsCollector) record(d time.Duration, weight uint64) {
+	m.duration.Add(int64(d) * int64(weight))
+	m.buckets[bucketIndex(d)].Add(weight)
 }
 
 // GetMetrics returns current metrics
@@ -89,24 +228,27 @@ func (m *MetricsCollector) GetMetrics() (processed uint64, errors uint64, avgDur
 // Demonstrates time formatting and logging
 func (m *MetricsCollector) reportMetrics() {
 	processed, errors, avgDuration := m.GetMetrics()
+	retries, permanentErrors := m.GetRetryMetrics()
+	bytes, bytesPerSecond := m.GetThroughput()
 
 	// Format metrics report
-	report := struct {
-		Timestamp   string
-		Processed   uint64
-		Errors      uint64
-		AvgDuration string
-	}{
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Processed:   processed,
-		Errors:      errors,
-		AvgDuration: avgDuration.String(),
+	report := Report{
+		Timestamp:       time.Now().Format(time.RFC3339),
+		Processed:       processed,
+		Errors:          errors,
+		Retries:         retries,
+		PermanentErrors: permanentErrors,
+		AvgDuration:     avgDuration.String(),
+		BytesProcessed:  bytes,
+		BytesPerSecond:  bytesPerSecond,
 	}
 
-	// In a real application, you might:
+	// The reporter might:
 	// - Log to a file
 	// - Send to a monitoring service
 	// - Update metrics endpoint
 	// - Store in a time-series database
-	_ = report // Placeholder for actual reporting logic
+	if m.reporter != nil {
+		m.reporter(report)
+	}
 }
@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenMetricsExemplar(t *testing.T) {
+	m := NewMetricsCollector(time.Minute)
+	m.IncrementProcessed()
+	m.AddDurationFor("fast.txt", 2*time.Millisecond)
+	m.IncrementProcessed()
+	m.AddDurationFor("slow.json", 300*time.Millisecond)
+	m.IncrementProcessed()
+	m.AddDurationFor("medium.csv", 20*time.Millisecond)
+
+	if path, d := m.Slowest(); path != "slow.json" || d != 300*time.Millisecond {
+		t.Errorf("Expected slow.json as slowest, got %s (%v)", path, d)
+	}
+
+	var out strings.Builder
+	if err := m.WriteOpenMetrics(&out); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	text := out.String()
+
+	// The exemplar sits on the bucket that holds the slowest observation
+	want := `file_analytics_processing_duration_seconds_bucket{le="0.5"} 3 # {path="slow.json"} 0.3`
+	if !strings.Contains(text, want) {
+		t.Errorf("Expected exemplar line %q in:\n%s", want, text)
+	}
+	if !strings.Contains(text, "file_analytics_processing_duration_seconds_count 3") {
+		t.Errorf("Expected histogram count of 3 in:\n%s", text)
+	}
+	if !strings.HasSuffix(text, "# EOF\n") {
+		t.Error("OpenMetrics output must end with # EOF")
+	}
+}
+
+func TestRetryMetrics(t *testing.T) {
+	m := NewMetricsCollector(time.Minute)
+	m.IncrementRetries()
+	m.IncrementRetries()
+	m.IncrementPermanentErrors()
+
+	if retries, permanent := m.GetRetryMetrics(); retries != 2 || permanent != 1 {
+		t.Errorf("Expected 2 retries and 1 permanent error, got %d and %d", retries, permanent)
+	}
+
+	var out strings.Builder
+	if err := m.WriteOpenMetrics(&out); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	for _, want := range []string{"file_analytics_retries_total 2\n", "file_analytics_permanent_errors_total 1\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSlowestWindowExpires(t *testing.T) {
+	m := NewMetricsCollector(time.Minute)
+	start := time.Now()
+
+	m.observeSlowest("old-outlier.log", time.Second, start)
+	m.observeSlowest("recent.log", 10*time.Millisecond, start.Add(30*time.Second))
+	if path, _ := m.Slowest(); path != "old-outlier.log" {
+		t.Errorf("Expected outlier to be kept within the window, got %s", path)
+	}
+
+	m.observeSlowest("newer.log", 10*time.Millisecond, start.Add(2*time.Minute))
+	if path, _ := m.Slowest(); path != "newer.log" {
+		t.Errorf("Expected window to roll over, got %s", path)
+	}
+}
+
+func TestDurationSampling(t *testing.T) {
+	m := NewMetricsCollector(time.Minute)
+	m.SetDurationSampling(4)
+	for i := 0; i < 8; i++ {
+		m.IncrementProcessed()
+		m.AddDuration(10 * time.Millisecond)
+	}
+
+	// Two of the eight durations are recorded, each counting four times
+	if _, _, avg := m.GetMetrics(); avg != 10*time.Millisecond {
+		t.Errorf("Expected an average of 10ms, got %v", avg)
+	}
+	var out strings.Builder
+	if err := m.WriteOpenMetrics(&out); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	if !strings.Contains(out.String(), "file_analytics_processing_duration_seconds_count 8") {
+		t.Errorf("Expected a weighted histogram count of 8 in:\n%s", out.String())
+	}
+	if got := m.sampleSeq.Load(); got != 8 {
+		t.Errorf("Expected 8 sampling decisions, got %d", got)
+	}
+
+	// The default records every duration without touching the sequence
+	all := NewMetricsCollector(time.Minute)
+	all.AddDuration(time.Millisecond)
+	if all.buckets[bucketIndex(time.Millisecond)].Load() != 1 || all.sampleSeq.Load() != 0 {
+		t.Error("Expected every duration to be recorded by default")
+	}
+}
+
+func TestThroughputMetrics(t *testing.T) {
+	m := NewMetricsCollector(time.Minute)
+	m.created = time.Now().Add(-2 * time.Second)
+	m.AddBytes(3000)
+	m.AddBytes(1000)
+
+	bytes, rate := m.GetThroughput()
+	if bytes != 4000 {
+		t.Errorf("Expected 4000 bytes, got %d", bytes)
+	}
+	// Averaged over the collector's two seconds, with slack for the test itself
+	if rate < 1900 || rate > 2000 {
+		t.Errorf("Expected about 2000 bytes per second, got %g", rate)
+	}
+
+	var out strings.Builder
+	if err := m.WriteOpenMetrics(&out); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	for _, want := range []string{"file_analytics_bytes_processed_total 4000\n", "file_analytics_bytes_per_second "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestStopEmitsFinalReport(t *testing.T) {
+	m := NewMetricsCollector(time.Hour)
+
+	var reports []Report
+	m.SetReporter(func(r Report) {
+		reports = append(reports, r)
+	})
+
+	m.Start()
+	m.IncrementProcessed()
+	m.IncrementErrors()
+
+	// A second Stop must neither panic nor report again
+	m.Stop()
+	m.Stop()
+
+	if len(reports) != 1 {
+		t.Fatalf("Expected exactly one final report, got %d", len(reports))
+	}
+	if reports[0].Processed != 1 || reports[0].Errors != 1 {
+		t.Errorf("Final report missed the last interval: %+v", reports[0])
+	}
+}
@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the duration histogram
+var durationBuckets = [...]float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+// maxExemplarPathLen keeps exemplar label sets within the OpenMetrics limit
+// of 128 characters
+const maxExemplarPathLen = 100
+
+// OpenMetricsContentType is the content type of WriteOpenMetrics output
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// exemplar ties an observed duration to the file that produced it
+type exemplar struct {
+	path     string
+	duration time.Duration
+	at       time.Time
+}
+
+// bucketIndex returns the histogram bucket a duration falls into
+func bucketIndex(d time.Duration) int {
+	seconds := d.Seconds()
+	for i, upper := range durationBuckets {
+		if seconds <= upper {
+			return i
+		}
+	}
+	return len(durationBuckets)
+}
+
+// observeSlowest keeps the slowest file of the current window
+// Once the window has elapsed the next observation starts a new one,
+// so a single old outlier doesn't hide newer spikes
+func (m *MetricsCollector) observeSlowest(path string, d time.Duration, now time.Time) {
+	m.slowMu.Lock()
+	defer m.slowMu.Unlock()
+
+	expired := m.slowWindow > 0 && now.Sub(m.slowest.at) > m.slowWindow
+	if m.slowest.path == "" || expired || d > m.slowest.duration {
+		m.slowest = exemplar{path: path, duration: d, at: now}
+	}
+}
+
+// Slowest returns the slowest file recorded in the current window
+func (m *MetricsCollector) Slowest() (path string, d time.Duration) {
+	m.slowMu.Lock()
+	defer m.slowMu.Unlock()
+	return m.slowest.path, m.slowest.duration
+}
+
+// WriteOpenMetrics writes the collector's metrics in OpenMetrics text format
+// The slowest recent file is attached as an exemplar to its duration bucket
+func (m *MetricsCollector) WriteOpenMetrics(w io.Writer) error {
+	processed, errors, _ := m.GetMetrics()
+	retries, permanentErrors := m.GetRetryMetrics()
+	bytes, bytesPerSecond := m.GetThroughput()
+
+	m.slowMu.Lock()
+	slow := m.slowest
+	m.slowMu.Unlock()
+	slowBucket := -1
+	if slow.path != "" {
+		slowBucket = bucketIndex(slow.duration)
+	}
+
+	var b strings.Builder
+	b.WriteString("# TYPE file_analytics_files_processed counter\n")
+	fmt.Fprintf(&b, "file_analytics_files_processed_total %d\n", processed)
+	b.WriteString("# TYPE file_analytics_errors counter\n")
+	fmt.Fprintf(&b, "file_analytics_errors_total %d\n", errors)
+	b.WriteString("# TYPE file_analytics_retries counter\n")
+	fmt.Fprintf(&b, "file_analytics_retries_total %d\n", retries)
+	b.WriteString("# TYPE file_analytics_permanent_errors counter\n")
+	fmt.Fprintf(&b, "file_analytics_permanent_errors_total %d\n", permanentErrors)
+	b.WriteString("# TYPE file_analytics_bytes_processed counter\n")
+	fmt.Fprintf(&b, "file_analytics_bytes_processed_total %d\n", bytes)
+	b.WriteString("# TYPE file_analytics_bytes_per_second gauge\n")
+	fmt.Fprintf(&b, "file_analytics_bytes_per_second %g\n", bytesPerSecond)
+
+	b.WriteString("# TYPE file_analytics_processing_duration_seconds histogram\n")
+	b.WriteString("# UNIT file_analytics_processing_duration_seconds seconds\n")
+	var cumulative uint64
+	for i := range m.buckets {
+		cumulative += m.buckets[i].Load()
+
+		le := "+Inf"
+		if i < len(durationBuckets) {
+			le = fmt.Sprintf("%g", durationBuckets[i])
+		}
+		fmt.Fprintf(&b, "file_analytics_processing_duration_seconds_bucket{le=\"%s\"} %d", le, cumulative)
+		if i == slowBucket {
+			fmt.Fprintf(&b, " # {path=\"%s\"} %g %.3f", escapeLabel(truncatePath(slow.path)),
+				slow.duration.Seconds(), float64(slow.at.UnixNano())/1e9)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "file_analytics_processing_duration_seconds_sum %g\n", time.Duration(m.duration.Load()).Seconds())
+	fmt.Fprintf(&b, "file_analytics_processing_duration_seconds_count %d\n", cumulative)
+	b.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// truncatePath keeps the end of long paths, which identifies the file best
+func truncatePath(path string) string {
+	if len(path) <= maxExemplarPathLen {
+		return path
+	}
+	start := len(path) - maxExemplarPathLen + 3
+	// Don't split a multi-byte character
+	for start < len(path) && !utf8.RuneStart(path[start]) {
+		start++
+	}
+	return "..." + path[start:]
+}
+
+// escapeLabel escapes a label value per the OpenMetrics text format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,293 @@
+package processor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// ErrEntryNotFound is the cause of the error returned when an archive has no
+// entry with the requested name
+var ErrEntryNotFound = errors.New("archive entry not found")
+
+// ErrArchiveTooLarge is the cause of the error returned when an archive
+// decompresses to more than the allowed total size
+var ErrArchiveTooLarge = errors.New("archive exceeds the decompressed size limit")
+
+// errStopWalk ends an archive walk early without reporting an error
+var errStopWalk = errors.New("stop archive walk")
+
+// zipMagic starts every zip archive that has at least one entry
+var zipMagic = []byte("PK\x03\x04")
+
+// ProcessArchiveEntry extracts the single entry called name from a zip or tar
+// archive and processes it with the first of processors that handles its name
+// Tar archives may be gzip, bzip2, xz, zstd or snappy compressed. Zip
+// entries are read directly; tar archives are streamed only up to the entry
+// The result's Path is "<archive>:<name>" and its size is the entry's size
+func ProcessArchiveEntry(ctx context.Context, archive, name string, processors []Processor) (models.ProcessResult, error) {
+	result := models.ProcessResult{
+		FileInfo: models.FileInfo{Path: archive + ":" + name},
+	}
+
+	name = entryName(name)
+	proc := processorFor(processors, name)
+	if proc == nil {
+		result.Error = apperrors.NewProcessError(apperrors.ErrorTypeValidation, archive, "no processor for archive entry "+name)
+		return result, result.Error
+	}
+
+	tmpPath, err := extractEntry(ctx, archive, name, proc)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	defer os.Remove(tmpPath)
+
+	entryResult, err := SafeProcess(ctx, proc, tmpPath)
+	entryResult.Path = result.Path
+	if err != nil {
+		entryResult.Error = fmt.Errorf("failed to process archive entry %s: %w", name, err)
+		return entryResult, entryResult.Error
+	}
+	return entryResult, nil
+}
+
+// ProcessArchive processes every regular file in a zip or tar archive with
+// the first of processors that handles its name, passing each result to fn
+// in archive order. Entries no processor handles are passed with a
+// validation error, and each result's Path is the entry's name
+// Once the entries have decompressed to more than maxTotal bytes (0 means no
+// limit) the walk stops with an error wrapping ErrArchiveTooLarge
+// No file slot is held for the archive itself, so processors can acquire
+// their own while it is being read
+func ProcessArchive(ctx context.Context, archive string, processors []Processor, maxTotal int64, fn func(models.ProcessResult) error) error {
+	var total int64
+	err := walkArchive(archive, func(name string, entry io.Reader) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		result := models.ProcessResult{FileInfo: models.FileInfo{Path: name}}
+		proc := processorFor(processors, name)
+		if proc == nil {
+			LoggerFrom(ctx).Debugf("No processor for archive entry %s in %s", name, archive)
+			result.Error = apperrors.NewProcessError(apperrors.ErrorTypeValidation, name, "unsupported file type")
+			return fn(result)
+		}
+
+		limit := int64(-1)
+		if maxTotal > 0 {
+			limit = maxTotal - total
+		}
+		tmpPath, written, err := extractTo(name, entry, proc, limit)
+		total += written
+		if maxTotal > 0 && total > maxTotal {
+			if tmpPath != "" {
+				os.Remove(tmpPath)
+			}
+			return apperrors.NewProcessError(apperrors.ErrorTypeValidation, archive, fmt.Sprintf("decompressed size exceeds %d bytes", maxTotal), ErrArchiveTooLarge)
+		}
+		if err != nil {
+			result.Error = err
+			return fn(result)
+		}
+		defer os.Remove(tmpPath)
+
+		entryResult, err := SafeProcess(ctx, proc, tmpPath)
+		entryResult.Path = name
+		if err != nil {
+			entryResult.Error = err
+		}
+		return fn(entryResult)
+	})
+	if err != nil {
+		var procErr *apperrors.ProcessError
+		if errors.As(err, &procErr) || errors.Is(err, ctx.Err()) {
+			return err
+		}
+		return apperrors.NewProcessError(apperrors.ErrorTypeFormat, archive, "failed to read archive", err)
+	}
+	return nil
+}
+
+// processorFor returns the first of processors that handles the base name of
+// the archive entry name, or nil if none does
+func processorFor(processors []Processor, name string) Processor {
+	for _, p := range processors {
+		if p.CanHandle(path.Base(name)) {
+			return p
+		}
+	}
+	return nil
+}
+
+// extractEntry copies the named entry of archive to a temporary file whose
+// name ends in the entry's base name, so proc recognizes it
+// The copy is held to proc's size limit, when it has one
+func extractEntry(ctx context.Context, archive, name string, proc Processor) (string, error) {
+	release, err := acquireFile(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	var tmpPath string
+	err = walkArchive(archive, func(entryName string, entry io.Reader) error {
+		if entryName != name {
+			return nil
+		}
+		var extractErr error
+		tmpPath, _, extractErr = extractTo(name, entry, proc, -1)
+		if extractErr != nil {
+			return extractErr
+		}
+		return errStopWalk
+	})
+	switch {
+	case tmpPath != "":
+		return tmpPath, nil
+	case err == nil:
+		err = ErrEntryNotFound
+	}
+
+	var procErr *apperrors.ProcessError
+	if errors.As(err, &procErr) {
+		return "", err
+	}
+	return "", apperrors.NewProcessError(apperrors.ErrorTypeFormat, archive, "failed to open entry "+name, err)
+}
+
+// extractTo copies entry to a temporary file whose name ends in the entry's
+// base name, returning the file's path and the bytes written
+// The copy is held to proc's size limit, when it has one, and fails with
+// nothing to clean up after more than limit bytes unless limit is negative
+func extractTo(name string, entry io.Reader, proc Processor, limit int64) (string, int64, error) {
+	content := entry
+	limited, hasLimit := proc.(interface{ MaxFileSize() int64 })
+	if hasLimit && limited.MaxFileSize() > 0 {
+		content = io.LimitReader(content, limited.MaxFileSize()+1)
+	}
+	if limit >= 0 {
+		content = io.LimitReader(content, limit+1)
+	}
+
+	tmp, err := os.CreateTemp("", "entry-*-"+path.Base(name))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer tmp.Close()
+
+	written, err := io.Copy(tmp, content)
+	if err == nil && limit >= 0 && written > limit {
+		err = ErrArchiveTooLarge
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", written, apperrors.NewProcessError(apperrors.ErrorTypeIO, name, "failed to extract entry", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", written, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return tmp.Name(), written, nil
+}
+
+// walkArchive calls fn with the cleaned name and content of each regular
+// file in a zip or tar archive, stopping at the first error
+// Returning errStopWalk from fn ends the walk without an error
+func walkArchive(archive string, fn func(name string, entry io.Reader) error) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return models.FileError(archive, "open file", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return models.FileError(archive, "get file info", err)
+	}
+
+	reader := bufio.NewReader(file)
+	header, _ := reader.Peek(len(zipMagic))
+	if bytes.Equal(header, zipMagic) {
+		err = walkZip(file, info.Size(), fn)
+	} else {
+		err = walkTar(reader, fn)
+	}
+	if errors.Is(err, errStopWalk) {
+		return nil
+	}
+	return err
+}
+
+// walkZip visits the entries listed in the zip's central directory
+func walkZip(file io.ReaderAt, size int64, fn func(string, io.Reader) error) error {
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		entry, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = fn(entryName(f.Name), entry)
+		entry.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkTar reads through a possibly compressed tar stream, visiting each
+// regular file
+func walkTar(reader *bufio.Reader, fn func(string, io.Reader) error) error {
+	var stream io.Reader = reader
+	header, _ := reader.Peek(maxMagicLen)
+	if format := detectCompression(header); format != nil {
+		decompressed, err := format.open(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read %s header: %w", format.name, err)
+		}
+		defer decompressed.Close()
+		stream = decompressed
+	}
+
+	archive := tar.NewReader(stream)
+	for {
+		hdr, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(entryName(hdr.Name), archive); err != nil {
+			return err
+		}
+	}
+}
+
+// entryName cleans an archive entry name so it can't refer outside the archive
+func entryName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
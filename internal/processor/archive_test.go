@@ -0,0 +1,179 @@
+package processor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+func TestProcessArchiveEntry(t *testing.T) {
+	entries := map[string]string{
+		"docs/readme.txt": "one two\nthree\n",
+		"data/rows.json":  `{"id": 1, "tags": ["a", "b"]}`,
+	}
+
+	zipPath, tarPath := writeTestArchives(t, t.TempDir(), entries)
+
+	processors := []Processor{NewTextProcessor(4096), NewJSONProcessor(4096)}
+	for _, archive := range []string{zipPath, tarPath} {
+		result, err := ProcessArchiveEntry(context.Background(), archive, "docs/readme.txt", processors)
+		if err != nil {
+			t.Fatalf("Failed to process entry of %s: %v", archive, err)
+		}
+		if result.Path != archive+":docs/readme.txt" || result.Type != "text" || result.Words != 3 {
+			t.Errorf("Unexpected result for %s: %+v", archive, result)
+		}
+		if result.Size != int64(len(entries["docs/readme.txt"])) {
+			t.Errorf("Expected the entry's size, got %d", result.Size)
+		}
+
+		json, err := ProcessArchiveEntry(context.Background(), archive, "data/rows.json", processors)
+		if err != nil || json.Type != "json" {
+			t.Errorf("Expected the JSON entry to be processed as json, got %+v (%v)", json, err)
+		}
+
+		_, err = ProcessArchiveEntry(context.Background(), archive, "docs/missing.txt", processors)
+		if !errors.Is(err, ErrEntryNotFound) {
+			t.Errorf("Expected ErrEntryNotFound for %s, got %v", archive, err)
+		}
+	}
+
+	// Entries no processor handles are rejected before the archive is read
+	if _, err := ProcessArchiveEntry(context.Background(), zipPath, "image.png", processors); err == nil {
+		t.Error("Expected an error for an unsupported entry type")
+	}
+
+	// Oversized entries are held to the processor's limit
+	limited := NewTextProcessor(4096)
+	limited.SetMaxFileSize(4)
+	_, err := ProcessArchiveEntry(context.Background(), zipPath, "docs/readme.txt", []Processor{limited})
+	if !errors.Is(err, models.ErrFileTooLarge) {
+		t.Errorf("Expected ErrFileTooLarge, got %v", err)
+	}
+}
+
+func TestProcessArchive(t *testing.T) {
+	entries := map[string]string{
+		"docs/readme.txt": "one two\nthree\n",
+		"data/rows.json":  `{"id": 1}`,
+		"image.png":       "not really a png",
+	}
+	zipPath, tarPath := writeTestArchives(t, t.TempDir(), entries)
+	processors := []Processor{NewTextProcessor(4096), NewJSONProcessor(4096)}
+
+	for _, archive := range []string{zipPath, tarPath} {
+		results := map[string]models.ProcessResult{}
+		err := ProcessArchive(context.Background(), archive, processors, 0, func(result models.ProcessResult) error {
+			results[result.Path] = result
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to process %s: %v", archive, err)
+		}
+		if len(results) != len(entries) {
+			t.Fatalf("Expected %d results for %s, got %d", len(entries), archive, len(results))
+		}
+		if r := results["docs/readme.txt"]; r.Error != nil || r.Words != 3 {
+			t.Errorf("Unexpected text result for %s: %+v", archive, r)
+		}
+		if r := results["data/rows.json"]; r.Error != nil || r.Type != "json" {
+			t.Errorf("Unexpected JSON result for %s: %+v", archive, r)
+		}
+		if r := results["image.png"]; r.Error == nil {
+			t.Errorf("Expected an inline error for the unsupported entry of %s", archive)
+		}
+
+		// The walk stops once the entries decompress past the cap
+		err = ProcessArchive(context.Background(), archive, processors, 10, func(models.ProcessResult) error { return nil })
+		if !errors.Is(err, ErrArchiveTooLarge) {
+			t.Errorf("Expected ErrArchiveTooLarge for %s, got %v", archive, err)
+		}
+	}
+
+	// Anything else is a format error
+	plain := filepath.Join(t.TempDir(), "plain.zip")
+	os.WriteFile(plain, []byte("not an archive at all, just some text that is long enough"), 0644)
+	if err := ProcessArchive(context.Background(), plain, processors, 0, func(models.ProcessResult) error { return nil }); err == nil {
+		t.Error("Expected an error for a file that isn't an archive")
+	}
+}
+
+func TestProcessArchiveRecoversPanic(t *testing.T) {
+	entries := map[string]string{
+		"bad.txt":  "malformed\n",
+		"good.txt": "one two\n",
+	}
+	zipPath, tarPath := writeTestArchives(t, t.TempDir(), entries)
+	processors := []Processor{&panickyProcessor{TextProcessor: NewTextProcessor(4096)}}
+
+	for _, archive := range []string{zipPath, tarPath} {
+		results := map[string]models.ProcessResult{}
+		err := ProcessArchive(context.Background(), archive, processors, 0, func(result models.ProcessResult) error {
+			results[result.Path] = result
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected the panic to stay inline for %s, got %v", archive, err)
+		}
+		var panicErr *PanicError
+		if r := results["bad.txt"]; !apperrors.IsErrorType(r.Error, apperrors.ErrorTypeFormat) || !errors.As(r.Error, &panicErr) {
+			t.Errorf("Expected a format error for the panicking entry of %s, got %v", archive, r.Error)
+		}
+		if r := results["good.txt"]; r.Error != nil || r.Words != 2 {
+			t.Errorf("Unexpected result for the other entry of %s: %+v", archive, r)
+		}
+	}
+
+	result, err := ProcessArchiveEntry(context.Background(), zipPath, "bad.txt", processors)
+	if !apperrors.IsErrorType(err, apperrors.ErrorTypeFormat) || result.Path != zipPath+":bad.txt" {
+		t.Errorf("Expected a format error for the single entry, got %v for %s", err, result.Path)
+	}
+}
+
+// writeTestArchives writes entries to bundle.zip and bundle.tar.gz in dir
+func writeTestArchives(t *testing.T, tmpDir string, entries map[string]string) (string, string) {
+	t.Helper()
+
+	// A zip archive
+	zipPath := filepath.Join(tmpDir, "bundle.zip")
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	zw := zip.NewWriter(zipFile)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add zip entry: %v", err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	zipFile.Close()
+
+	// A gzipped tar archive
+	tarPath := filepath.Join(tmpDir, "bundle.tar.gz")
+	tarFile, err := os.Create(tarPath)
+	if err != nil {
+		t.Fatalf("Failed to create tar: %v", err)
+	}
+	gz := gzip.NewWriter(tarFile)
+	tw := tar.NewWriter(gz)
+	for name, content := range entries {
+		tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	tarFile.Close()
+
+	return zipPath, tarPath
+}
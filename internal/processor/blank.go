@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"encoding/binary"
+	"unicode"
+	"unicode/utf8"
+)
+
+// blankDetector is an io.Writer that reports whether a stream holds nothing
+// but whitespace
+// The encoding is sniffed from a UTF-16 byte order mark at the start of the
+// stream, defaulting to UTF-8, so UTF-16 spaces aren't mistaken for content
+type blankDetector struct {
+	order   binary.ByteOrder
+	started bool
+	blank   bool
+	carry   []byte
+}
+
+// newBlankDetector creates a detector for a stream that is blank until proven otherwise
+func newBlankDetector() *blankDetector {
+	return &blankDetector{blank: true}
+}
+
+// Write implements io.Writer, decoding characters across chunk boundaries
+// Once content has been seen the remaining input is ignored
+func (d *blankDetector) Write(p []byte) (int, error) {
+	n := len(p)
+	if !d.blank {
+		return n, nil
+	}
+
+	data := p
+	if len(d.carry) > 0 {
+		data = append(d.carry, p...)
+		d.carry = nil
+	}
+
+	if !d.started {
+		if len(data) < 2 {
+			d.carry = append([]byte(nil), data...)
+			return n, nil
+		}
+		d.started = true
+		switch {
+		case data[0] == 0xFF && data[1] == 0xFE:
+			d.order = binary.LittleEndian
+		case data[0] == 0xFE && data[1] == 0xFF:
+			d.order = binary.BigEndian
+		}
+	}
+
+	for len(data) > 0 {
+		var r rune
+		var size int
+		if d.order != nil {
+			if len(data) < 2 {
+				break
+			}
+			r, size = rune(d.order.Uint16(data)), 2
+		} else {
+			if !utf8.FullRune(data) {
+				break
+			}
+			r, size = utf8.DecodeRune(data)
+		}
+
+		// The byte order mark is not content
+		if !unicode.IsSpace(r) && r != '\uFEFF' {
+			d.blank = false
+			return n, nil
+		}
+		data = data[size:]
+	}
+
+	d.carry = append([]byte(nil), data...)
+	return n, nil
+}
+
+// Blank reports whether everything written so far was whitespace
+// A dangling partial character counts as content
+func (d *blankDetector) Blank() bool {
+	if !d.started && len(d.carry) == 1 {
+		// A single byte can't be a UTF-16 character, so decode it as UTF-8
+		return d.carry[0] < utf8.RuneSelf && unicode.IsSpace(rune(d.carry[0]))
+	}
+	return d.blank && len(d.carry) == 0
+}
@@ -0,0 +1,29 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// utf8BOM is the byte order mark some editors prepend to UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// stripBOM wraps r so that a leading UTF-8 BOM is discarded
+// It reports whether a BOM was present
+func stripBOM(r io.Reader) (io.Reader, bool, error) {
+	br := bufio.NewReader(r)
+
+	head, err := br.Peek(len(utf8BOM))
+	if err != nil && err != io.EOF {
+		return nil, false, err
+	}
+
+	if bytes.Equal(head, utf8BOM) {
+		if _, err := br.Discard(len(utf8BOM)); err != nil {
+			return nil, false, err
+		}
+		return br, true, nil
+	}
+	return br, false, nil
+}
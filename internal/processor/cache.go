@@ -0,0 +1,126 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// cacheEntry is a single cached result together with the file
+// attributes and processor configuration used to decide whether it is
+// still valid
+type cacheEntry struct {
+	Size    int64                `json:"size"`
+	ModTime time.Time            `json:"mod_time"`
+	Config  string               `json:"config,omitempty"`
+	Result  models.ProcessResult `json:"result"`
+}
+
+// ResultCache is an on-disk cache of processing results keyed by path,
+// invalidated whenever a file's size or modification time changes or the
+// result was produced by processors configured differently
+type ResultCache struct {
+	path string
+	// config identifies the processor configuration of this run
+	config  string
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	hits    int
+	misses  int
+}
+
+// LoadResultCache opens the cache stored at path for a run whose processor
+// configuration is identified by config, e.g. a hash of the settings
+// Entries stored under another config are misses
+// A missing cache file yields an empty cache rather than an error
+func LoadResultCache(path, config string) (*ResultCache, error) {
+	c := &ResultCache{
+		path:    path,
+		config:  config,
+		entries: make(map[string]cacheEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse cache file: %w", err)
+	}
+	return c, nil
+}
+
+// Lookup returns the cached result for path if the file is unchanged and
+// was processed with the same configuration
+func (c *ResultCache) Lookup(path string, info os.FileInfo) (models.ProcessResult, bool) {
+	key := cacheKey(path)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) || entry.Config != c.config {
+		c.misses++
+		return models.ProcessResult{}, false
+	}
+
+	c.hits++
+	return entry.Result, true
+}
+
+// Store records a successful result, keyed by its path, size, mtime and
+// the cache's configuration
+func (c *ResultCache) Store(result models.ProcessResult) {
+	if result.Error != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[cacheKey(result.Path)] = cacheEntry{
+		Size:    result.Size,
+		ModTime: result.Modified,
+		Config:  c.config,
+		Result:  result,
+	}
+}
+
+// Save writes the cache back to disk
+func (c *ResultCache) Save() error {
+	c.mu.Lock()
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+// Stats returns how many lookups were served from the cache and how many missed
+func (c *ResultCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// cacheKey normalizes a path so relative and absolute spellings share an entry
+func cacheKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
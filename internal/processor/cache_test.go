@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResultCacheInvalidation(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.txt")
+	cacheFile := filepath.Join(tmpDir, "cache.json")
+
+	if err := os.WriteFile(testFile, []byte("hello world\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := NewTextProcessor(4096).Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	cache, err := LoadResultCache(cacheFile, "v1")
+	if err != nil {
+		t.Fatalf("Failed to load empty cache: %v", err)
+	}
+	cache.Store(result)
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Failed to save cache: %v", err)
+	}
+
+	// Reload from disk and expect a hit for the unchanged file
+	cache, err = LoadResultCache(cacheFile, "v1")
+	if err != nil {
+		t.Fatalf("Failed to reload cache: %v", err)
+	}
+
+	info, _ := os.Stat(testFile)
+	cached, ok := cache.Lookup(testFile, info)
+	if !ok {
+		t.Fatal("Expected cache hit for unchanged file")
+	}
+	if cached.Words != result.Words || cached.Lines != result.Lines {
+		t.Errorf("Cached result mismatch: got %+v, want %+v", cached, result)
+	}
+
+	// Changing the content (and so size and mtime) must invalidate the entry
+	if err := os.WriteFile(testFile, []byte("hello there world\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify test file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(testFile, later, later)
+
+	info, _ = os.Stat(testFile)
+	if _, ok := cache.Lookup(testFile, info); ok {
+		t.Error("Expected cache miss after file changed")
+	}
+
+	hits, misses := cache.Stats()
+	if hits != 1 || misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d and %d", hits, misses)
+	}
+}
+
+func TestResultCacheConfigMismatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.txt")
+	cacheFile := filepath.Join(tmpDir, "cache.json")
+
+	if err := os.WriteFile(testFile, []byte("hello world\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	result, err := NewTextProcessor(4096).Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	cache, err := LoadResultCache(cacheFile, "v1")
+	if err != nil {
+		t.Fatalf("Failed to load empty cache: %v", err)
+	}
+	cache.Store(result)
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Failed to save cache: %v", err)
+	}
+
+	// A run with processors configured differently must not reuse the entry
+	cache, err = LoadResultCache(cacheFile, "v2")
+	if err != nil {
+		t.Fatalf("Failed to reload cache: %v", err)
+	}
+	info, _ := os.Stat(testFile)
+	if _, ok := cache.Lookup(testFile, info); ok {
+		t.Error("Expected cache miss for another configuration")
+	}
+}
@@ -0,0 +1,352 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compression describes a compressed format recognized by its magic bytes
+type compression struct {
+	name   string
+	magic  []byte
+	suffix []string
+	// open starts decompressing; closing the reader releases the decoder
+	open func(io.Reader) (io.ReadCloser, error)
+}
+
+// compressions are the formats the compressed processor can read
+var compressions = []compression{
+	{
+		name:   "gzip",
+		magic:  []byte{0x1f, 0x8b},
+		suffix: []string{".gz", ".gzip"},
+		open:   func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	},
+	{
+		name:   "bzip2",
+		magic:  []byte("BZh"),
+		suffix: []string{".bz2"},
+		open:   func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(bzip2.NewReader(r)), nil },
+	},
+	{
+		name:   "xz",
+		magic:  []byte{0xfd, '7', 'z', 'X', 'Z', 0x00},
+		suffix: []string{".xz"},
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			reader, err := xz.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(reader), nil
+		},
+	},
+	{
+		name:   "zstd",
+		magic:  []byte{0x28, 0xb5, 0x2f, 0xfd},
+		suffix: []string{".zst", ".zstd"},
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			// A single synchronous decoder keeps memory use per file small
+			decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+	},
+	{
+		// The framed format, starting with a stream identifier chunk;
+		// raw snappy blocks have no magic bytes
+		name:   "snappy",
+		magic:  []byte("\xff\x06\x00\x00sNaPpY"),
+		suffix: []string{".sz", ".snappy"},
+		open:   func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(s2.NewReader(r)), nil },
+	},
+}
+
+// maxMagicLen is the longest magic byte sequence in compressions
+const maxMagicLen = 10
+
+// sniffLen is how much decompressed content is inspected to pick a processor
+// when the file name doesn't say
+const sniffLen = 512
+
+// DefaultMaxDecompressedSize is the default cap on the decompressed size of
+// a compressed file, so a small decompression bomb can't fill the disk even
+// when --max-file-size leaves files unlimited
+const DefaultMaxDecompressedSize int64 = 1 << 30
+
+// ErrDecompressedTooLarge is the cause of the error returned when a file
+// decompresses to more than the processor's decompressed size limit
+var ErrDecompressedTooLarge = errors.New("decompressed content exceeds the size limit")
+
+// CompressedProcessor decompresses gzip, bzip2, xz, zstd and snappy files
+// and hands the content to the processor for the underlying format
+// The compression is detected from the magic bytes, so misleading or
+// missing extensions don't matter
+type CompressedProcessor struct {
+	*models.BaseProcessor
+	inner []Processor
+	// maxDecompressed caps the decompressed size; 0 means unlimited
+	maxDecompressed int64
+}
+
+// NewCompressedProcessor creates a processor that decompresses files for
+// inner, up to DefaultMaxDecompressedSize bytes each
+func NewCompressedProcessor(bufferSize int, inner ...Processor) *CompressedProcessor {
+	return &CompressedProcessor{
+		BaseProcessor:   models.NewBaseProcessor("compressed", bufferSize),
+		inner:           inner,
+		maxDecompressed: DefaultMaxDecompressedSize,
+	}
+}
+
+// SetMaxDecompressedSize sets how many bytes a file may decompress to; 0
+// removes the limit, leaving only the max file size
+func (p *CompressedProcessor) SetMaxDecompressedSize(n int64) {
+	p.maxDecompressed = max(n, 0)
+}
+
+// CanHandle implements the Processor interface by sniffing the magic bytes
+func (p *CompressedProcessor) CanHandle(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, maxMagicLen)
+	n, _ := io.ReadFull(file, header)
+	return detectCompression(header[:n]) != nil
+}
+
+// SupportedExtensions implements ExtensionLister with the usual suffixes
+// of the formats it reads; files are still recognized by content
+func (p *CompressedProcessor) SupportedExtensions() []string {
+	var extensions []string
+	for _, c := range compressions {
+		extensions = append(extensions, c.suffix...)
+	}
+	return extensions
+}
+
+// Process implements the Processor interface
+// The content is decompressed to a temporary file named after the original
+// without its compression suffix, which the inner processor then reads
+func (p *CompressedProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
+	result := models.ProcessResult{
+		FileInfo: models.FileInfo{
+			Path:      path,
+			Type:      "compressed",
+			Processed: time.Now(),
+		},
+	}
+
+	// Get file info
+	info, err := os.Stat(path)
+	if err != nil {
+		result.Error = models.FileError(path, "get file info", err)
+		return result, result.Error
+	}
+	result.Size = info.Size()
+	result.Modified = info.ModTime()
+
+	// Skip oversized files before reading them
+	if err := p.CheckFileSize(path, info.Size()); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	start := time.Now()
+	// Hash the compressed file as stored, in the same pass, when enabled
+	digest, err := newDigest(ctx, p.HashAlgorithm())
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	tmpPath, format, inner, err := p.decompress(ctx, path, digest)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	defer os.Remove(tmpPath)
+	LoggerFrom(ctx).Debugf("Reading %s as %s", path, format.name)
+
+	innerResult, err := inner.Process(ctx, tmpPath)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to process decompressed %s: %w", format.name, err)
+		return result, result.Error
+	}
+
+	// Report the compressed file itself; Bytes stays the decompressed count
+	innerResult.FileInfo = models.FileInfo{
+		Path:      path,
+		Size:      result.Size,
+		Modified:  result.Modified,
+		Processed: result.Processed,
+		Type:      innerResult.Type,
+	}
+	innerResult.Compression = format.name
+	innerResult.Hash = digestSum(digest)
+	innerResult.Duration = time.Since(start)
+	return innerResult, nil
+}
+
+// decompress detects the compression of path, picks the inner processor and
+// writes the decompressed content to a temporary file
+// When digest is non-nil it receives every byte of the compressed file
+// It holds a file slot only while reading path; the inner processor takes
+// its own for the temporary file
+func (p *CompressedProcessor) decompress(ctx context.Context, path string, digest hash.Hash) (string, *compression, Processor, error) {
+	release, err := acquireFile(ctx)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	defer release()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, nil, models.FileError(path, "open file", err)
+	}
+	defer file.Close()
+
+	compressed := bufio.NewReader(teeDigest(file, digest))
+	header, _ := compressed.Peek(maxMagicLen)
+	format := detectCompression(header)
+	if format == nil {
+		return "", nil, nil, apperrors.NewProcessError(apperrors.ErrorTypeFormat, path, "not a gzip, bzip2, xz, zstd or snappy file")
+	}
+
+	decompressed, err := format.open(compressed)
+	if err != nil {
+		return "", nil, nil, apperrors.NewProcessError(apperrors.ErrorTypeFormat, path, "failed to read "+format.name+" header", err)
+	}
+	defer decompressed.Close()
+	content := bufio.NewReaderSize(decompressed, sniffLen)
+	sniff, _ := content.Peek(sniffLen)
+
+	name := innerName(filepath.Base(path), format)
+	inner := p.innerFor(name, sniff)
+	if inner == nil {
+		return "", nil, nil, apperrors.NewProcessError(apperrors.ErrorTypeValidation, path, "no processor for decompressed content of "+name)
+	}
+
+	tmpPath, err := p.decompressTo(path, name, content)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	// Decompressors may stop before trailing bytes; the digest needs them all
+	if digest != nil {
+		if _, err := io.Copy(io.Discard, compressed); err != nil {
+			os.Remove(tmpPath)
+			return "", nil, nil, fmt.Errorf("failed to hash file: %w", err)
+		}
+	}
+	return tmpPath, format, inner, nil
+}
+
+// decompressTo copies the decompressed content to a temporary file whose
+// name ends in name, so extension-based processors recognize it
+// The decompressed size is held to the same limit as files on disk and to
+// the decompressed size limit, and copying stops just past the lower one so
+// a decompression bomb can't fill the disk
+func (p *CompressedProcessor) decompressTo(path, name string, content io.Reader) (string, error) {
+	limit := p.MaxFileSize()
+	if p.maxDecompressed > 0 && (limit <= 0 || p.maxDecompressed < limit) {
+		limit = p.maxDecompressed
+	}
+	if limit > 0 {
+		content = io.LimitReader(content, limit+1)
+	}
+
+	tmp, err := os.CreateTemp("", "decompressed-*-"+name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer tmp.Close()
+
+	written, err := io.Copy(tmp, content)
+	if err == nil {
+		err = tmp.Close()
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", apperrors.NewProcessError(apperrors.ErrorTypeIO, path, "failed to decompress file", err)
+	}
+	if err := p.CheckFileSize(path, written); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if p.maxDecompressed > 0 && written > p.maxDecompressed {
+		os.Remove(tmp.Name())
+		return "", apperrors.NewProcessError(apperrors.ErrorTypeValidation, path,
+			fmt.Sprintf("decompressed size exceeds %d bytes", p.maxDecompressed), ErrDecompressedTooLarge)
+	}
+	return tmp.Name(), nil
+}
+
+// innerFor picks the processor for the decompressed content, first by the
+// file name without its compression suffix, then by sniffing the content
+func (p *CompressedProcessor) innerFor(name string, sniff []byte) Processor {
+	for _, candidate := range []string{name, "content" + sniffExtension(sniff)} {
+		for _, proc := range p.inner {
+			if proc.CanHandle(candidate) {
+				return proc
+			}
+		}
+	}
+	return nil
+}
+
+// detectCompression returns the format whose magic bytes start header, or nil
+func detectCompression(header []byte) *compression {
+	for i := range compressions {
+		if bytes.HasPrefix(header, compressions[i].magic) {
+			return &compressions[i]
+		}
+	}
+	return nil
+}
+
+// innerName strips the compression suffix from base, if it has one
+func innerName(base string, format *compression) string {
+	lower := strings.ToLower(base)
+	for _, suffix := range format.suffix {
+		if strings.HasSuffix(lower, suffix) && len(base) > len(suffix) {
+			return base[:len(base)-len(suffix)]
+		}
+	}
+	return base
+}
+
+// sniffExtension guesses an extension from the start of decompressed content
+func sniffExtension(sniff []byte) string {
+	trimmed := bytes.TrimLeft(sniff, " \t\r\n\uFEFF")
+	switch {
+	case len(trimmed) == 0:
+		return ".txt"
+	case trimmed[0] == '{' || trimmed[0] == '[':
+		return ".json"
+	case trimmed[0] == '<':
+		return ".xml"
+	}
+	return ".txt"
+}
@@ -1,36 +1,87 @@
 package processor
 
 import (
+	"bufio"
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
 	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
 )
 
 // CSVProcessor implements the Processor interface for CSV files
 type CSVProcessor struct {
 	*models.BaseProcessor
+	hasHeader bool
+
+	// delimiter separates fields; 0 picks it by extension, tab for .tsv
+	// and comma otherwise
+	delimiter rune
+
+	// maxFieldSize caps a single field in bytes; 0 means no cap
+	maxFieldSize   int
+	truncateFields bool
+
+	// redactor redacts the rows passed to ProcessRows callbacks when set
+	redactor *Redactor
 }
 
 // NewCSVProcessor creates a new CSV processor
 func NewCSVProcessor(bufferSize int) *CSVProcessor {
 	return &CSVProcessor{
 		BaseProcessor: models.NewBaseProcessor("csv", bufferSize),
+		hasHeader:     true,
 	}
 }
 
+// SetHasHeader sets whether the first row is a header; it is true by default
+// Without a header every row counts as data, and rows are checked for a
+// consistent field count against the first row instead
+func (p *CSVProcessor) SetHasHeader(hasHeader bool) {
+	p.hasHeader = hasHeader
+}
+
+// SetDelimiter sets the field separator, e.g. ';'; 0 restores picking it
+// by extension
+func (p *CSVProcessor) SetDelimiter(delimiter rune) {
+	p.delimiter = delimiter
+}
+
+// SetMaxFieldSize caps the size in bytes of any single field; 0 removes the cap
+// Oversized fields are cut to size and reported as anomalies when truncate is
+// set, otherwise the file fails with a format error
+// The cap bounds what is kept per record, not what the CSV reader buffers
+// while parsing the record's line
+func (p *CSVProcessor) SetMaxFieldSize(size int, truncate bool) {
+	p.maxFieldSize = size
+	p.truncateFields = truncate
+}
+
+// SetRedactor redacts the named columns of every row ProcessRows passes on;
+// nil passes rows unchanged
+func (p *CSVProcessor) SetRedactor(redactor *Redactor) {
+	p.redactor = redactor
+}
+
 // CanHandle implements the Processor interface
 func (p *CSVProcessor) CanHandle(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	return ext == ".csv" || ext == ".tsv"
 }
 
+// SupportedExtensions implements ExtensionLister
+func (p *CSVProcessor) SupportedExtensions() []string {
+	return []string{".csv", ".tsv"}
+}
+
 // Process implements the Processor interface
 func (p *CSVProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
 	result := models.ProcessResult{
@@ -44,58 +95,256 @@ func (p *CSVProcessor) Process(ctx context.Context, path string) (models.Process
 	// Get file info
 	info, err := os.Stat(path)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to get file info: %w", err)
+		result.Error = models.FileError(path, "get file info", err)
 		return result, result.Error
 	}
 
 	result.Size = info.Size()
 	result.Modified = info.ModTime()
 
-	// Open the file
-	file, err := os.Open(path)
+	// Skip oversized files before reading them
+	if err := p.CheckFileSize(path, info.Size()); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Hash the raw bytes in the same pass when enabled
+	digest, err := newDigest(ctx, p.HashAlgorithm())
 	if err != nil {
-		result.Error = fmt.Errorf("failed to open file: %w", err)
+		result.Error = err
 		return result, result.Error
 	}
-	defer file.Close()
 
-	// Create CSV reader
-	reader := csv.NewReader(file)
+	// Wait for a file slot shared by every processor
+	release, err := acquireFile(ctx)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	defer release()
 
-	// Detect delimiter based on file extension
-	if strings.HasSuffix(strings.ToLower(path), ".tsv") {
-		reader.Comma = '\t'
+	// Open the file and create the CSV reader
+	blank := newBlankDetector()
+	file, reader, hasBOM, err := p.openReader(path, digest, blank)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
 	}
+	defer file.Close()
+	result.HasBOM = hasBOM
 
 	// Process the CSV file
 	start := time.Now()
 
-	// Read header
-	_, err = reader.Read()
+	// Rows with the wrong number of fields are anomalies, not fatal errors
+	reader.FieldsPerRecord = -1
+	// Records are only counted, so one slice can back every row
+	reader.ReuseRecord = true
+
+	// Read the first row; a file with no rows at all is only an error if it has content
+	first, err := reader.Read()
+	if err == io.EOF && blank.Blank() {
+		result.Duration = time.Since(start)
+		result.IsEmpty = true
+		result.Bytes = int(info.Size())
+		result.Hash = digestSum(digest)
+		return result, nil
+	}
 	if err != nil {
-		result.Error = fmt.Errorf("failed to read CSV header: %w", err)
+		result.Error = csvReadError(path, reader, "failed to read CSV header", err)
 		return result, result.Error
 	}
+	if err := p.limitFields(path, &result, reader, first); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	result.HeaderAssumed = p.hasHeader
+	fields := len(first)
 
-	// Count rows and calculate statistics
+	// Count rows and calculate statistics; without a header the first row is data
 	var rows, words int
+	if !p.hasHeader {
+		rows++
+		words += fields
+	}
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			result.Error = fmt.Errorf("failed to read CSV row: %w", err)
+			result.Error = csvReadError(path, reader, "failed to read CSV row", err)
+			return result, result.Error
+		}
+		if err := p.limitFields(path, &result, reader, record); err != nil {
+			result.Error = err
 			return result, result.Error
 		}
 		rows++
 		words += len(record)
+
+		if len(record) != fields {
+			line, _ := reader.FieldPos(0)
+			result.AddAnomaly("field_count",
+				fmt.Sprintf("row has %d fields, %s has %d", len(record), p.firstRowName(), fields), line)
+		}
 	}
 
 	result.Duration = time.Since(start)
-	result.Lines = rows + 1 // Include header row
+	result.IsEmpty = blank.Blank()
+	result.Lines = rows
+	if p.hasHeader {
+		result.Lines++ // Include header row
+	}
+	result.Extra = map[string]int{"data_rows": rows}
 	result.Words = words
 	result.Bytes = int(info.Size())
+	result.Hash = digestSum(digest)
 
 	return result, nil
 }
+
+// ProcessRows streams the data rows of a CSV file to fn, skipping the header
+// unless the processor is configured for header-less files
+// Rows are redacted first when a redactor is set
+// Streaming stops early when fn returns an error or ctx is cancelled,
+// and that error is returned
+func (p *CSVProcessor) ProcessRows(ctx context.Context, path string, fn func(row []string) error) error {
+	release, err := acquireFile(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	file, reader, _, err := p.openReader(path, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Skip header, keeping its names for the redactor
+	var header []string
+	if p.hasHeader {
+		if header, err = reader.Read(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read CSV header: %w", err)
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV row: %w", err)
+		}
+		if err := p.limitFields(path, nil, reader, record); err != nil {
+			return err
+		}
+
+		if err := fn(p.redactor.Row(header, record)); err != nil {
+			return err
+		}
+	}
+}
+
+// csvReadError wraps a read failure in a format error at the line and
+// column the reader stopped at
+func csvReadError(path string, reader *csv.Reader, message string, err error) error {
+	processErr := apperrors.NewProcessError(apperrors.ErrorTypeFormat, path, message, err)
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		processErr.WithPosition(parseErr.Line, parseErr.Column, reader.InputOffset())
+	}
+	return processErr
+}
+
+// limitFields applies the field size cap to record in place
+// Truncations are recorded as anomalies on result when it is non-nil;
+// without truncation an oversized field is a format error
+func (p *CSVProcessor) limitFields(path string, result *models.ProcessResult, reader *csv.Reader, record []string) error {
+	if p.maxFieldSize <= 0 {
+		return nil
+	}
+	for i, field := range record {
+		if len(field) <= p.maxFieldSize {
+			continue
+		}
+		line, column := reader.FieldPos(i)
+		if !p.truncateFields {
+			return apperrors.NewProcessError(apperrors.ErrorTypeFormat, path,
+				fmt.Sprintf("field %d is %d bytes, over the %d byte limit", i+1, len(field), p.maxFieldSize)).
+				WithPosition(line, column, reader.InputOffset())
+		}
+
+		record[i] = truncateField(field, p.maxFieldSize)
+		if result != nil {
+			result.AddAnomaly("field_truncated",
+				fmt.Sprintf("field %d was %d bytes, truncated to %d", i+1, len(field), len(record[i])), line)
+		}
+	}
+	return nil
+}
+
+// truncateField cuts s to at most max bytes without splitting a UTF-8 rune
+func truncateField(s string, max int) string {
+	end := max
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
+// firstRowName describes the row other rows' field counts are compared with
+func (p *CSVProcessor) firstRowName() string {
+	if p.hasHeader {
+		return "header"
+	}
+	return "first row"
+}
+
+// openReader opens path and returns a CSV reader configured for its delimiter
+// A leading UTF-8 BOM is stripped so it can't corrupt the first header field
+// When raw is non-nil it receives the file's bytes as they are read, and when
+// tee is non-nil it receives everything the CSV reader consumes
+// The caller is responsible for closing the returned file
+func (p *CSVProcessor) openReader(path string, raw, tee io.Writer) (*os.File, *csv.Reader, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, false, models.FileError(path, "open file", err)
+	}
+
+	var source io.Reader = file
+	if raw != nil {
+		source = io.TeeReader(file, raw)
+	}
+	content, hasBOM, err := stripBOM(source)
+	if err != nil {
+		file.Close()
+		return nil, nil, false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if tee != nil {
+		content = io.TeeReader(content, tee)
+	}
+
+	// Create CSV reader, reading through a buffer of the processor's size
+	reader := csv.NewReader(bufio.NewReaderSize(content, p.BufferSize()))
+
+	// Detect delimiter based on file extension, unless one is set
+	switch {
+	case p.delimiter != 0:
+		reader.Comma = p.delimiter
+	case strings.HasSuffix(strings.ToLower(path), ".tsv"):
+		reader.Comma = '\t'
+	}
+
+	return file, reader, hasBOM, nil
+}
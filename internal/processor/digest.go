@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"context"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+)
+
+// hashAlgorithmKey is the context key for a per-call hash algorithm
+type hashAlgorithmKey struct{}
+
+// newDigest creates the hash for a processor's configured algorithm, or
+// returns nil when algo is empty and hashing is off
+// An algorithm set on ctx by ProcessAndHash takes precedence
+func newDigest(ctx context.Context, algo string) (hash.Hash, error) {
+	if override, ok := ctx.Value(hashAlgorithmKey{}).(string); ok {
+		algo = override
+	}
+	if algo == "" {
+		return nil, nil
+	}
+	return utils.NewHash(algo)
+}
+
+// ProcessAndHash processes path with p and also returns the file's digest
+// using algo, reading the file only once for processors that support
+// hashing (all built-in ones); for others the file is hashed separately
+// The processor's own hash setting is left unchanged
+func ProcessAndHash(ctx context.Context, p Processor, path, algo string) (models.ProcessResult, string, error) {
+	if _, err := utils.NewHash(algo); err != nil {
+		return models.ProcessResult{}, "", err
+	}
+
+	result, err := p.Process(context.WithValue(ctx, hashAlgorithmKey{}, algo), path)
+	if err != nil {
+		return result, "", err
+	}
+
+	if result.Hash == "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return result, "", models.FileError(path, "open file", err)
+		}
+		defer file.Close()
+		if result.Hash, err = utils.HashReader(file, algo); err != nil {
+			return result, "", err
+		}
+	}
+	return result, result.Hash, nil
+}
+
+// teeDigest wraps r so the raw bytes feed digest as they are read,
+// returning r unchanged when digest is nil
+// Processors read files to EOF on success, so the digest covers the whole file
+func teeDigest(r io.Reader, digest hash.Hash) io.Reader {
+	if digest == nil {
+		return r
+	}
+	return io.TeeReader(r, digest)
+}
+
+// digestSum returns the hex digest, or "" when hashing is off
+func digestSum(digest hash.Hash) string {
+	if digest == nil {
+		return ""
+	}
+	return hex.EncodeToString(digest.Sum(nil))
+}
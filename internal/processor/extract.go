@@ -0,0 +1,29 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+)
+
+// TextSink receives the plain text a processor extracted from the file at
+// path, once the file has been processed successfully
+type TextSink func(path string, text []byte) error
+
+// DirTextSink writes extracted text under dir, mirroring each file's path
+// with ".txt" appended, so data/feed.xml becomes <dir>/data/feed.xml.txt
+// Parent directory references are dropped, so the text stays inside dir
+func DirTextSink(dir string) TextSink {
+	return func(source string, text []byte) error {
+		rel := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(source)), "/")
+		target := filepath.Join(dir, filepath.FromSlash(rel)+".txt")
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create text directory: %w", err)
+		}
+		return utils.WriteFileAtomic(target, text, 0644)
+	}
+}
@@ -0,0 +1,168 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Factory creates a fully configured processor with the given buffer size
+type Factory func(bufferSize int) (Processor, error)
+
+// ExtensionOverride gives files with some extensions their own processor
+// settings, as read from the config file's processing.overrides list
+type ExtensionOverride struct {
+	// Extensions the override applies to, e.g. .csv
+	Extensions []string `mapstructure:"extensions"`
+	// BufferSize replaces the default buffer size when positive
+	BufferSize int `mapstructure:"buffer_size"`
+	// Delimiter is the field separator of CSV files, a single character
+	Delimiter string `mapstructure:"delimiter"`
+	// HasHeader says whether CSV files start with a header row
+	HasHeader *bool `mapstructure:"has_header"`
+}
+
+// Validate checks the override's settings on their own
+func (o ExtensionOverride) Validate() error {
+	if len(o.Extensions) == 0 {
+		return fmt.Errorf("override lists no extensions")
+	}
+	for _, ext := range o.Extensions {
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+			return fmt.Errorf("invalid override extension %q: must start with a dot", ext)
+		}
+	}
+	if o.BufferSize < 0 {
+		return fmt.Errorf("invalid override buffer_size %d: must not be negative", o.BufferSize)
+	}
+	if o.Delimiter != "" {
+		if r, size := utf8.DecodeRuneInString(o.Delimiter); size != len(o.Delimiter) || r == '\n' || r == '\r' || r == '"' || r == utf8.RuneError {
+			return fmt.Errorf("invalid override delimiter %q: must be a single character other than a quote or line break", o.Delimiter)
+		}
+	}
+	return nil
+}
+
+// BuildProcessors creates one processor per factory with bufferSize
+// Each override is applied by building, with the factory of the processor
+// that handles its extensions, another processor limited to those
+// extensions, placed ahead of the default so it claims them first
+func BuildProcessors(bufferSize int, factories []Factory, overrides []ExtensionOverride) ([]Processor, error) {
+	defaults := make([]Processor, len(factories))
+	for i, factory := range factories {
+		proc, err := factory(bufferSize)
+		if err != nil {
+			return nil, err
+		}
+		defaults[i] = proc
+	}
+
+	scopedFor := make([][]Processor, len(factories))
+	for _, override := range overrides {
+		if err := override.Validate(); err != nil {
+			return nil, err
+		}
+
+		// An override may span processors, e.g. .csv and .log
+		extensions := make(map[int][]string)
+		for _, ext := range override.Extensions {
+			ext = strings.ToLower(ext)
+			owner := -1
+			for i, proc := range defaults {
+				if proc.CanHandle("file" + ext) {
+					owner = i
+					break
+				}
+			}
+			if owner < 0 {
+				return nil, fmt.Errorf("invalid override: no processor handles %s files", ext)
+			}
+			extensions[owner] = append(extensions[owner], ext)
+		}
+
+		for i := range factories {
+			if len(extensions[i]) == 0 {
+				continue
+			}
+			size := bufferSize
+			if override.BufferSize > 0 {
+				size = override.BufferSize
+			}
+			proc, err := factories[i](size)
+			if err != nil {
+				return nil, err
+			}
+			if err := override.apply(proc); err != nil {
+				return nil, err
+			}
+			scopedFor[i] = append(scopedFor[i], &scopedProcessor{Processor: proc, extensions: extensions[i]})
+		}
+	}
+
+	var processors []Processor
+	for i, proc := range defaults {
+		processors = append(processors, scopedFor[i]...)
+		processors = append(processors, proc)
+	}
+	return processors, nil
+}
+
+// apply sets the override's processor-specific options on proc
+func (o ExtensionOverride) apply(proc Processor) error {
+	if o.Delimiter != "" {
+		csvProc, ok := proc.(interface{ SetDelimiter(rune) })
+		if !ok {
+			return fmt.Errorf("invalid override for %s: delimiter only applies to CSV files", strings.Join(o.Extensions, ", "))
+		}
+		r, _ := utf8.DecodeRuneInString(o.Delimiter)
+		csvProc.SetDelimiter(r)
+	}
+	if o.HasHeader != nil {
+		csvProc, ok := proc.(interface{ SetHasHeader(bool) })
+		if !ok {
+			return fmt.Errorf("invalid override for %s: has_header only applies to CSV files", strings.Join(o.Extensions, ", "))
+		}
+		csvProc.SetHasHeader(*o.HasHeader)
+	}
+	return nil
+}
+
+// scopedProcessor limits a processor to some of the extensions it handles
+type scopedProcessor struct {
+	Processor
+	extensions []string
+}
+
+// CanHandle implements the Processor interface
+func (p *scopedProcessor) CanHandle(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, scoped := range p.extensions {
+		if ext == scoped {
+			return p.Processor.CanHandle(path)
+		}
+	}
+	return false
+}
+
+// SupportedExtensions implements ExtensionLister with the extensions the
+// processor is limited to
+func (p *scopedProcessor) SupportedExtensions() []string {
+	return append([]string(nil), p.extensions...)
+}
+
+// Name names the wrapped processor, if it has a name
+func (p *scopedProcessor) Name() string {
+	if named, ok := p.Processor.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", p.Processor)
+}
+
+// MaxFileSize reports the wrapped processor's size limit, if it has one
+func (p *scopedProcessor) MaxFileSize() int64 {
+	if limited, ok := p.Processor.(interface{ MaxFileSize() int64 }); ok {
+		return limited.MaxFileSize()
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+func TestBuildProcessorsOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	semicolons := filepath.Join(tmpDir, "data.csv")
+	if err := os.WriteFile(semicolons, []byte("a;b;c\n1;2;3\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	factories := []Factory{
+		func(size int) (Processor, error) { return NewTextProcessor(size), nil },
+		func(size int) (Processor, error) { return NewCSVProcessor(size), nil },
+	}
+	noHeader := false
+	overrides := []ExtensionOverride{
+		{Extensions: []string{".csv"}, Delimiter: ";", HasHeader: &noHeader},
+		{Extensions: []string{".log"}, BufferSize: 8192},
+	}
+
+	processors, err := BuildProcessors(4096, factories, overrides)
+	if err != nil {
+		t.Fatalf("Failed to build processors: %v", err)
+	}
+	if len(processors) != 4 {
+		t.Fatalf("Expected 2 defaults and 2 overrides, got %d processors", len(processors))
+	}
+
+	// The override comes first and claims only its own extensions
+	logProc := processorFor(processors, "app.log")
+	if text, ok := logProc.(*scopedProcessor).Processor.(*TextProcessor); !ok || text.BufferSize() != 8192 {
+		t.Errorf("Expected the .log override with an 8192 byte buffer, got %T", logProc)
+	}
+	if _, ok := processorFor(processors, "notes.txt").(*TextProcessor); !ok {
+		t.Error("Expected .txt files to use the default text processor")
+	}
+	if _, ok := processorFor(processors, "data.tsv").(*CSVProcessor); !ok {
+		t.Error("Expected .tsv files to use the default CSV processor")
+	}
+
+	result, err := processorFor(processors, semicolons).Process(context.Background(), semicolons)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.Words != 6 || result.Extra["data_rows"] != 2 {
+		t.Errorf("Expected 6 fields in 2 data rows, got %d fields and %v", result.Words, result.Extra)
+	}
+
+	// Overrides that don't fit are rejected
+	for _, bad := range []ExtensionOverride{
+		{},
+		{Extensions: []string{"csv"}},
+		{Extensions: []string{".png"}},
+		{Extensions: []string{".txt"}, Delimiter: ";"},
+		{Extensions: []string{".csv"}, Delimiter: ";;"},
+	} {
+		if _, err := BuildProcessors(4096, factories, []ExtensionOverride{bad}); err == nil {
+			t.Errorf("Expected an error for override %+v", bad)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	text := NewTextProcessor(4096)
+	text.AddExtension("dat")
+	factories := []Factory{
+		func(size int) (Processor, error) { return text, nil },
+		func(size int) (Processor, error) { return NewCSVProcessor(size), nil },
+	}
+	overrides := []ExtensionOverride{{Extensions: []string{".TSV"}, Delimiter: ";"}}
+	processors, err := BuildProcessors(4096, factories, overrides)
+	if err != nil {
+		t.Fatalf("Failed to build processors: %v", err)
+	}
+	processors = append(processors, unnamedProcessor{})
+
+	want := []string{
+		"text: .txt .log .md .dat",
+		"csv: .tsv",
+		"csv: .csv .tsv",
+		"processor.unnamedProcessor: ",
+	}
+	infos := Describe(processors)
+	if len(infos) != len(want) {
+		t.Fatalf("Expected %d processors, got %+v", len(want), infos)
+	}
+	for i, info := range infos {
+		if got := info.Name + ": " + strings.Join(info.Extensions, " "); got != want[i] {
+			t.Errorf("Processor %d: expected %q, got %q", i, want[i], got)
+		}
+	}
+}
+
+// unnamedProcessor has neither a name nor a list of extensions, like a
+// minimal plugin
+type unnamedProcessor struct{}
+
+func (unnamedProcessor) CanHandle(path string) bool { return false }
+
+func (unnamedProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
+	return models.ProcessResult{}, nil
+}
@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// hookSymbol is the function a hook plugin must export
+const hookSymbol = "OnResult"
+
+// ResultHook receives each result once its file has been processed
+// Failed files are passed too, with their Error set
+type ResultHook func(result models.ProcessResult) error
+
+// Hooks is a list of result hooks, run in the order they were added
+type Hooks []ResultHook
+
+// Add registers hook to run after the hooks already added
+func (h *Hooks) Add(hook ResultHook) {
+	*h = append(*h, hook)
+}
+
+// Run calls each hook synchronously with result, stopping at the first
+// hook that returns an error
+func (h Hooks) Run(result models.ProcessResult) error {
+	for i, hook := range h {
+		if err := hook(result); err != nil {
+			return fmt.Errorf("hook %d failed for %s: %w", i+1, result.Path, err)
+		}
+	}
+	return nil
+}
+
+// LoadHookPlugin opens a Go plugin (.so) and returns its exported
+// `func OnResult(models.ProcessResult) error` as a hook
+// The same platform and build restrictions as LoadPlugin apply
+func LoadHookPlugin(path string) (ResultHook, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup(hookSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s does not export %s: %w", path, hookSymbol, err)
+	}
+
+	onResult, ok := sym.(func(models.ProcessResult) error)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s: %s has type %T, want func(models.ProcessResult) error", path, hookSymbol, sym)
+	}
+	return onResult, nil
+}
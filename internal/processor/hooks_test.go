@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+func TestHooks(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) ResultHook {
+		return func(result models.ProcessResult) error {
+			calls = append(calls, name+":"+result.Path)
+			return err
+		}
+	}
+
+	var hooks Hooks
+	hooks.Add(record("first", nil))
+	hooks.Add(record("second", nil))
+
+	result := models.ProcessResult{FileInfo: models.FileInfo{Path: "a.txt"}}
+	if err := hooks.Run(result); err != nil {
+		t.Fatalf("Failed to run hooks: %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "first:a.txt" || calls[1] != "second:a.txt" {
+		t.Errorf("Expected hooks in registration order, got %v", calls)
+	}
+
+	// A failing hook stops the ones after it and its error is wrapped
+	boom := errors.New("queue unavailable")
+	calls = nil
+	hooks = Hooks{record("first", boom), record("second", nil)}
+	if err := hooks.Run(result); !errors.Is(err, boom) {
+		t.Errorf("Expected the hook error, got %v", err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("Expected later hooks to be skipped, got %v", calls)
+	}
+
+	// No hooks is a no-op
+	if err := Hooks(nil).Run(result); err != nil {
+		t.Errorf("Expected no error without hooks, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLProcessor implements the Processor interface for HTML files
+// Words and lines are counted on the visible text, leaving out markup and
+// the contents of script and style elements
+type HTMLProcessor struct {
+	*models.BaseProcessor
+	// textSink receives the visible text of each file when set
+	textSink TextSink
+}
+
+// NewHTMLProcessor creates a new HTML processor
+func NewHTMLProcessor(bufferSize int) *HTMLProcessor {
+	return &HTMLProcessor{
+		BaseProcessor: models.NewBaseProcessor("html", bufferSize),
+	}
+}
+
+// CanHandle implements the Processor interface
+func (p *HTMLProcessor) CanHandle(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".html" || ext == ".htm"
+}
+
+// SupportedExtensions implements ExtensionLister
+func (p *HTMLProcessor) SupportedExtensions() []string {
+	return []string{".html", ".htm"}
+}
+
+// SetTextSink passes the visible text of each successfully processed file,
+// one non-blank line per line, to sink; nil stops extracting text
+func (p *HTMLProcessor) SetTextSink(sink TextSink) {
+	p.textSink = sink
+}
+
+// Process implements the Processor interface
+// Malformed markup is read as leniently as browsers do, so it still yields
+// counts. Extra holds the "elements", "links" and "images" counts
+func (p *HTMLProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
+	result := models.ProcessResult{
+		FileInfo: models.FileInfo{
+			Path:      path,
+			Type:      "html",
+			Processed: time.Now(),
+		},
+	}
+
+	// Get file info
+	info, err := os.Stat(path)
+	if err != nil {
+		result.Error = models.FileError(path, "get file info", err)
+		return result, result.Error
+	}
+
+	result.Size = info.Size()
+	result.Modified = info.ModTime()
+
+	// Skip oversized files before reading them
+	if err := p.CheckFileSize(path, info.Size()); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Hash the raw bytes in the same pass when enabled
+	digest, err := newDigest(ctx, p.HashAlgorithm())
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Wait for a file slot shared by every processor
+	release, err := acquireFile(ctx)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	defer release()
+
+	// Open the file
+	file, err := os.Open(path)
+	if err != nil {
+		result.Error = models.FileError(path, "open file", err)
+		return result, result.Error
+	}
+	defer file.Close()
+
+	start := time.Now()
+	blank := newBlankDetector()
+	tokenizer := html.NewTokenizer(io.TeeReader(teeDigest(file, digest), blank))
+
+	var text *bytes.Buffer
+	if p.textSink != nil {
+		text = &bytes.Buffer{}
+	}
+
+	// hidden counts the open script and style elements, whose text isn't shown
+	var elements, links, images, lines, words, hidden int
+tokens:
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				result.Error = fmt.Errorf("failed to read HTML: %w", err)
+				return result, result.Error
+			}
+			break tokens
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			elements++
+			switch token.DataAtom {
+			case atom.A:
+				if hasAttr(token, "href") {
+					links++
+				}
+			case atom.Img:
+				images++
+			case atom.Script, atom.Style:
+				if token.Type == html.StartTagToken {
+					hidden++
+				}
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if a := atom.Lookup(name); (a == atom.Script || a == atom.Style) && hidden > 0 {
+				hidden--
+			}
+
+		case html.TextToken:
+			if hidden > 0 {
+				continue
+			}
+			// Each tag ends a word, and each non-blank line of text counts once
+			for _, line := range bytes.Split(tokenizer.Text(), []byte{'\n'}) {
+				fields := bytes.Fields(line)
+				if len(fields) == 0 {
+					continue
+				}
+				lines++
+				words += len(fields)
+				if text != nil {
+					text.Write(bytes.Join(fields, []byte{' '}))
+					text.WriteByte('\n')
+				}
+			}
+		}
+	}
+
+	result.Duration = time.Since(start)
+	result.IsEmpty = blank.Blank()
+	result.Lines = lines
+	result.Words = words
+	result.Bytes = int(info.Size())
+	result.Extra = map[string]int{"elements": elements, "links": links, "images": images}
+	result.Hash = digestSum(digest)
+
+	if text != nil {
+		if err := p.textSink(path, text.Bytes()); err != nil {
+			result.Error = fmt.Errorf("failed to write extracted text: %w", err)
+			return result, result.Error
+		}
+	}
+	return result, nil
+}
+
+// hasAttr reports whether token has a non-empty attribute called key
+func hasAttr(token html.Token, key string) bool {
+	for _, attr := range token.Attr {
+		if attr.Key == key && strings.TrimSpace(attr.Val) != "" {
+			return true
+		}
+	}
+	return false
+}
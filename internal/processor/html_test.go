@@ -0,0 +1,57 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHTMLProcessor(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "page.html")
+
+	// Unclosed tags and a stray end tag are read leniently
+	content := `<!DOCTYPE html>
+<html><head><title>Home page</title>
+<style>body { color: red }</style>
+<script>var hidden = "not counted";</script></head>
+<body>
+<p>Welcome to <a href="/about">our site</a>
+<p>See <a href="#">this</a> and <a name="anchor">that</a></span>
+<img src="logo.png"><br/>
+</body></html>`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewHTMLProcessor(4096)
+	if !processor.CanHandle(testFile) || !processor.CanHandle("INDEX.HTM") || processor.CanHandle("page.xml") {
+		t.Error("Processor should handle .html and .htm files only")
+	}
+
+	outDir := filepath.Join(tmpDir, "text")
+	processor.SetTextSink(DirTextSink(outDir))
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	// Visible text: "Home page", "Welcome to", "our site", "See", "this",
+	// "and", "that"
+	if result.Lines != 7 || result.Words != 10 {
+		t.Errorf("Expected 7 lines and 10 words, got %d and %d", result.Lines, result.Words)
+	}
+	// html, head, title, style, script, body, p, a, p, a, a, img, br
+	if result.Extra["elements"] != 13 || result.Extra["links"] != 2 || result.Extra["images"] != 1 {
+		t.Errorf("Expected 13 elements, 2 links and 1 image, got %v", result.Extra)
+	}
+
+	text, err := os.ReadFile(filepath.Join(outDir, testFile+".txt"))
+	if err != nil {
+		t.Fatalf("Failed to read extracted text: %v", err)
+	}
+	if want := "Home page\nWelcome to\nour site\nSee\nthis\nand\nthat\n"; string(text) != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+}
@@ -0,0 +1,174 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Indentation styles reported by the indentation checker
+const (
+	indentTabs   = "tabs"
+	indentSpaces = "spaces"
+)
+
+// indentChecker is an io.Writer that classifies the leading whitespace of
+// each line as tabs, spaces, or mixed (a tab after a space)
+// Lines that are blank or unindented are not counted; a line starting with
+// tabs and aligned with spaces after them counts as tab-indented
+type indentChecker struct {
+	line int
+	// State of the current line's leading whitespace
+	done   bool
+	spaces int
+	mixed  bool
+	first  byte
+
+	tabLines   int
+	spaceLines int
+	mixedLines int
+	// firstTab, firstSpace and firstMixed are the first line of each kind
+	firstTab   int
+	firstSpace int
+	firstMixed int
+
+	// prevSpaces is the indentation of the last space-indented or
+	// unindented line; steps counts the indentation increases between them
+	prevSpaces int
+	steps      map[int]int
+}
+
+// newIndentChecker creates a checker positioned at the first line
+func newIndentChecker() *indentChecker {
+	return &indentChecker{line: 1, steps: make(map[int]int)}
+}
+
+// Write implements io.Writer, tracking leading whitespace across chunk boundaries
+func (c *indentChecker) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if c.done {
+			i := bytes.IndexByte(p, '\n')
+			if i < 0 {
+				break
+			}
+			c.endLine()
+			p = p[i+1:]
+			continue
+		}
+
+		switch b := p[0]; b {
+		case '\n':
+			c.endLine()
+		case '\r':
+		case ' ', '\t':
+			if c.first == 0 {
+				c.first = b
+			}
+			if b == ' ' {
+				c.spaces++
+			} else if c.spaces > 0 {
+				c.mixed = true
+			}
+		default:
+			c.classify()
+		}
+		p = p[1:]
+	}
+	return n, nil
+}
+
+// classify records the indentation of the current line once its first
+// non-whitespace byte is seen
+func (c *indentChecker) classify() {
+	c.done = true
+	switch {
+	case c.first == 0:
+		c.stepTo(0)
+	case c.mixed:
+		c.mixedLines++
+		if c.firstMixed == 0 {
+			c.firstMixed = c.line
+		}
+	case c.first == '\t':
+		c.tabLines++
+		if c.firstTab == 0 {
+			c.firstTab = c.line
+		}
+	default:
+		c.spaceLines++
+		if c.firstSpace == 0 {
+			c.firstSpace = c.line
+		}
+		c.stepTo(c.spaces)
+	}
+}
+
+// stepTo records an increase in space indentation up to spaces
+func (c *indentChecker) stepTo(spaces int) {
+	if step := spaces - c.prevSpaces; step > 0 {
+		c.steps[step]++
+	}
+	c.prevSpaces = spaces
+}
+
+// endLine starts a new line
+func (c *indentChecker) endLine() {
+	c.line++
+	c.done, c.spaces, c.mixed, c.first = false, 0, false, 0
+}
+
+// Style returns the dominant indentation style, "tabs" or "spaces" (which
+// wins ties), or "" when no line is indented
+func (c *indentChecker) Style() string {
+	switch {
+	case c.tabLines > c.spaceLines:
+		return indentTabs
+	case c.spaceLines > 0:
+		return indentSpaces
+	}
+	return ""
+}
+
+// Width returns the most common increase in space indentation, preferring
+// the smaller on ties, or 0 unless the dominant style is spaces
+func (c *indentChecker) Width() int {
+	if c.Style() != indentSpaces {
+		return 0
+	}
+	width, count := 0, 0
+	for step, n := range c.steps {
+		if n > count || (n == count && step < width) {
+			width, count = step, n
+		}
+	}
+	return width
+}
+
+// Mismatched returns how many lines don't use the dominant style, counting
+// every mixed line, and the first of them (0 if there are none)
+func (c *indentChecker) Mismatched() (count, first int) {
+	count, first = c.mixedLines, c.firstMixed
+	other, otherFirst := c.tabLines, c.firstTab
+	if c.Style() == indentTabs {
+		other, otherFirst = c.spaceLines, c.firstSpace
+	}
+	if other > 0 {
+		count += other
+		if first == 0 || otherFirst < first {
+			first = otherFirst
+		}
+	}
+	return count, first
+}
+
+// Detail describes mismatched lines for an anomaly
+func (c *indentChecker) Detail(mismatched int) string {
+	switch style := c.Style(); {
+	case style == "":
+		return fmt.Sprintf("%d lines mix tabs and spaces", mismatched)
+	case c.Width() > 0:
+		return fmt.Sprintf("%d lines not indented with the dominant %d %s", mismatched, c.Width(), style)
+	default:
+		return fmt.Sprintf("%d lines not indented with the dominant %s", mismatched, style)
+	}
+}
@@ -3,18 +3,28 @@ package processor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"time"
 
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
 	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
 // JSONProcessor implements the Processor interface for JSON files
 type JSONProcessor struct {
 	*models.BaseProcessor
+	// Optional schema every decoded document is validated against
+	schema *jsonschema.Schema
+	// Record the shape of the documents, and optionally their top-level keys
+	structure    bool
+	topLevelKeys bool
+	// redactor redacts named keys in documents and schema anomalies
+	redactor *Redactor
 }
 
 // NewJSONProcessor creates a new JSON processor
@@ -24,11 +34,29 @@ func NewJSONProcessor(bufferSize int) *JSONProcessor {
 	}
 }
 
+// NewJSONSchemaProcessor creates a JSON processor that validates every
+// document against the JSON Schema stored at schemaPath
+func NewJSONSchemaProcessor(bufferSize int, schemaPath string) (*JSONProcessor, error) {
+	schema, err := jsonschema.Compile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile JSON schema: %w", err)
+	}
+
+	p := NewJSONProcessor(bufferSize)
+	p.schema = schema
+	return p, nil
+}
+
 // CanHandle implements the Processor interface
 func (p *JSONProcessor) CanHandle(path string) bool {
 	return strings.HasSuffix(strings.ToLower(path), ".json")
 }
 
+// SupportedExtensions implements ExtensionLister
+func (p *JSONProcessor) SupportedExtensions() []string {
+	return []string{".json"}
+}
+
 // Process implements the Processor interface
 func (p *JSONProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
 	result := models.ProcessResult{
@@ -42,42 +70,201 @@ func (p *JSONProcessor) Process(ctx context.Context, path string) (models.Proces
 	// Get file info
 	info, err := os.Stat(path)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to get file info: %w", err)
+		result.Error = models.FileError(path, "get file info", err)
 		return result, result.Error
 	}
 
 	result.Size = info.Size()
 	result.Modified = info.ModTime()
 
+	// Skip oversized files before reading them
+	if err := p.CheckFileSize(path, info.Size()); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Hash the raw bytes in the same pass when enabled
+	digest, err := newDigest(ctx, p.HashAlgorithm())
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Wait for a file slot shared by every processor
+	release, err := acquireFile(ctx)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	defer release()
+
 	// Open the file
 	file, err := os.Open(path)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to open file: %w", err)
+		result.Error = models.FileError(path, "open file", err)
 		return result, result.Error
 	}
 	defer file.Close()
 
 	// Process the JSON file
 	start := time.Now()
-	decoder := json.NewDecoder(file)
+	blank := newBlankDetector()
+	decoder := json.NewDecoder(io.TeeReader(teeDigest(file, digest), blank))
+
+	var shape *structureCounter
+	if p.structure || p.topLevelKeys {
+		shape = newStructureCounter(p.topLevelKeys)
+	}
 
 	// Count objects and calculate size
 	var count int
 	for {
-		var json interface{}
-		if err := decoder.Decode(&json); err != nil {
+		doc, err := p.decode(decoder, shape)
+		if err != nil {
 			if err == io.EOF {
 				break
 			}
-			result.Error = fmt.Errorf("failed to decode JSON: %w", err)
+			result.Error = jsonDecodeError(path, decoder, err)
 			return result, result.Error
 		}
 		count++
+
+		if p.schema != nil {
+			p.validate(&result, doc, count)
+		}
+	}
+	if shape != nil {
+		result.JSONStructure = shape.result()
 	}
 
 	result.Duration = time.Since(start)
+	result.IsEmpty = blank.Blank()
 	result.Lines = count // In JSON, each object is counted as a line
 	result.Bytes = int(info.Size())
+	result.Hash = digestSum(digest)
 
 	return result, nil
 }
+
+// ProcessDocuments streams each top-level JSON document in the file to fn,
+// redacted first when a redactor is set
+// Streaming stops early when fn returns an error or ctx is cancelled,
+// and that error is returned
+func (p *JSONProcessor) ProcessDocuments(ctx context.Context, path string, fn func(doc interface{}) error) error {
+	release, err := acquireFile(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return models.FileError(path, "open file", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return jsonDecodeError(path, decoder, err)
+		}
+
+		if err := fn(p.redactor.Value(doc)); err != nil {
+			return err
+		}
+	}
+}
+
+// decode reads the next document, counting its structure when shape is set
+// The document is only unmarshaled when a schema needs it
+func (p *JSONProcessor) decode(decoder *json.Decoder, shape *structureCounter) (interface{}, error) {
+	var doc interface{}
+	if shape == nil {
+		err := decoder.Decode(&doc)
+		return doc, err
+	}
+
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if err := shape.add(raw); err != nil {
+		return nil, err
+	}
+	if p.schema != nil {
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// jsonDecodeError wraps a decoding failure in a format error locating it
+// Syntax errors point at the offending byte; other failures, such as a
+// truncated file, at the start of the document being decoded
+func jsonDecodeError(path string, decoder *json.Decoder, err error) error {
+	offset := decoder.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) && syntaxErr.Offset > 0 {
+		offset = syntaxErr.Offset - 1
+	}
+	line, column := lineColumn(path, offset)
+	return apperrors.NewProcessError(apperrors.ErrorTypeFormat, path, "failed to decode JSON", err).
+		WithPosition(line, column, offset)
+}
+
+// SetStructureStats enables recording the maximum depth, the number of
+// objects, arrays and scalars, and the keys at each depth
+// It is off by default because it walks every document a second time
+func (p *JSONProcessor) SetStructureStats(enabled bool) {
+	p.structure = enabled
+}
+
+// SetTopLevelKeys enables collecting the keys of top-level objects, up to
+// models.MaxTopLevelKeys of them, along with the structure stats
+func (p *JSONProcessor) SetTopLevelKeys(enabled bool) {
+	p.topLevelKeys = enabled
+}
+
+// SetRedactor redacts the named keys of the documents ProcessDocuments
+// passes on and keeps their values out of schema anomalies; nil leaves
+// everything in full
+func (p *JSONProcessor) SetRedactor(redactor *Redactor) {
+	p.redactor = redactor
+}
+
+// validate checks a decoded document against the schema and records the outcome
+func (p *JSONProcessor) validate(result *models.ProcessResult, doc interface{}, record int) {
+	if err := p.schema.Validate(doc); err != nil {
+		result.InvalidRecords++
+		result.AddAnomaly("schema", fmt.Sprintf("record %d: %s", record, p.schemaDetail(err)), 0)
+		return
+	}
+	result.ValidRecords++
+}
+
+// schemaDetail describes a validation failure, leaving out the message when
+// it concerns a redacted key, since messages such as pattern mismatches
+// quote the offending value
+func (p *JSONProcessor) schemaDetail(err error) string {
+	var validation *jsonschema.ValidationError
+	if p.redactor == nil || !errors.As(err, &validation) {
+		return err.Error()
+	}
+	leaf := validation
+	for len(leaf.Causes) > 0 {
+		leaf = leaf.Causes[0]
+	}
+	if !p.redactor.Covers(leaf.InstanceLocation) {
+		return err.Error()
+	}
+	return fmt.Sprintf("jsonschema: '%s' does not validate with %s (value redacted)", leaf.InstanceLocation, leaf.KeywordLocation)
+}
@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// structureCounter walks the token stream of JSON documents, counting
+// containers, scalars and keys by depth
+type structureCounter struct {
+	stats models.JSONStructure
+	// keys collects top-level keys when non-nil
+	keys map[string]struct{}
+}
+
+// jsonFrame is an open object or array; key is set when an object expects a
+// key next
+type jsonFrame struct {
+	object bool
+	key    bool
+}
+
+// newStructureCounter creates a counter, collecting top-level keys if asked
+func newStructureCounter(topLevelKeys bool) *structureCounter {
+	c := &structureCounter{stats: models.JSONStructure{KeyDepths: make(map[int]int)}}
+	if topLevelKeys {
+		c.keys = make(map[string]struct{})
+	}
+	return c
+}
+
+// add counts one encoded document
+func (c *structureCounter) add(doc []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+
+	var stack []jsonFrame
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		depth := len(stack)
+		delim, isDelim := token.(json.Delim)
+		if depth > 0 && stack[depth-1].key && !isDelim {
+			// Object keys are strings, so any non-delimiter here is a key
+			c.stats.KeyDepths[depth]++
+			if depth == 1 && c.keys != nil && len(c.keys) < models.MaxTopLevelKeys {
+				c.keys[token.(string)] = struct{}{}
+			}
+			stack[depth-1].key = false
+			continue
+		}
+
+		switch {
+		case delim == '{' || delim == '[':
+			if delim == '{' {
+				c.stats.Objects++
+			} else {
+				c.stats.Arrays++
+			}
+			stack = append(stack, jsonFrame{object: delim == '{', key: delim == '{'})
+			if len(stack) > c.stats.MaxDepth {
+				c.stats.MaxDepth = len(stack)
+			}
+			continue
+		case isDelim:
+			stack = stack[:depth-1]
+		default:
+			c.stats.Scalars++
+		}
+
+		// A completed value is followed by a key in its enclosing object
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].key = true
+		}
+	}
+}
+
+// result returns the counts, with the top-level keys sorted
+func (c *structureCounter) result() *models.JSONStructure {
+	stats := c.stats
+	if len(stats.KeyDepths) == 0 {
+		stats.KeyDepths = nil
+	}
+	for key := range c.keys {
+		stats.TopLevelKeys = append(stats.TopLevelKeys, key)
+	}
+	sort.Strings(stats.TopLevelKeys)
+	return &stats
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// fileSlots caps how many files all processors read at once, whichever
+// subsystem (CLI, API, watcher) called them
+// A nil channel means unlimited
+var fileSlots struct {
+	mu    sync.RWMutex
+	slots chan struct{}
+}
+
+// SetMaxConcurrency limits the number of files read concurrently across every
+// processor in the process; n <= 0 removes the limit
+// Reads already in progress keep the slot they hold under the old limit
+func SetMaxConcurrency(n int) {
+	fileSlots.mu.Lock()
+	defer fileSlots.mu.Unlock()
+	if n <= 0 {
+		fileSlots.slots = nil
+		return
+	}
+	fileSlots.slots = make(chan struct{}, n)
+}
+
+// acquireFile waits for a file slot and returns the function that frees it
+// Processors call it before opening a file and release once reading is done
+func acquireFile(ctx context.Context) (release func(), err error) {
+	fileSlots.mu.RLock()
+	slots := fileSlots.slots
+	fileSlots.mu.RUnlock()
+
+	if slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to wait for a file slot: %w", ctx.Err())
+	}
+}
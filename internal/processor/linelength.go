@@ -0,0 +1,71 @@
+package processor
+
+// lineLengthCounter is an io.Writer that measures the length of each line in
+// runes, tracking the longest line and how many exceed a threshold
+// Runes are counted by skipping UTF-8 continuation bytes, so a rune split
+// across chunks is counted once without buffering
+type lineLengthCounter struct {
+	threshold int
+	current   int
+	pending   bool
+	// lastCR marks a '\r' just before the current position, which is not
+	// counted as part of the line if a '\n' follows
+	lastCR bool
+
+	lines int
+	total int
+	max   int
+	over  int
+}
+
+// newLineLengthCounter creates a counter for lines longer than threshold runes
+func newLineLengthCounter(threshold int) *lineLengthCounter {
+	return &lineLengthCounter{threshold: threshold}
+}
+
+// Write implements io.Writer, accumulating line lengths across chunk boundaries
+func (c *lineLengthCounter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		switch {
+		case b == '\n':
+			if c.lastCR {
+				c.current--
+			}
+			c.endLine()
+		case b&0xC0 != 0x80:
+			c.current++
+			c.pending = true
+		}
+		c.lastCR = b == '\r'
+	}
+	return len(p), nil
+}
+
+// Flush counts a final line that has no trailing newline
+func (c *lineLengthCounter) Flush() {
+	if c.pending {
+		c.endLine()
+	}
+}
+
+// endLine records the current line's length and starts a new line
+func (c *lineLengthCounter) endLine() {
+	c.lines++
+	c.total += c.current
+	if c.current > c.max {
+		c.max = c.current
+	}
+	if c.current > c.threshold {
+		c.over++
+	}
+	c.current = 0
+	c.pending = false
+}
+
+// Average returns the mean line length in runes
+func (c *lineLengthCounter) Average() float64 {
+	if c.lines == 0 {
+		return 0
+	}
+	return float64(c.total) / float64(c.lines)
+}
@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"bytes"
+	"hash"
+	"hash/fnv"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/cache"
+)
+
+// maxDedupLines caps how many distinct lines a lineDeduper remembers
+const maxDedupLines = 1 << 18
+
+// lineDeduper is an io.Writer that splits the stream into lines and counts
+// unique versus repeated lines
+// Only a 64-bit hash of each of the most recently seen distinct lines is
+// kept, so memory is bounded whatever the file's size
+// Past that many distinct lines the counts are approximate: a line last
+// seen before the oldest remembered one counts as unique again
+type lineDeduper struct {
+	hash      hash.Hash64
+	seen      *cache.LRU[uint64, struct{}]
+	pending   bool
+	unique    int
+	duplicate int
+}
+
+// newLineDeduper creates an empty line deduplicator remembering up to
+// maxLines distinct lines
+func newLineDeduper(maxLines int) *lineDeduper {
+	return &lineDeduper{
+		hash: fnv.New64a(),
+		seen: cache.New[uint64, struct{}](maxLines),
+	}
+}
+
+// Write implements io.Writer, hashing lines across chunk boundaries
+func (d *lineDeduper) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			d.hash.Write(p)
+			d.pending = true
+			break
+		}
+		d.hash.Write(p[:i])
+		d.endLine()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Flush counts a final line that has no trailing newline
+func (d *lineDeduper) Flush() {
+	if d.pending {
+		d.endLine()
+	}
+}
+
+// endLine records the hash of the current line and starts a new one
+func (d *lineDeduper) endLine() {
+	sum := d.hash.Sum64()
+	d.hash.Reset()
+	d.pending = false
+
+	// Get marks a repeated line as recently used, so lines that keep
+	// recurring stay remembered
+	if _, ok := d.seen.Get(sum); ok {
+		d.duplicate++
+		return
+	}
+	d.seen.Add(sum, struct{}{})
+	d.unique++
+}
@@ -0,0 +1,25 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loggerKey is the context key for a request- or run-scoped logger
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger, which processors use for
+// their log lines, e.g. to tag them with the API request they serve
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFrom returns the logger carried by ctx, or the global logrus logger
+// when there is none
+func LoggerFrom(ctx context.Context) *logrus.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok && logger != nil {
+		return logger
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
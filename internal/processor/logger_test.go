@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLoggerFrom(t *testing.T) {
+	// Without a logger the global one is used
+	if got := LoggerFrom(context.Background()); got.Logger != logrus.StandardLogger() {
+		t.Errorf("Expected the standard logger, got %v", got.Logger)
+	}
+
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetLevel(logrus.DebugLevel)
+	ctx := WithLogger(context.Background(), logger.WithField("request_id", "abc123"))
+
+	// A processor's log lines carry the context logger's fields
+	badFile := filepath.Join(t.TempDir(), "bad.txt")
+	if err := os.WriteFile(badFile, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := SafeProcess(ctx, &panickyProcessor{TextProcessor: NewTextProcessor(4096)}, badFile); err == nil {
+		t.Fatal("Expected an error from the panicking processor")
+	}
+	if line := out.String(); !strings.Contains(line, "request_id=abc123") || !strings.Contains(line, "panicked") {
+		t.Errorf("Expected a tagged panic log line, got %q", line)
+	}
+}
@@ -0,0 +1,143 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// NDJSONProcessor implements the Processor interface for newline-delimited
+// JSON, where every non-blank line must be one complete JSON value
+// Malformed lines are reported as anomalies instead of failing the file
+type NDJSONProcessor struct {
+	*models.BaseProcessor
+}
+
+// NewNDJSONProcessor creates a new NDJSON processor
+func NewNDJSONProcessor(bufferSize int) *NDJSONProcessor {
+	return &NDJSONProcessor{
+		BaseProcessor: models.NewBaseProcessor("ndjson", bufferSize),
+	}
+}
+
+// CanHandle implements the Processor interface
+func (p *NDJSONProcessor) CanHandle(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".ndjson" || ext == ".jsonl"
+}
+
+// SupportedExtensions implements ExtensionLister
+func (p *NDJSONProcessor) SupportedExtensions() []string {
+	return []string{".ndjson", ".jsonl"}
+}
+
+// Process implements the Processor interface
+func (p *NDJSONProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
+	result := models.ProcessResult{
+		FileInfo: models.FileInfo{
+			Path:      path,
+			Type:      "ndjson",
+			Processed: time.Now(),
+		},
+	}
+
+	// Get file info
+	info, err := os.Stat(path)
+	if err != nil {
+		result.Error = models.FileError(path, "get file info", err)
+		return result, result.Error
+	}
+
+	result.Size = info.Size()
+	result.Modified = info.ModTime()
+
+	// Skip oversized files before reading them
+	if err := p.CheckFileSize(path, info.Size()); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Hash the raw bytes in the same pass when enabled
+	digest, err := newDigest(ctx, p.HashAlgorithm())
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Wait for a file slot shared by every processor
+	release, err := acquireFile(ctx)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	defer release()
+
+	// Open the file
+	file, err := os.Open(path)
+	if err != nil {
+		result.Error = models.FileError(path, "open file", err)
+		return result, result.Error
+	}
+	defer file.Close()
+
+	start := time.Now()
+	content, hasBOM, err := stripBOM(teeDigest(file, digest))
+	if err != nil {
+		result.Error = fmt.Errorf("failed to read file: %w", err)
+		return result, result.Error
+	}
+	result.HasBOM = hasBOM
+
+	// ReadBytes grows past the buffer, so long records are read whole
+	reader := bufio.NewReaderSize(content, p.BufferSize())
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			result.Lines++
+			p.checkRecord(&result, line)
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			result.Error = fmt.Errorf("failed to read file: %w", readErr)
+			return result, result.Error
+		}
+	}
+
+	result.Duration = time.Since(start)
+	result.IsEmpty = result.ValidRecords+result.InvalidRecords == 0
+	result.Bytes = int(info.Size())
+	result.Hash = digestSum(digest)
+
+	return result, nil
+}
+
+// checkRecord validates the record on the current line; blank lines are skipped
+func (p *NDJSONProcessor) checkRecord(result *models.ProcessResult, line []byte) {
+	record := bytes.TrimSpace(line)
+	if len(record) == 0 {
+		return
+	}
+	if json.Valid(record) {
+		result.ValidRecords++
+		return
+	}
+
+	result.InvalidRecords++
+	detail := "line is not a single complete JSON value"
+	var probe interface{}
+	if err := json.Unmarshal(record, &probe); err != nil {
+		detail = err.Error()
+	}
+	result.AddAnomaly("malformed_record", detail, result.Lines)
+}
@@ -0,0 +1,15 @@
+package processor
+
+// optionalFactories holds constructors for processors that are compiled in
+// only with a build tag, so their dependencies stay out of the core build
+var optionalFactories []func(bufferSize int) Processor
+
+// OptionalProcessors creates the processors enabled by build tags
+// (for example `-tags xlsx` for spreadsheets)
+func OptionalProcessors(bufferSize int) []Processor {
+	processors := make([]Processor, 0, len(optionalFactories))
+	for _, factory := range optionalFactories {
+		processors = append(processors, factory(bufferSize))
+	}
+	return processors
+}
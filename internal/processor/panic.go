@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// PanicError is the cause of the error returned when a processor panics
+type PanicError struct {
+	// Value is what the processor panicked with
+	Value interface{}
+	// Stack is the panicking goroutine's stack trace
+	Stack []byte
+}
+
+// Error implements the error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// SafeProcess calls p.Process, turning a panic into a format ProcessError
+// whose cause is a *PanicError, so one malformed file can't crash the run
+// The stack is logged at debug level to the context's logger
+func SafeProcess(ctx context.Context, p Processor, path string) (result models.ProcessResult, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			stack := debug.Stack()
+			LoggerFrom(ctx).Debugf("Processor panicked on %s: %v\n%s", path, v, stack)
+			result = models.ProcessResult{FileInfo: models.FileInfo{Path: path}}
+			result.Error = apperrors.NewProcessError(apperrors.ErrorTypeFormat, path, "processor panicked",
+				&PanicError{Value: v, Stack: stack})
+			err = result.Error
+		}
+	}()
+	return p.Process(ctx, path)
+}
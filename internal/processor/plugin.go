@@ -0,0 +1,39 @@
+package processor
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// pluginSymbol is the constructor a processor plugin must export
+const pluginSymbol = "NewProcessor"
+
+// LoadPlugin opens a Go plugin (.so) and returns the processor built by its
+// exported `func NewProcessor() models.Processor`
+// Go plugins only work on Linux, FreeBSD and macOS, with cgo enabled, and the
+// plugin must be built with the same Go version and module versions as the
+// analyzer; elsewhere LoadPlugin returns an error
+func LoadPlugin(path string) (models.Processor, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup(pluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s does not export %s: %w", path, pluginSymbol, err)
+	}
+
+	newProcessor, ok := sym.(func() models.Processor)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s: %s has type %T, want func() models.Processor", path, pluginSymbol, sym)
+	}
+
+	proc := newProcessor()
+	if proc == nil {
+		return nil, fmt.Errorf("plugin %s: %s returned a nil processor", path, pluginSymbol)
+	}
+	return proc, nil
+}
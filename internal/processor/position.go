@@ -0,0 +1,32 @@
+package processor
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// lineColumn returns the line and column, both counted from 1, of the byte
+// at offset in the file at path, or zeros when the file can't be read
+// It reads the file again, so it is only meant for reporting errors
+func lineColumn(path string, offset int64) (line, column int) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	line, column = 1, 1
+	reader := bufio.NewReader(io.LimitReader(file, offset))
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return line, column
+		}
+		if b == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+}
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
 )
@@ -13,3 +14,34 @@ type Processor interface {
 	// Process handles the file and returns processing results
 	Process(ctx context.Context, path string) (models.ProcessResult, error)
 }
+
+// ExtensionLister is implemented by processors that can list the file
+// extensions they handle, lower case with the leading dot
+type ExtensionLister interface {
+	SupportedExtensions() []string
+}
+
+// ProcessorInfo describes a processor for users
+type ProcessorInfo struct {
+	Name string
+	// Extensions is empty when the processor doesn't list them, as plugins
+	// needn't; CanHandle still decides which files it takes
+	Extensions []string
+}
+
+// Describe returns the name and extensions of each processor, in the
+// order they are tried
+func Describe(processors []Processor) []ProcessorInfo {
+	infos := make([]ProcessorInfo, 0, len(processors))
+	for _, p := range processors {
+		info := ProcessorInfo{Name: fmt.Sprintf("%T", p)}
+		if named, ok := p.(interface{ Name() string }); ok {
+			info.Name = named.Name()
+		}
+		if lister, ok := p.(ExtensionLister); ok {
+			info.Extensions = lister.SupportedExtensions()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
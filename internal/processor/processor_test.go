@@ -1,14 +1,27 @@
 package processor
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
 	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
 )
 
 func TestJSONProcessor(t *testing.T) {
@@ -50,6 +63,52 @@ func TestJSONProcessor(t *testing.T) {
 	}
 }
 
+func TestJSONStructureStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "data.json")
+
+	// Two documents: an object nesting an array of objects, and a scalar
+	content := `{"id": 1, "tags": ["a", "b"], "items": [{"sku": "x", "dims": {"w": 2}}], "empty": {}}` + "\n" + `"note"`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewJSONProcessor(4096)
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.JSONStructure != nil {
+		t.Errorf("Expected no structure stats by default, got %+v", result.JSONStructure)
+	}
+
+	processor.SetStructureStats(true)
+	processor.SetTopLevelKeys(true)
+	result, err = processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.Lines != 2 {
+		t.Errorf("Expected 2 documents, got %d", result.Lines)
+	}
+
+	got := result.JSONStructure
+	if got == nil {
+		t.Fatal("Expected structure stats")
+	}
+	if got.MaxDepth != 4 || got.Objects != 4 || got.Arrays != 2 || got.Scalars != 6 {
+		t.Errorf("Expected depth 4, 4 objects, 2 arrays and 6 scalars, got %+v", got)
+	}
+	wantDepths := map[int]int{1: 4, 3: 2, 4: 1}
+	if !reflect.DeepEqual(got.KeyDepths, wantDepths) {
+		t.Errorf("Expected key depths %v, got %v", wantDepths, got.KeyDepths)
+	}
+	wantKeys := []string{"empty", "id", "items", "tags"}
+	if !reflect.DeepEqual(got.TopLevelKeys, wantKeys) {
+		t.Errorf("Expected top-level keys %v, got %v", wantKeys, got.TopLevelKeys)
+	}
+}
+
 func TestCSVProcessor(t *testing.T) {
 	// Create test CSV file
 	testData := [][]string{
@@ -134,3 +193,874 @@ func TestHashAndBase64(t *testing.T) {
 		t.Error("Decoded content should match original content")
 	}
 }
+
+func TestTextProcessor(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.txt")
+
+	content := "hello world\nfoo bar\tbaz\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewTextProcessor(4096)
+	if !processor.CanHandle(testFile) {
+		t.Error("Processor should handle text files")
+	}
+
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	// Counts produced by the shared BaseProcessor.ReadLines loop
+	if result.Words != 5 {
+		t.Errorf("Expected 5 words, got %d", result.Words)
+	}
+	if result.Lines != 3 {
+		t.Errorf("Expected 3 lines, got %d", result.Lines)
+	}
+	if result.Bytes != len(content) {
+		t.Errorf("Expected %d bytes, got %d", len(content), result.Bytes)
+	}
+}
+
+func TestCSVProcessRows(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.csv")
+
+	content := "name,value\ntest1,1\ntest2,2\ntest3,3\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewCSVProcessor(4096)
+
+	// All data rows are streamed, the header is skipped
+	var names []string
+	err := processor.ProcessRows(context.Background(), testFile, func(row []string) error {
+		names = append(names, row[0])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream rows: %v", err)
+	}
+	if len(names) != 3 || names[0] != "test1" {
+		t.Errorf("Expected rows test1..test3, got %v", names)
+	}
+
+	// An error from the callback stops streaming and is returned
+	stop := errors.New("stop")
+	var seen int
+	err = processor.ProcessRows(context.Background(), testFile, func(row []string) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Errorf("Expected early stop after 1 row, got err=%v after %d rows", err, seen)
+	}
+
+	// A cancelled context stops before any row is delivered
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = processor.ProcessRows(ctx, testFile, func(row []string) error {
+		t.Error("Callback should not run after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCSVProcessorBOM(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "bom.csv")
+
+	content := "\xEF\xBB\xBFname,value\ntest1,1\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewCSVProcessor(4096)
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if !result.HasBOM {
+		t.Error("Expected BOM to be detected")
+	}
+
+	// The header must parse without the BOM glued to the first field
+	file, reader, _, err := processor.openReader(testFile, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	defer file.Close()
+
+	header, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Failed to read header: %v", err)
+	}
+	if header[0] != "name" {
+		t.Errorf("Expected first header field %q, got %q", "name", header[0])
+	}
+}
+
+func TestCSVProcessorHeaderless(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "export.csv")
+
+	content := "1,alpha\n2,beta\n3,gamma,extra\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewCSVProcessor(4096)
+
+	// By default the first row is taken as the header
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if !result.HeaderAssumed || result.Extra["data_rows"] != 2 {
+		t.Errorf("Expected a header and 2 data rows, got header=%v rows=%d", result.HeaderAssumed, result.Extra["data_rows"])
+	}
+
+	processor.SetHasHeader(false)
+	result, err = processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.HeaderAssumed {
+		t.Error("Expected no header to be assumed")
+	}
+	if result.Extra["data_rows"] != 3 || result.Lines != 3 {
+		t.Errorf("Expected 3 data rows and 3 lines, got %d and %d", result.Extra["data_rows"], result.Lines)
+	}
+	if result.Words != 7 {
+		t.Errorf("Expected 7 fields counted, got %d", result.Words)
+	}
+	if len(result.Anomalies) != 1 || result.Anomalies[0].Line != 3 {
+		t.Errorf("Expected one field count anomaly on line 3, got %+v", result.Anomalies)
+	}
+
+	// ProcessRows streams the first row too
+	if err := os.WriteFile(testFile, []byte("1,alpha\n2,beta\n3,gamma\n"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite test file: %v", err)
+	}
+	var ids []string
+	err = processor.ProcessRows(context.Background(), testFile, func(row []string) error {
+		ids = append(ids, row[0])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream rows: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != "1" {
+		t.Errorf("Expected rows 1..3, got %v", ids)
+	}
+}
+
+func TestCSVProcessorMaxFieldSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "blobs.csv")
+
+	blob := strings.Repeat("QUJD", 5000)
+	content := "id,data\n1,short\n2," + blob + "\n3," + strings.Repeat("x", 15) + "éyyyy\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewCSVProcessor(4096)
+
+	// Without truncation an oversized field fails the file as a format error
+	processor.SetMaxFieldSize(16, false)
+	_, err := processor.Process(context.Background(), testFile)
+	if !apperrors.IsErrorType(err, apperrors.ErrorTypeFormat) {
+		t.Fatalf("Expected a format error, got %v", err)
+	}
+
+	// With truncation the file succeeds and each cut is an anomaly
+	processor.SetMaxFieldSize(16, true)
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.Extra["data_rows"] != 3 {
+		t.Errorf("Expected 3 data rows, got %d", result.Extra["data_rows"])
+	}
+	if len(result.Anomalies) != 2 || result.Anomalies[0].Kind != "field_truncated" || result.Anomalies[0].Line != 3 {
+		t.Errorf("Expected truncations on lines 3 and 4, got %+v", result.Anomalies)
+	}
+
+	// Streamed rows carry the truncated values, cut on a rune boundary
+	var data []string
+	err = processor.ProcessRows(context.Background(), testFile, func(row []string) error {
+		data = append(data, row[1])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream rows: %v", err)
+	}
+	if len(data) != 3 || len(data[1]) != 16 || data[2] != strings.Repeat("x", 15) {
+		t.Errorf("Expected fields truncated to at most 16 bytes, got %q", data)
+	}
+}
+
+func TestTextProcessorDuplicateLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "retries.log")
+
+	content := "connect failed\nretrying\nconnect failed\nretrying\nconnect failed\ndone"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// A tiny buffer forces lines to span read chunks
+	processor := NewTextProcessor(4)
+	processor.SetDetectDuplicates(true)
+
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	if result.UniqueLines != 3 {
+		t.Errorf("Expected 3 unique lines, got %d", result.UniqueLines)
+	}
+	if result.DuplicateLines != 3 {
+		t.Errorf("Expected 3 duplicate lines, got %d", result.DuplicateLines)
+	}
+}
+
+func TestLineDeduperBounded(t *testing.T) {
+	// With room for two lines, "a" is forgotten once "c" is seen, so its
+	// repeat counts as unique while the repeat of "c" is still caught
+	dedup := newLineDeduper(2)
+	dedup.Write([]byte("a\nb\nc\na\nc\n"))
+	dedup.Flush()
+
+	if dedup.unique != 4 || dedup.duplicate != 1 {
+		t.Errorf("Expected 4 unique and 1 duplicate lines, got %d and %d", dedup.unique, dedup.duplicate)
+	}
+}
+
+func TestTextProcessorWordPattern(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "notes.txt")
+
+	content := "error: disk-full (code=28)\r\n-- -- --\nkey=value,other=thing"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewTextProcessor(4)
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.Words != 7 {
+		t.Errorf("Expected 7 whitespace-separated words by default, got %d", result.Words)
+	}
+
+	// Only alphanumeric tokens count, across the tiny buffer's chunk boundaries
+	processor.SetWordPattern(regexp.MustCompile("[[:alnum:]]+"))
+	result, err = processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.Words != 9 {
+		t.Errorf("Expected 9 alphanumeric words, got %d", result.Words)
+	}
+
+	processor.SetWordPattern(nil)
+	if result, _ := processor.Process(context.Background(), testFile); result.Words != 7 {
+		t.Errorf("Expected the default word count to be restored, got %d", result.Words)
+	}
+}
+
+func TestTextProcessorLineLength(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "wide.txt")
+
+	// The long line is 5000 runes but 10000 bytes, so it spans several 4096-byte
+	// reads and some two-byte runes are split across them
+	long := strings.Repeat("é", 5000)
+	content := "short\r\n" + long + "\n" + strings.Repeat("x", 120) + "\nlast"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewTextProcessor(4096)
+	processor.SetLineLengthThreshold(120)
+
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	if result.MaxLineLength != 5000 {
+		t.Errorf("Expected max line length 5000, got %d", result.MaxLineLength)
+	}
+	if result.LinesOverThreshold != 1 {
+		t.Errorf("Expected 1 line over the threshold, got %d", result.LinesOverThreshold)
+	}
+	if want := float64(5+5000+120+4) / 4; result.AvgLineLength != want {
+		t.Errorf("Expected average line length %.2f, got %.2f", want, result.AvgLineLength)
+	}
+}
+
+func TestTextProcessorIndentation(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "code.txt")
+
+	// Mostly four-space indentation, one tab-indented line and one line with
+	// a tab after spaces; blank lines and tab alignment don't count
+	content := "func main() {\n    if x {\n        y()\n\n    }\n\tz()\n  \tw()\n    a\tb\n}\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewTextProcessor(4096)
+	processor.SetCheckIndentation(true)
+
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.IndentStyle != "spaces" || result.IndentWidth != 4 {
+		t.Errorf("Expected 4-space indentation, got %q width %d", result.IndentStyle, result.IndentWidth)
+	}
+	if result.MismatchedIndentLines != 2 {
+		t.Errorf("Expected 2 mismatched lines, got %d", result.MismatchedIndentLines)
+	}
+	if len(result.Anomalies) != 1 || result.Anomalies[0].Kind != "mixed_indentation" || result.Anomalies[0].Line != 6 {
+		t.Errorf("Expected a mixed_indentation anomaly at line 6, got %+v", result.Anomalies)
+	}
+
+	// Consistent tab indentation is not an anomaly
+	if err := os.WriteFile(testFile, []byte("a\n\tb\n\t\tc  d\n\t  aligned\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	result, err = processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.IndentStyle != "tabs" || result.IndentWidth != 0 || result.MismatchedIndentLines != 0 || result.AnomalyCount != 0 {
+		t.Errorf("Expected clean tab indentation, got %+v", result)
+	}
+}
+
+func TestTextProcessorWCCompatible(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Expected counts are GNU wc -l -w -c output with LC_ALL=C
+	tests := []struct {
+		name                string
+		content             string
+		lines, words, bytes int
+	}{
+		{"no trailing newline", "hello world", 0, 2, 11},
+		{"trailing newline", "hello world\n", 1, 2, 12},
+		{"trailing whitespace", "one two  \nthree\t\n\n", 3, 3, 18},
+		{"control whitespace", "a\rb\vc\fd\n", 1, 4, 8},
+		{"byte order mark", "\xef\xbb\xbfhi\n", 1, 1, 6},
+		{"blank lines", "  \n \n", 2, 0, 5},
+		{"empty", "", 0, 0, 0},
+	}
+
+	// A tiny buffer makes words span read chunks
+	processor := NewTextProcessor(4)
+	processor.SetWCCompatible(true)
+	for i, tt := range tests {
+		testFile := filepath.Join(tmpDir, fmt.Sprintf("wc%d.txt", i))
+		if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		result, err := processor.Process(context.Background(), testFile)
+		if err != nil {
+			t.Fatalf("Failed to process %s: %v", tt.name, err)
+		}
+		if result.Lines != tt.lines || result.Words != tt.words || result.Bytes != tt.bytes {
+			t.Errorf("%s: expected %d %d %d, got %d %d %d", tt.name,
+				tt.lines, tt.words, tt.bytes, result.Lines, result.Words, result.Bytes)
+		}
+	}
+}
+
+func TestJSONSchemaProcessor(t *testing.T) {
+	tmpDir := t.TempDir()
+	schemaFile := filepath.Join(tmpDir, "schema.json")
+	testFile := filepath.Join(tmpDir, "records.json")
+
+	schema := `{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`
+	if err := os.WriteFile(schemaFile, []byte(schema), 0644); err != nil {
+		t.Fatalf("Failed to create schema file: %v", err)
+	}
+
+	records := "{\"name\": \"a\"}\n{\"value\": 1}\n{\"name\": \"b\"}\n"
+	if err := os.WriteFile(testFile, []byte(records), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor, err := NewJSONSchemaProcessor(4096, schemaFile)
+	if err != nil {
+		t.Fatalf("Failed to create schema processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	if result.ValidRecords != 2 || result.InvalidRecords != 1 {
+		t.Errorf("Expected 2 valid and 1 invalid records, got %d and %d",
+			result.ValidRecords, result.InvalidRecords)
+	}
+	if len(result.Anomalies) != 1 || result.Anomalies[0].Kind != "schema" {
+		t.Errorf("Expected one schema anomaly, got %+v", result.Anomalies)
+	}
+}
+
+func TestMaxFileSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "big.json")
+
+	if err := os.WriteFile(testFile, []byte(`{"payload": "0123456789"}`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewJSONProcessor(4096)
+	processor.SetMaxFileSize(10)
+
+	_, err := processor.Process(context.Background(), testFile)
+	if !errors.Is(err, models.ErrFileTooLarge) {
+		t.Fatalf("Expected ErrFileTooLarge, got %v", err)
+	}
+	if !apperrors.IsErrorType(err, apperrors.ErrorTypeValidation) {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+
+	// Zero restores unlimited processing
+	processor.SetMaxFileSize(0)
+	if _, err := processor.Process(context.Background(), testFile); err != nil {
+		t.Errorf("Expected file to be processed without a limit, got %v", err)
+	}
+}
+
+func TestEmptyFileDetection(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content []byte
+		empty   bool
+	}{
+		{"zero.txt", nil, true},
+		{"spaces.txt", []byte(" \t\r\n\n  "), true},
+		{"content.txt", []byte("  x  "), false},
+		{"utf16-blank.txt", []byte{0xFF, 0xFE, 0x20, 0x00, 0x0A, 0x00}, true},
+		{"utf16-text.txt", []byte{0xFF, 0xFE, 0x68, 0x00, 0x69, 0x00}, false},
+		{"nbsp.txt", []byte("\u00a0\n"), true},
+		{"blank.json", []byte("\n  \n"), true},
+		{"doc.json", []byte(`{"a": 1}`), false},
+		{"blank.csv", []byte("\r\n\r\n"), true},
+		{"rows.csv", []byte("a,b\n1,2\n"), false},
+	}
+
+	processors := []Processor{NewTextProcessor(4096), NewJSONProcessor(4096), NewCSVProcessor(4096)}
+	for _, tt := range tests {
+		path := filepath.Join(tmpDir, tt.name)
+		if err := os.WriteFile(path, tt.content, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		for _, p := range processors {
+			if !p.CanHandle(path) {
+				continue
+			}
+			result, err := p.Process(context.Background(), path)
+			if err != nil {
+				t.Fatalf("Failed to process %s: %v", tt.name, err)
+			}
+			if result.IsEmpty != tt.empty {
+				t.Errorf("%s: expected IsEmpty=%v, got %v", tt.name, tt.empty, result.IsEmpty)
+			}
+		}
+	}
+}
+
+func TestLoadPluginErrors(t *testing.T) {
+	if _, err := LoadPlugin(filepath.Join(t.TempDir(), "missing.so")); err == nil {
+		t.Error("Expected an error for a missing plugin")
+	}
+
+	// A file that isn't a shared object must be rejected, not crash
+	notPlugin := filepath.Join(t.TempDir(), "fake.so")
+	if err := os.WriteFile(notPlugin, []byte("not a plugin"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := LoadPlugin(notPlugin); err == nil {
+		t.Error("Expected an error for an invalid plugin")
+	}
+}
+
+func TestProcessorAnomalies(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	csvFile := filepath.Join(tmpDir, "ragged.csv")
+	if err := os.WriteFile(csvFile, []byte("a,b,c\n1,2,3\n4,5\n6,7,8,9\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	result, err := NewCSVProcessor(4096).Process(context.Background(), csvFile)
+	if err != nil {
+		t.Fatalf("Ragged rows should not fail the file: %v", err)
+	}
+	if result.AnomalyCount != 2 || result.Anomalies[0].Kind != "field_count" || result.Anomalies[0].Line != 3 {
+		t.Errorf("Expected field_count anomalies starting at line 3, got %+v", result.Anomalies)
+	}
+
+	textFile := filepath.Join(tmpDir, "latin1.txt")
+	if err := os.WriteFile(textFile, []byte("fine\ncaf\xe9\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	result, err = NewTextProcessor(4096).Process(context.Background(), textFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if len(result.Anomalies) != 1 || result.Anomalies[0].Kind != "encoding" || result.Anomalies[0].Line != 2 {
+		t.Errorf("Expected an encoding anomaly on line 2, got %+v", result.Anomalies)
+	}
+
+	// Anomalies past the cap are counted but not kept
+	var capped models.ProcessResult
+	for i := 0; i < models.MaxAnomalies+5; i++ {
+		capped.AddAnomaly("test", "detail", i)
+	}
+	if len(capped.Anomalies) != models.MaxAnomalies || capped.AnomalyCount != models.MaxAnomalies+5 {
+		t.Errorf("Expected %d kept of %d, got %d of %d", models.MaxAnomalies, models.MaxAnomalies+5,
+			len(capped.Anomalies), capped.AnomalyCount)
+	}
+}
+
+func TestVanishedFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "gone")
+	processors := []models.Processor{
+		NewTextProcessor(4096),
+		NewJSONProcessor(4096),
+		NewCSVProcessor(4096),
+	}
+
+	for _, p := range processors {
+		_, err := p.Process(context.Background(), missing)
+		if !errors.Is(err, models.ErrFileVanished) {
+			t.Errorf("Expected ErrFileVanished from %T, got %v", p, err)
+		}
+		if !apperrors.IsErrorType(err, apperrors.ErrorTypeIO) {
+			t.Errorf("Expected an IO error from %T, got %v", p, err)
+		}
+	}
+}
+
+func TestFormatErrorPosition(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		p       Processor
+		line    int
+		column  int
+	}{
+		{"bad.json", "{\"a\": 1}\n{\"b\":\n  x}", NewJSONProcessor(4096), 3, 3},
+		{"bad.csv", "a,b\n1,2\n3,\"x\"y\n", NewCSVProcessor(4096), 3, 5},
+		{"bad.xml", "<a>\n  <b></c>\n</a>", NewXMLProcessor(4096), 2, 10},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		_, err := tt.p.Process(context.Background(), path)
+		var processErr *apperrors.ProcessError
+		if !errors.As(err, &processErr) || processErr.Type != apperrors.ErrorTypeFormat {
+			t.Errorf("%s: expected a format ProcessError, got %v", tt.name, err)
+			continue
+		}
+		if processErr.Line != tt.line || processErr.Column != tt.column {
+			t.Errorf("%s: expected line %d, column %d, got line %d, column %d",
+				tt.name, tt.line, tt.column, processErr.Line, processErr.Column)
+		}
+		if !strings.Contains(err.Error(), fmt.Sprintf("at line %d, column %d", tt.line, tt.column)) {
+			t.Errorf("%s: expected the position in the message, got %q", tt.name, err)
+		}
+	}
+}
+
+func TestCompressedProcessor(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// A gzipped text file with a misleading extension
+	var gz bytes.Buffer
+	gzw := gzip.NewWriter(&gz)
+	gzw.Write([]byte(strings.Repeat("one two three\n", 100)))
+	gzw.Close()
+	textFile := filepath.Join(tmpDir, "notes.txt")
+	if err := os.WriteFile(textFile, gz.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// An xz-compressed JSON document with no extension at all
+	var xzBuf bytes.Buffer
+	xzw, err := xz.NewWriter(&xzBuf)
+	if err != nil {
+		t.Fatalf("Failed to create xz writer: %v", err)
+	}
+	xzw.Write([]byte(`[{"id": 1}, {"id": 2}]`))
+	xzw.Close()
+	jsonFile := filepath.Join(tmpDir, "export")
+	if err := os.WriteFile(jsonFile, xzBuf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// A zstd-compressed log and a snappy-framed JSON export
+	zstdWriter, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create zstd writer: %v", err)
+	}
+	zstdFile := filepath.Join(tmpDir, "app.log.zst")
+	if err := os.WriteFile(zstdFile, zstdWriter.EncodeAll([]byte(strings.Repeat("line of log\n", 1000)), nil), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	var sz bytes.Buffer
+	szw := s2.NewWriter(&sz, s2.WriterSnappyCompat())
+	szw.Write([]byte(`{"id": 1}`))
+	szw.Close()
+	snappyFile := filepath.Join(tmpDir, "export.json.sz")
+	if err := os.WriteFile(snappyFile, sz.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	plainFile := filepath.Join(tmpDir, "plain.txt")
+	if err := os.WriteFile(plainFile, []byte("not compressed"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewCompressedProcessor(4096, NewTextProcessor(4096), NewJSONProcessor(4096))
+	if processor.CanHandle(plainFile) {
+		t.Error("Expected an uncompressed file to be left to other processors")
+	}
+
+	for _, tt := range []struct {
+		path, wantType, wantCompression string
+	}{
+		{textFile, "text", "gzip"},
+		{jsonFile, "json", "xz"},
+		{zstdFile, "text", "zstd"},
+		{snappyFile, "json", "snappy"},
+	} {
+		if !processor.CanHandle(tt.path) {
+			t.Errorf("Expected %s to be detected as compressed", tt.path)
+			continue
+		}
+		result, err := processor.Process(context.Background(), tt.path)
+		if err != nil {
+			t.Fatalf("Failed to process %s: %v", tt.path, err)
+		}
+		if result.Type != tt.wantType || result.Compression != tt.wantCompression || result.Path != tt.path {
+			t.Errorf("Expected %s %s for %s, got %+v", tt.wantCompression, tt.wantType, tt.path, result)
+		}
+	}
+
+	// The size limit applies to the decompressed content too
+	processor.SetMaxFileSize(int64(gz.Len()) + 100)
+	if _, err := processor.Process(context.Background(), textFile); !errors.Is(err, models.ErrFileTooLarge) {
+		t.Errorf("Expected ErrFileTooLarge for decompressed content, got %v", err)
+	}
+	if _, err := processor.Process(context.Background(), zstdFile); !errors.Is(err, models.ErrFileTooLarge) {
+		t.Errorf("Expected ErrFileTooLarge for decompressed zstd content, got %v", err)
+	}
+}
+
+func TestCompressedProcessorDecompressedLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Keep the temporary decompressed files where the test can see them
+	t.Setenv("TMPDIR", t.TempDir())
+
+	// 4 MiB of zeros compress to a few hundred bytes
+	zstdWriter, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create zstd writer: %v", err)
+	}
+	bomb := filepath.Join(tmpDir, "zeros.txt.zst")
+	if err := os.WriteFile(bomb, zstdWriter.EncodeAll(make([]byte, 4<<20), nil), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// The cap applies without any max file size
+	processor := NewCompressedProcessor(4096, NewTextProcessor(4096))
+	processor.SetMaxDecompressedSize(1 << 20)
+	if _, err := processor.Process(context.Background(), bomb); !errors.Is(err, ErrDecompressedTooLarge) {
+		t.Errorf("Expected ErrDecompressedTooLarge, got %v", err)
+	}
+	if leftover, _ := os.ReadDir(os.TempDir()); len(leftover) != 0 {
+		t.Errorf("Expected the partial decompressed file to be removed, found %d files", len(leftover))
+	}
+
+	// 0 removes the cap
+	processor.SetMaxDecompressedSize(0)
+	result, err := processor.Process(context.Background(), bomb)
+	if err != nil {
+		t.Fatalf("Failed to process without a decompressed size limit: %v", err)
+	}
+	if result.Bytes != 4<<20 {
+		t.Errorf("Expected %d decompressed bytes, got %d", 4<<20, result.Bytes)
+	}
+}
+
+func TestSetMaxConcurrency(t *testing.T) {
+	SetMaxConcurrency(1)
+	t.Cleanup(func() { SetMaxConcurrency(0) })
+
+	testFile := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(testFile, []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	processor := NewTextProcessor(4096)
+
+	// With the only slot taken, Process waits until its context gives up
+	release, err := acquireFile(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire slot: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := processor.Process(ctx, testFile); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected to time out waiting for a slot, got %v", err)
+	}
+
+	release()
+	if _, err := processor.Process(context.Background(), testFile); err != nil {
+		t.Errorf("Expected processing once the slot is free, got %v", err)
+	}
+}
+
+func TestProcessorHash(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"notes.txt": "\xEF\xBB\xBFhello\nworld\n",
+		"data.json": `{"a": 1} {"b": 2}`,
+		"rows.csv":  "\xEF\xBB\xBFname,age\nalice,30\n",
+		"doc.xml":   "<root><item>x</item></root>",
+	}
+
+	text := NewTextProcessor(4)
+	jsonProc := NewJSONProcessor(4096)
+	csvProc := NewCSVProcessor(4096)
+	xmlProc := NewXMLProcessor(4096)
+	processors := []interface {
+		Processor
+		SetHashAlgorithm(string)
+	}{text, jsonProc, csvProc, xmlProc}
+
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		for _, algo := range []string{"", "md5", "sha256"} {
+			for _, p := range processors {
+				if !p.CanHandle(path) {
+					continue
+				}
+				p.SetHashAlgorithm(algo)
+				result, err := p.Process(context.Background(), path)
+				if err != nil {
+					t.Fatalf("Failed to process %s: %v", name, err)
+				}
+
+				want := ""
+				if algo != "" {
+					want, _ = utils.HashReader(strings.NewReader(content), algo)
+				}
+				if result.Hash != want {
+					t.Errorf("%s with %q: expected hash %q, got %q", name, algo, want, result.Hash)
+				}
+			}
+		}
+	}
+
+	text.SetHashAlgorithm("crc32")
+	if _, err := text.Process(context.Background(), filepath.Join(tmpDir, "notes.txt")); err == nil {
+		t.Error("Expected an unsupported hash algorithm to fail")
+	}
+}
+
+// plainProcessor handles any file without supporting hashing
+type plainProcessor struct{}
+
+func (plainProcessor) CanHandle(path string) bool { return true }
+
+func (plainProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
+	return models.ProcessResult{FileInfo: models.FileInfo{Path: path}}, nil
+}
+
+func TestProcessAndHash(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(testFile, []byte("one pass\nis enough\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	want, _ := utils.HashFile(testFile)
+
+	processor := NewTextProcessor(4096)
+	for _, p := range []Processor{processor, plainProcessor{}} {
+		result, hash, err := ProcessAndHash(context.Background(), p, testFile, "sha256")
+		if err != nil {
+			t.Fatalf("Failed to process and hash with %T: %v", p, err)
+		}
+		if hash != want || result.Hash != want {
+			t.Errorf("%T: expected hash %s, got %s (result %s)", p, want, hash, result.Hash)
+		}
+	}
+
+	// The per-call algorithm doesn't change the processor's own setting
+	if result, _ := processor.Process(context.Background(), testFile); result.Hash != "" {
+		t.Errorf("Expected no hash from plain Process, got %s", result.Hash)
+	}
+
+	if _, _, err := ProcessAndHash(context.Background(), processor, testFile, "none"); err == nil {
+		t.Error("Expected an unsupported algorithm to fail")
+	}
+}
+
+func TestNDJSONProcessor(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "events.ndjson")
+
+	// Line 3 is truncated and line 5 holds two values; neither aborts the file
+	content := "{\"id\": 1}\n\n{\"id\": 2,\n{\"id\": 3}\n{\"id\": 4} {\"id\": 5}\r\n" + `"` + strings.Repeat("x", 10000) + `"`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	processor := NewNDJSONProcessor(4096)
+	if !processor.CanHandle("a.jsonl") || processor.CanHandle("a.json") {
+		t.Error("Expected .ndjson and .jsonl to be handled, but not .json")
+	}
+
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	if result.ValidRecords != 3 || result.InvalidRecords != 2 {
+		t.Errorf("Expected 3 valid and 2 invalid records, got %d and %d", result.ValidRecords, result.InvalidRecords)
+	}
+	if result.Lines != 6 {
+		t.Errorf("Expected 6 lines, got %d", result.Lines)
+	}
+	if len(result.Anomalies) != 2 || result.Anomalies[0].Line != 3 || result.Anomalies[1].Line != 5 {
+		t.Errorf("Expected anomalies on lines 3 and 5, got %+v", result.Anomalies)
+	}
+}
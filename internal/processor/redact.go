@@ -0,0 +1,165 @@
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redaction methods
+const (
+	// RedactHash replaces a value with a short SHA-256 digest, so equal
+	// values stay recognisably equal
+	RedactHash = "hash"
+	// RedactMask replaces every character of a value with '*'
+	RedactMask = "mask"
+	// RedactDrop removes the field altogether
+	RedactDrop = "drop"
+)
+
+// RedactRule names a CSV column or JSON object key and how its values are redacted
+type RedactRule struct {
+	Field  string `mapstructure:"field"`
+	Method string `mapstructure:"method"`
+}
+
+// ParseRedactRule parses a "field=method" rule, e.g. email=hash
+func ParseRedactRule(s string) (RedactRule, error) {
+	field, method, ok := strings.Cut(s, "=")
+	if !ok {
+		return RedactRule{}, fmt.Errorf("%q is not field=method", s)
+	}
+	return RedactRule{Field: field, Method: method}, nil
+}
+
+// Redactor applies redaction rules to the values of named fields before they
+// are surfaced to row callbacks or in anomalies, so sensitive values never
+// reach a report
+// Field names are matched exactly against CSV header names, or 1-based
+// column numbers in files without a header, and against JSON object keys at
+// any depth. A nil *Redactor leaves everything as is
+type Redactor struct {
+	methods map[string]string
+}
+
+// NewRedactor creates a redactor from rules; it returns nil when there are none
+func NewRedactor(rules []RedactRule) (*Redactor, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{methods: make(map[string]string, len(rules))}
+	for _, rule := range rules {
+		if rule.Field == "" {
+			return nil, fmt.Errorf("invalid redaction: field name is empty")
+		}
+		switch rule.Method {
+		case RedactHash, RedactMask, RedactDrop:
+		default:
+			return nil, fmt.Errorf("invalid redaction method %q for %s: must be hash, mask or drop", rule.Method, rule.Field)
+		}
+		r.methods[rule.Field] = rule.Method
+	}
+	return r, nil
+}
+
+// Row returns a redacted copy of a CSV row whose columns are named by
+// header, or numbered from 1 when header is nil; row itself is not modified
+func (r *Redactor) Row(header, row []string) []string {
+	if r == nil {
+		return row
+	}
+	redacted := make([]string, 0, len(row))
+	for i, value := range row {
+		name := strconv.Itoa(i + 1)
+		if header != nil {
+			if i >= len(header) {
+				redacted = append(redacted, value)
+				continue
+			}
+			name = header[i]
+		}
+		switch r.methods[name] {
+		case RedactDrop:
+			continue
+		case RedactHash:
+			value = redactHash(value)
+		case RedactMask:
+			value = redactMask(value)
+		}
+		redacted = append(redacted, value)
+	}
+	return redacted
+}
+
+// Value returns a redacted copy of a decoded JSON document; doc itself is
+// not modified
+func (r *Redactor) Value(doc interface{}) interface{} {
+	if r == nil {
+		return doc
+	}
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			switch r.methods[key] {
+			case RedactDrop:
+				continue
+			case RedactHash:
+				value = redactHash(jsonText(value))
+			case RedactMask:
+				value = redactMask(jsonText(value))
+			default:
+				value = r.Value(value)
+			}
+			redacted[key] = value
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, value := range v {
+			redacted[i] = r.Value(value)
+		}
+		return redacted
+	}
+	return doc
+}
+
+// Covers reports whether the JSON pointer, e.g. /user/email, passes through
+// a redacted key, so messages about the value there would reveal it
+func (r *Redactor) Covers(pointer string) bool {
+	if r == nil || pointer == "" {
+		return false
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		if _, ok := r.methods[token]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHash replaces s with the first 16 hex digits of its SHA-256 digest
+func redactHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// redactMask replaces every rune of s with '*'
+func redactMask(s string) string {
+	return strings.Repeat("*", utf8.RuneCountInString(s))
+}
+
+// jsonText is the text of a JSON value that hashing and masking apply to:
+// strings as they are, anything else as encoded JSON
+func jsonText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	text, _ := json.Marshal(value)
+	return string(text)
+}
@@ -0,0 +1,132 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	redactor, err := NewRedactor([]RedactRule{
+		{Field: "email", Method: RedactHash},
+		{Field: "ssn", Method: RedactMask},
+		{Field: "notes", Method: RedactDrop},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create redactor: %v", err)
+	}
+
+	header := []string{"name", "email", "ssn", "notes"}
+	row := []string{"ann", "ann@example.com", "123-45", "private"}
+	got := redactor.Row(header, row)
+	want := []string{"ann", redactHash("ann@example.com"), "******"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if row[1] != "ann@example.com" {
+		t.Error("Row should not modify its input")
+	}
+
+	// Nested keys are redacted at any depth, other values are kept
+	doc := map[string]interface{}{
+		"id":    1.0,
+		"users": []interface{}{map[string]interface{}{"email": "bob@example.com", "ssn": 12.0, "notes": "x"}},
+	}
+	redacted := redactor.Value(doc).(map[string]interface{})
+	user := redacted["users"].([]interface{})[0].(map[string]interface{})
+	if user["email"] != redactHash("bob@example.com") || user["ssn"] != "**" || len(user) != 2 {
+		t.Errorf("Expected the user to be redacted, got %v", user)
+	}
+	if redacted["id"] != 1.0 {
+		t.Errorf("Expected id to be kept, got %v", redacted["id"])
+	}
+
+	if !redactor.Covers("/users/0/email") || redactor.Covers("/users/0/name") {
+		t.Error("Expected Covers to match pointers through redacted keys only")
+	}
+
+	// A nil redactor leaves values alone
+	var none *Redactor
+	if got := none.Row(header, row); !reflect.DeepEqual(got, row) {
+		t.Errorf("Expected the row unchanged, got %v", got)
+	}
+
+	for _, rules := range [][]RedactRule{{{Field: "email", Method: "shred"}}, {{Method: RedactHash}}} {
+		if _, err := NewRedactor(rules); err == nil {
+			t.Errorf("Expected an error for %+v", rules)
+		}
+	}
+	if _, err := ParseRedactRule("email"); err == nil {
+		t.Error("Expected an error for a rule without a method")
+	}
+}
+
+func TestRedactedCallbacksAndAnomalies(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvFile := filepath.Join(tmpDir, "people.csv")
+	jsonFile := filepath.Join(tmpDir, "people.json")
+	schemaFile := filepath.Join(tmpDir, "schema.json")
+
+	files := map[string]string{
+		csvFile:    "name,email\nann,ann@example.com\n",
+		jsonFile:   `{"name": "bob", "email": "not-an-address"}`,
+		schemaFile: `{"properties": {"email": {"type": "string", "pattern": "@"}}}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	redactor, err := NewRedactor([]RedactRule{{Field: "email", Method: RedactMask}})
+	if err != nil {
+		t.Fatalf("Failed to create redactor: %v", err)
+	}
+
+	csvProcessor := NewCSVProcessor(4096)
+	csvProcessor.SetRedactor(redactor)
+	var rows [][]string
+	err = csvProcessor.ProcessRows(context.Background(), csvFile, func(row []string) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream rows: %v", err)
+	}
+	if len(rows) != 1 || rows[0][1] != strings.Repeat("*", len("ann@example.com")) {
+		t.Errorf("Expected the email column masked, got %v", rows)
+	}
+
+	jsonProcessor, err := NewJSONSchemaProcessor(4096, schemaFile)
+	if err != nil {
+		t.Fatalf("Failed to create schema processor: %v", err)
+	}
+	jsonProcessor.SetRedactor(redactor)
+
+	var docs []interface{}
+	err = jsonProcessor.ProcessDocuments(context.Background(), jsonFile, func(doc interface{}) error {
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream documents: %v", err)
+	}
+	if len(docs) != 1 || docs[0].(map[string]interface{})["email"] != "**************" {
+		t.Errorf("Expected the email key masked, got %v", docs)
+	}
+
+	// The pattern mismatch would otherwise quote the value
+	result, err := jsonProcessor.Process(context.Background(), jsonFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if len(result.Anomalies) != 1 {
+		t.Fatalf("Expected one schema anomaly, got %+v", result.Anomalies)
+	}
+	if detail := result.Anomalies[0].Detail; strings.Contains(detail, "not-an-address") || !strings.Contains(detail, "redacted") {
+		t.Errorf("Expected the value left out of %q", detail)
+	}
+}
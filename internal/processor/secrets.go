@@ -0,0 +1,216 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// SecretPattern names a regular expression matching a kind of secret
+type SecretPattern struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+}
+
+// DefaultSecretPatterns are the patterns scanned for when none are configured
+var DefaultSecretPatterns = []SecretPattern{
+	{Name: "aws_access_key", Pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "private_key", Pattern: `-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----`},
+	{Name: "github_token", Pattern: `\bgh[pousr]_[A-Za-z0-9]{36}\b`},
+	{Name: "slack_token", Pattern: `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+}
+
+// DefaultSecretEntropy is the Shannon entropy, in bits per character, above
+// which a long token is reported as a likely secret
+// Hex digests stay below it, as 16 symbols carry at most 4 bits each
+const DefaultSecretEntropy = 4.5
+
+// Tokens checked for entropy are runs of letters, digits and +/=_- of at
+// least secretTokenMinLength characters; shorter runs can't reach a useful
+// entropy
+const secretTokenMinLength = 24
+
+// maxSecretLineLength caps the part of a line that is scanned, so a file
+// without newlines can't be buffered whole
+const maxSecretLineLength = 64 * 1024
+
+// ParseSecretPattern parses a "name=regex" pattern, e.g. token=tok_[0-9a-f]{32}
+func ParseSecretPattern(s string) (SecretPattern, error) {
+	name, pattern, ok := strings.Cut(s, "=")
+	if !ok {
+		return SecretPattern{}, fmt.Errorf("%q is not name=regex", s)
+	}
+	return SecretPattern{Name: name, Pattern: pattern}, nil
+}
+
+// namedPattern is a compiled SecretPattern
+type namedPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// SecretScanner flags lines of text that appear to contain secrets, either
+// matching one of its patterns or holding a long high-entropy token
+// Findings carry the line number and the kind of secret but never the
+// matched value, so reports don't spread the secrets they flag
+type SecretScanner struct {
+	patterns   []namedPattern
+	minEntropy float64
+}
+
+// NewSecretScanner compiles patterns into a scanner; minEntropy enables the
+// entropy check for tokens above that many bits per character, 0 disables it
+func NewSecretScanner(patterns []SecretPattern, minEntropy float64) (*SecretScanner, error) {
+	if minEntropy < 0 {
+		return nil, fmt.Errorf("invalid secret entropy %g: must not be negative", minEntropy)
+	}
+	s := &SecretScanner{minEntropy: minEntropy}
+	for _, pattern := range patterns {
+		if pattern.Name == "" {
+			return nil, fmt.Errorf("invalid secret pattern %q: name is empty", pattern.Pattern)
+		}
+		re, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern %s: %w", pattern.Name, err)
+		}
+		s.patterns = append(s.patterns, namedPattern{name: pattern.Name, re: re})
+	}
+	return s, nil
+}
+
+// secretFinding is a likely secret found on a line
+type secretFinding struct {
+	line   int
+	detail string
+}
+
+// secretScan is an io.Writer that runs a SecretScanner over each line of
+// one file
+type secretScan struct {
+	scanner  *SecretScanner
+	line     int
+	buf      []byte
+	pending  bool
+	findings []secretFinding
+}
+
+// newScan starts scanning a file at its first line
+func (s *SecretScanner) newScan() *secretScan {
+	return &secretScan{scanner: s, line: 1}
+}
+
+// Write implements io.Writer, collecting lines across chunk boundaries
+func (c *secretScan) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			c.append(p)
+			c.pending = true
+			break
+		}
+		c.append(p[:i])
+		c.endLine()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// append adds to the current line up to maxSecretLineLength
+func (c *secretScan) append(p []byte) {
+	if room := maxSecretLineLength - len(c.buf); room < len(p) {
+		p = p[:max(room, 0)]
+	}
+	c.buf = append(c.buf, p...)
+}
+
+// Flush scans a final line that has no trailing newline
+func (c *secretScan) Flush() {
+	if c.pending {
+		c.endLine()
+	}
+}
+
+// endLine scans the current line and starts the next one
+func (c *secretScan) endLine() {
+	for _, pattern := range c.scanner.patterns {
+		if pattern.re.Match(c.buf) {
+			c.findings = append(c.findings, secretFinding{line: c.line, detail: "possible " + pattern.name})
+		}
+	}
+	if c.scanner.minEntropy > 0 {
+		if entropy := maxTokenEntropy(c.buf); entropy > c.scanner.minEntropy {
+			c.findings = append(c.findings, secretFinding{
+				line:   c.line,
+				detail: fmt.Sprintf("high-entropy token (%.1f bits per character)", entropy),
+			})
+		}
+	}
+	c.line++
+	c.buf = c.buf[:0]
+	c.pending = false
+}
+
+// maxTokenEntropy returns the highest entropy of the line's tokens that are
+// long enough and mix letters with digits, or 0 when there are none
+func maxTokenEntropy(line []byte) float64 {
+	best := 0.0
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i < len(line) && isTokenByte(line[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= secretTokenMinLength {
+			if token := line[start:i]; hasLetterAndDigit(token) {
+				best = max(best, shannonEntropy(token))
+			}
+		}
+		start = -1
+	}
+	return best
+}
+
+// isTokenByte reports whether b can be part of a key or token
+func isTokenByte(b byte) bool {
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		return true
+	}
+	return b == '+' || b == '/' || b == '=' || b == '_' || b == '-'
+}
+
+// hasLetterAndDigit leaves out long words and identifiers without digits
+func hasLetterAndDigit(token []byte) bool {
+	var letter, digit bool
+	for _, b := range token {
+		switch {
+		case b >= '0' && b <= '9':
+			digit = true
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z':
+			letter = true
+		}
+	}
+	return letter && digit
+}
+
+// shannonEntropy returns the entropy of the byte distribution of s in bits
+// per byte
+func shannonEntropy(s []byte) float64 {
+	var counts [256]int
+	for _, b := range s {
+		counts[b]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / float64(len(s))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
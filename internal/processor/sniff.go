@@ -0,0 +1,167 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// contentHeaderLen is how much of a file is read to identify its content;
+// enough to reach the PE signature of typical Windows executables
+const contentHeaderLen = 1024
+
+// Content kinds reported by SniffContent besides the signatures below and
+// the MIME types of http.DetectContentType
+const (
+	contentText  = "text"
+	contentEmpty = "empty"
+)
+
+// signature identifies a content kind by the bytes it starts with
+type signature struct {
+	kind  string
+	magic []byte
+}
+
+// signatures are checked in order before falling back to http.DetectContentType
+var signatures = []signature{
+	{"ELF executable", []byte("\x7fELF")},
+	{"Mach-O executable", []byte{0xcf, 0xfa, 0xed, 0xfe}},
+	{"Mach-O executable", []byte{0xce, 0xfa, 0xed, 0xfe}},
+	{"Mach-O executable", []byte{0xfe, 0xed, 0xfa, 0xcf}},
+	{"Mach-O executable", []byte{0xfe, 0xed, 0xfa, 0xce}},
+	{"WebAssembly module", []byte("\x00asm")},
+	{"zip archive", []byte("PK\x03\x04")},
+	{"zip archive", []byte("PK\x05\x06")},
+	{"gzip archive", []byte{0x1f, 0x8b}},
+	{"bzip2 archive", []byte("BZh")},
+	{"xz archive", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{"7z archive", []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}},
+	{"rar archive", []byte("Rar!\x1a\x07")},
+	{"PDF document", []byte("%PDF-")},
+	{"PNG image", []byte("\x89PNG\r\n\x1a\n")},
+	{"JPEG image", []byte{0xff, 0xd8, 0xff}},
+	{"GIF image", []byte("GIF87a")},
+	{"GIF image", []byte("GIF89a")},
+	{"SQLite database", []byte("SQLite format 3\x00")},
+}
+
+// expectedContent maps file extensions to the content kinds they may hold
+// Files with other extensions are never reported as mismatched
+var expectedContent = map[string][]string{
+	".txt":    {contentText},
+	".log":    {contentText},
+	".md":     {contentText},
+	".csv":    {contentText},
+	".tsv":    {contentText},
+	".json":   {contentText},
+	".ndjson": {contentText},
+	".jsonl":  {contentText},
+	".xml":    {contentText},
+	".yaml":   {contentText},
+	".yml":    {contentText},
+	".html":   {contentText},
+	".htm":    {contentText},
+	".jpg":    {"JPEG image"},
+	".jpeg":   {"JPEG image"},
+	".png":    {"PNG image"},
+	".gif":    {"GIF image"},
+	".pdf":    {"PDF document"},
+	".zip":    {"zip archive"},
+	".jar":    {"zip archive"},
+	".docx":   {"zip archive"},
+	".xlsx":   {"zip archive"},
+	".pptx":   {"zip archive"},
+	".gz":     {"gzip archive"},
+	".tgz":    {"gzip archive"},
+	".bz2":    {"bzip2 archive"},
+	".xz":     {"xz archive"},
+	".7z":     {"7z archive"},
+	".rar":    {"rar archive"},
+	".exe":    {"Windows executable"},
+	".dll":    {"Windows executable"},
+	".wasm":   {"WebAssembly module"},
+	".db":     {"SQLite database"},
+	".sqlite": {"SQLite database"},
+}
+
+// SniffContent identifies the content of path from its first bytes: one of
+// the executable, archive, document or image kinds with a known signature,
+// "text", "empty", or else the MIME type http.DetectContentType reports
+func SniffContent(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", models.FileError(path, "open file", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, contentHeaderLen)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return sniffHeader(header[:n]), nil
+}
+
+// sniffHeader identifies content from its first bytes
+func sniffHeader(header []byte) string {
+	if len(header) == 0 {
+		return contentEmpty
+	}
+	if isPE(header) {
+		return "Windows executable"
+	}
+	for _, sig := range signatures {
+		if bytes.HasPrefix(header, sig.magic) {
+			return sig.kind
+		}
+	}
+
+	mimeType := http.DetectContentType(header)
+	if strings.HasPrefix(mimeType, "text/") {
+		return contentText
+	}
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return mimeType
+}
+
+// isPE reports a Windows PE executable: an "MZ" header whose e_lfanew
+// field points at the "PE\0\0" signature
+// "MZ" alone is too common at the start of text to go by
+func isPE(header []byte) bool {
+	if len(header) < 0x40 || !bytes.HasPrefix(header, []byte("MZ")) {
+		return false
+	}
+	offset := int(binary.LittleEndian.Uint32(header[0x3c:]))
+	return offset+4 <= len(header) && bytes.Equal(header[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+// ContentMismatch reports whether the content of path contradicts its
+// extension, e.g. a .txt file holding an executable or a .jpg holding a zip
+// archive, returning a description of the mismatch or "" when there is none
+// Empty files and extensions without an expected content kind never mismatch
+func ContentMismatch(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	expected, ok := expectedContent[ext]
+	if !ok {
+		return "", nil
+	}
+
+	kind, err := SniffContent(path)
+	if err != nil || kind == contentEmpty {
+		return "", err
+	}
+	for _, want := range expected {
+		if kind == want {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("%s file contains %s, not %s", ext, kind, strings.Join(expected, " or ")), nil
+}
@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestContentMismatch(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// A minimal PE header: "MZ", e_lfanew at 0x3c and the PE signature
+	pe := make([]byte, 0x90)
+	copy(pe, "MZ")
+	binary.LittleEndian.PutUint32(pe[0x3c:], 0x80)
+	copy(pe[0x80:], "PE\x00\x00")
+
+	tests := []struct {
+		name     string
+		content  []byte
+		mismatch bool
+	}{
+		{"notes.txt", []byte("plain text\n"), false},
+		{"program.txt", pe, true},
+		{"program.exe", pe, false},
+		{"mz.txt", []byte("MZ is just the start of this sentence, padded to be long enough for a header check\n"), false},
+		{"photo.jpg", []byte("PK\x03\x04rest of a zip archive"), true},
+		{"photo.jpeg", []byte{0xff, 0xd8, 0xff, 0xe0, 0, 0x10, 'J', 'F', 'I', 'F'}, false},
+		{"data.json", []byte(`{"id": 1}`), false},
+		{"data.csv", []byte("\x7fELF\x02\x01\x01"), true},
+		{"image.png", []byte("just text"), true},
+		{"empty.txt", nil, false},
+		{"unknown.bin", pe, false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(tmpDir, tt.name)
+		if err := os.WriteFile(path, tt.content, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		detail, err := ContentMismatch(path)
+		if err != nil {
+			t.Fatalf("Failed to check %s: %v", tt.name, err)
+		}
+		if (detail != "") != tt.mismatch {
+			t.Errorf("%s: expected mismatch %v, got %q", tt.name, tt.mismatch, detail)
+		}
+	}
+
+	if detail, _ := ContentMismatch(filepath.Join(tmpDir, "program.txt")); detail != ".txt file contains Windows executable, not text" {
+		t.Errorf("Unexpected mismatch detail: %q", detail)
+	}
+}
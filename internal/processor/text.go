@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -18,6 +19,18 @@ type TextProcessor struct {
 	*models.BaseProcessor
 	// Supported extensions
 	extensions []string
+	// Count unique vs duplicate lines (costs memory and CPU)
+	detectDuplicates bool
+	// Report line lengths and count lines longer than this many runes (0 disables)
+	lineLengthThreshold int
+	// Count matches of this pattern as words instead of whitespace-separated runs
+	wordPattern *regexp.Regexp
+	// Report the dominant indentation and flag lines mixing tabs and spaces
+	checkIndentation bool
+	// Count lines, words and bytes exactly as wc does
+	wcCompatible bool
+	// Flag lines that appear to contain secrets (nil disables)
+	secrets *SecretScanner
 }
 
 // NewTextProcessor demonstrates a constructor function with variadic parameters
@@ -62,7 +75,7 @@ func (p *TextProcessor) Process(ctx context.Context, path string) (models.Proces
 	// Get file info
 	info, err := os.Stat(path)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to get file info: %w", err)
+		result.Error = models.FileError(path, "get file info", err)
 		return result, result.Error
 	}
 
@@ -70,10 +83,31 @@ func (p *TextProcessor) Process(ctx context.Context, path string) (models.Proces
 	result.Size = info.Size()
 	result.Modified = info.ModTime()
 
+	// Skip oversized files before reading them
+	if err := p.CheckFileSize(path, info.Size()); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Hash the raw bytes in the same pass when enabled
+	digest, err := newDigest(ctx, p.HashAlgorithm())
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Wait for a file slot shared by every processor
+	release, err := acquireFile(ctx)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	defer release()
+
 	// Open the file
 	file, err := os.Open(path)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to open file: %w", err)
+		result.Error = models.FileError(path, "open file", err)
 		return result, result.Error
 	}
 	defer file.Close()
@@ -81,70 +115,111 @@ func (p *TextProcessor) Process(ctx context.Context, path string) (models.Proces
 	// Process the file content
 	// Demonstrates multiple assignment from function return
 	start := time.Now()
-	result.Lines, result.Words, result.Bytes, err = p.readLines(file)
-	result.Duration = time.Since(start)
 
+	// wc counts the raw stream, BOM and all
+	raw := teeDigest(file, digest)
+	var wc *wcCounter
+	if p.wcCompatible {
+		wc = newWCCounter()
+		raw = io.TeeReader(raw, wc)
+	}
+
+	// A leading BOM is not content, so keep it out of the counts
+	reader, hasBOM, err := stripBOM(raw)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to process file: %w", err)
+		result.Error = fmt.Errorf("failed to read file: %w", err)
 		return result, result.Error
 	}
+	result.HasBOM = hasBOM
 
-	return result, nil
-}
+	// Line analyses observe the stream as it is counted
+	blank := newBlankDetector()
+	encoding := newUTF8Checker()
+	reader = io.TeeReader(reader, io.MultiWriter(blank, encoding))
+
+	var dedup *lineDeduper
+	if p.detectDuplicates {
+		dedup = newLineDeduper(maxDedupLines)
+		reader = io.TeeReader(reader, dedup)
+	}
+
+	var lengths *lineLengthCounter
+	if p.lineLengthThreshold > 0 {
+		lengths = newLineLengthCounter(p.lineLengthThreshold)
+		reader = io.TeeReader(reader, lengths)
+	}
 
-// readLines counts lines, words, and bytes in a reader
-// Demonstrates working with io.Reader and multiple return values
-func (p *TextProcessor) readLines(reader io.Reader) (lines, words, bytes int, err error) {
-	// Create a buffer for reading
-	// Demonstrates array usage
-	buf := make([]byte, 4096)
-
-	// Variables to track state
-	var (
-		inWord bool
-		count  int
-	)
-
-	// Read the file in chunks
-	// Demonstrates for loop with multiple conditions
-	for {
-		count, err = reader.Read(buf)
-		if err != nil {
-			if err == io.EOF {
-				err = nil
-				break
-			}
-			return
+	var indent *indentChecker
+	if p.checkIndentation {
+		indent = newIndentChecker()
+		reader = io.TeeReader(reader, indent)
+	}
+
+	var secrets *secretScan
+	if p.secrets != nil {
+		secrets = p.secrets.newScan()
+		reader = io.TeeReader(reader, secrets)
+	}
+
+	var words *patternWordCounter
+	if p.wordPattern != nil {
+		words = newPatternWordCounter(p.wordPattern)
+		reader = io.TeeReader(reader, words)
+	}
+
+	result.Lines, result.Words, result.Bytes, err = p.ReadLines(reader)
+	result.Duration = time.Since(start)
+	result.IsEmpty = blank.Blank()
+	if line := encoding.InvalidLine(); line > 0 {
+		result.AddAnomaly("encoding", "invalid UTF-8 sequence", line)
+	}
+
+	if dedup != nil {
+		dedup.Flush()
+		result.UniqueLines = dedup.unique
+		result.DuplicateLines = dedup.duplicate
+	}
+
+	if words != nil {
+		words.Flush()
+		result.Words = words.words
+	}
+
+	if wc != nil {
+		result.Lines, result.Words, result.Bytes = wc.lines, wc.words, wc.bytes
+	}
+
+	if indent != nil {
+		result.IndentStyle = indent.Style()
+		result.IndentWidth = indent.Width()
+		mismatched, first := indent.Mismatched()
+		result.MismatchedIndentLines = mismatched
+		if mismatched > 0 {
+			result.AddAnomaly("mixed_indentation", indent.Detail(mismatched), first)
 		}
+	}
 
-		bytes += count
-
-		// Process the buffer
-		// Demonstrates range loop over slice
-		for _, b := range buf[:count] {
-			// Count lines
-			if b == '\n' {
-				lines++
-			}
-
-			// Count words
-			// Demonstrates switch statement
-			switch {
-			case b == ' ' || b == '\n' || b == '\t':
-				inWord = false
-			case !inWord:
-				words++
-				inWord = true
-			}
+	if secrets != nil {
+		secrets.Flush()
+		for _, finding := range secrets.findings {
+			result.AddAnomaly("secret", finding.detail, finding.line)
 		}
 	}
 
-	// Adjust final counts
-	if bytes > 0 && !inWord {
-		lines++
+	if lengths != nil {
+		lengths.Flush()
+		result.MaxLineLength = lengths.max
+		result.AvgLineLength = lengths.Average()
+		result.LinesOverThreshold = lengths.over
+	}
+
+	if err != nil {
+		result.Error = fmt.Errorf("failed to process file: %w", err)
+		return result, result.Error
 	}
+	result.Hash = digestSum(digest)
 
-	return
+	return result, nil
 }
 
 // SupportedExtensions demonstrates a method returning a slice
@@ -156,6 +231,47 @@ func (p *TextProcessor) SupportedExtensions() []string {
 	return result
 }
 
+// SetDetectDuplicates enables counting of unique and duplicate lines
+// It is off by default because it keeps a hash per distinct line, up to
+// the most recent 262144 of them
+func (p *TextProcessor) SetDetectDuplicates(enabled bool) {
+	p.detectDuplicates = enabled
+}
+
+// SetLineLengthThreshold enables line length statistics, counting lines
+// longer than threshold runes; 0 disables them
+func (p *TextProcessor) SetLineLengthThreshold(threshold int) {
+	p.lineLengthThreshold = threshold
+}
+
+// SetWordPattern defines a word as a match of pattern, e.g. [[:alnum:]]+ to
+// count only alphanumeric tokens; matches never span lines
+// nil restores the default of counting runs of non-whitespace
+func (p *TextProcessor) SetWordPattern(pattern *regexp.Regexp) {
+	p.wordPattern = pattern
+}
+
+// SetCheckIndentation enables reporting the dominant indentation style and
+// flagging lines that mix tabs and spaces or use the other style
+func (p *TextProcessor) SetCheckIndentation(enabled bool) {
+	p.checkIndentation = enabled
+}
+
+// SetSecretScanner flags lines that appear to contain secrets as anomalies,
+// without the matched values; nil disables the scan
+func (p *TextProcessor) SetSecretScanner(scanner *SecretScanner) {
+	p.secrets = scanner
+}
+
+// SetWCCompatible makes line, word and byte counts match `wc -l -w -c` in
+// the C locale, replacing the default counts and any word pattern
+// By default a file ending in whitespace counts one line more than it has
+// newlines, only space, tab and newline separate words, and a leading BOM is
+// left out of the byte count
+func (p *TextProcessor) SetWCCompatible(enabled bool) {
+	p.wcCompatible = enabled
+}
+
 // AddExtension demonstrates method with pointer receiver
 func (p *TextProcessor) AddExtension(ext string) {
 	// Demonstrates string manipulation
@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// tuneFilesPerWorker is how many files each worker gets while a worker
+	// count is measured
+	tuneFilesPerWorker = 4
+
+	// tuneMinGain is how much faster a larger worker count must be to be
+	// chosen, so timing noise doesn't favour extra workers
+	tuneMinGain = 1.05
+)
+
+// WorkerBenchmark is the throughput measured for one worker count
+type WorkerBenchmark struct {
+	Workers        int     `json:"workers"`
+	Files          int     `json:"files"`
+	FilesPerSecond float64 `json:"files_per_second"`
+}
+
+// TuneWorkers picks a worker count by timing run with 1, 2, 4, … workers,
+// up to maxWorkers, each on its own batch of files taken from the front of
+// files, and returns the fastest count, the files left over and the
+// measurements; best is 0 when too few files were given to measure any
+// run must process every file of the batch it is given. Each batch is
+// processed for real, so callers keep its results and go on with the rest
+// of the files using the chosen count; fresh files also keep the OS cache
+// from favouring later rounds
+// Tuning stops early when there aren't enough files left or ctx is done
+func TuneWorkers(ctx context.Context, files []string, maxWorkers int, run func(batch []string, workers int)) (best int, rest []string, benchmarks []WorkerBenchmark) {
+	var bestRate float64
+	for workers := 1; workers <= maxWorkers; workers *= 2 {
+		n := workers * tuneFilesPerWorker
+		if len(files) < n || ctx.Err() != nil {
+			break
+		}
+		batch := files[:n]
+		files = files[n:]
+
+		start := time.Now()
+		run(batch, workers)
+		rate := float64(n) / max(time.Since(start).Seconds(), 1e-9)
+
+		benchmarks = append(benchmarks, WorkerBenchmark{Workers: workers, Files: n, FilesPerSecond: rate})
+		if rate > bestRate*tuneMinGain {
+			best, bestRate = workers, rate
+		}
+	}
+	return best, files, benchmarks
+}
@@ -0,0 +1,44 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTuneWorkers(t *testing.T) {
+	files := make([]string, 100)
+	for i := range files {
+		files[i] = fmt.Sprintf("file%d.txt", i)
+	}
+
+	// Work that divides perfectly between workers favours the most workers
+	var processed []string
+	scaling := func(batch []string, workers int) {
+		processed = append(processed, batch...)
+		time.Sleep(time.Duration(len(batch)/workers) * 2 * time.Millisecond)
+	}
+	best, rest, benchmarks := TuneWorkers(context.Background(), files, 4, scaling)
+	if best != 4 || len(benchmarks) != 3 {
+		t.Errorf("Expected 4 workers after 3 rounds, got %d after %+v", best, benchmarks)
+	}
+	// 4, 8 and 16 files were used up, in order
+	if len(rest) != 72 || rest[0] != "file28.txt" || len(processed) != 28 || processed[27] != "file27.txt" {
+		t.Errorf("Expected the first 28 files to be processed, got %d processed and %d left", len(processed), len(rest))
+	}
+
+	// Work that doesn't parallelize keeps a single worker
+	serial := func(batch []string, workers int) {
+		time.Sleep(time.Duration(len(batch)) * 3 * time.Millisecond)
+	}
+	if best, _, _ := TuneWorkers(context.Background(), files, 8, serial); best != 1 {
+		t.Errorf("Expected 1 worker for serial work, got %d", best)
+	}
+
+	// Too few files measure nothing
+	best, rest, benchmarks = TuneWorkers(context.Background(), files[:3], 8, serial)
+	if best != 0 || len(rest) != 3 || len(benchmarks) != 0 {
+		t.Errorf("Expected no tuning for 3 files, got %d with %+v", best, benchmarks)
+	}
+}
@@ -0,0 +1,77 @@
+package processor
+
+import "unicode/utf8"
+
+// utf8Checker is an io.Writer that finds the first invalid UTF-8 sequence
+// in a stream and the line it is on
+// Streams starting with a UTF-16 byte order mark are not checked
+type utf8Checker struct {
+	line    int
+	started bool
+	skip    bool
+	carry   []byte
+	// invalidLine is the 1-based line of the first invalid sequence, or 0
+	invalidLine int
+}
+
+// newUTF8Checker creates a checker positioned at the first line
+func newUTF8Checker() *utf8Checker {
+	return &utf8Checker{line: 1}
+}
+
+// Write implements io.Writer, decoding sequences across chunk boundaries
+func (c *utf8Checker) Write(p []byte) (int, error) {
+	n := len(p)
+	if c.skip || c.invalidLine != 0 {
+		return n, nil
+	}
+
+	data := p
+	if len(c.carry) > 0 {
+		data = append(c.carry, p...)
+		c.carry = nil
+	}
+
+	if !c.started {
+		if len(data) < 2 {
+			c.carry = append([]byte(nil), data...)
+			return n, nil
+		}
+		c.started = true
+		if (data[0] == 0xFF && data[1] == 0xFE) || (data[0] == 0xFE && data[1] == 0xFF) {
+			c.skip = true
+			return n, nil
+		}
+	}
+
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			if data[0] == '\n' {
+				c.line++
+			}
+			data = data[1:]
+			continue
+		}
+		if !utf8.FullRune(data) {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			c.invalidLine = c.line
+			return n, nil
+		}
+		data = data[size:]
+	}
+
+	c.carry = append([]byte(nil), data...)
+	return n, nil
+}
+
+// InvalidLine returns the line of the first invalid sequence, or 0 if the
+// stream was valid UTF-8; a sequence cut off at the end counts as invalid
+func (c *utf8Checker) InvalidLine() int {
+	if c.invalidLine == 0 && !c.skip && len(c.carry) > 0 && !utf8.Valid(c.carry) {
+		return c.line
+	}
+	return c.invalidLine
+}
@@ -0,0 +1,35 @@
+package processor
+
+// wcCounter is an io.Writer that counts lines, words and bytes the way
+// `wc -l -w -c` does in the C locale
+// Unlike the default counts it sees the raw bytes, BOM included, counts only
+// newlines as lines, and treats \r, \v and \f as whitespace too
+type wcCounter struct {
+	lines, words, bytes int
+	inWord              bool
+}
+
+// newWCCounter creates a counter with nothing counted yet
+func newWCCounter() *wcCounter {
+	return &wcCounter{}
+}
+
+// Write implements io.Writer, keeping word state across chunk boundaries
+func (c *wcCounter) Write(p []byte) (int, error) {
+	c.bytes += len(p)
+	for _, b := range p {
+		switch b {
+		case '\n':
+			c.lines++
+			c.inWord = false
+		case ' ', '\t', '\r', '\v', '\f':
+			c.inWord = false
+		default:
+			if !c.inWord {
+				c.words++
+				c.inWord = true
+			}
+		}
+	}
+	return len(p), nil
+}
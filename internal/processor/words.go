@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// patternWordCounter is an io.Writer that counts the matches of a word
+// pattern line by line, so a word never spans a line break
+// Only the current line is buffered
+type patternWordCounter struct {
+	pattern *regexp.Regexp
+	line    []byte
+	words   int
+}
+
+// newPatternWordCounter creates a counter for words matching pattern
+func newPatternWordCounter(pattern *regexp.Regexp) *patternWordCounter {
+	return &patternWordCounter{pattern: pattern}
+}
+
+// Write implements io.Writer, collecting lines across chunk boundaries
+func (c *patternWordCounter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			c.line = append(c.line, p...)
+			break
+		}
+		c.line = append(c.line, p[:i]...)
+		c.endLine()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Flush counts the words of a final line that has no trailing newline
+func (c *patternWordCounter) Flush() {
+	if len(c.line) > 0 {
+		c.endLine()
+	}
+}
+
+// endLine counts the words on the current line and starts a new one
+func (c *patternWordCounter) endLine() {
+	line := bytes.TrimSuffix(c.line, []byte{'\r'})
+	c.words += len(c.pattern.FindAllIndex(line, -1))
+	c.line = c.line[:0]
+}
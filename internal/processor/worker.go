@@ -2,10 +2,12 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/retry"
 )
 
 // WorkRequest represents a file processing request
@@ -27,6 +29,16 @@ type WorkerPool struct {
 	done chan struct{}
 	// Demonstrates error handling with channels
 	errors chan error
+	// retry controls how failed files are processed again; one attempt by default
+	retry retry.Policy
+	// retryMetrics counts retries and permanent failures when set
+	retryMetrics RetryMetrics
+}
+
+// RetryMetrics receives retry outcomes, as monitor.MetricsCollector does
+type RetryMetrics interface {
+	IncrementRetries()
+	IncrementPermanentErrors()
 }
 
 // NewWorkerPool creates a new worker pool
@@ -46,6 +58,18 @@ func NewWorkerPool(size int, processor models.Processor) *WorkerPool {
 	}
 }
 
+// SetRetryPolicy retries files whose processing fails with an error the
+// policy accepts; call it before Start
+func (p *WorkerPool) SetRetryPolicy(policy retry.Policy) {
+	p.retry = policy
+}
+
+// SetRetryMetrics counts each retry attempt and each file that still fails
+// once retries are exhausted or not allowed; call it before Start
+func (p *WorkerPool) SetRetryMetrics(metrics RetryMetrics) {
+	p.retryMetrics = metrics
+}
+
 // Start launches the worker pool
 // Demonstrates goroutine management
 func (p *WorkerPool) Start(ctx context.Context) {
@@ -64,18 +88,34 @@ func (p *WorkerPool) Stop() {
 	<-p.done
 }
 
-// Submit adds a file to be processed
+// submitTimeout bounds how long Submit waits for room in the queue
+const submitTimeout = 5 * time.Second
+
+// Submit adds a file to be processed, giving up after submitTimeout
 // Demonstrates non-blocking channel operations
 func (p *WorkerPool) Submit(path string) (chan models.ProcessResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), submitTimeout)
+	defer cancel()
+
+	responseChan, err := p.SubmitCtx(ctx, path)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("submission timeout: worker pool is full")
+	}
+	return responseChan, err
+}
+
+// SubmitCtx adds a file to be processed, waiting for room in the queue
+// until ctx is done, in which case ctx.Err() is returned
+func (p *WorkerPool) SubmitCtx(ctx context.Context, path string) (chan models.ProcessResult, error) {
 	// Create response channel
 	responseChan := make(chan models.ProcessResult, 1)
 
-	// Demonstrates select with timeout
+	// Demonstrates select with cancellation
 	select {
 	case p.requests <- WorkRequest{FilePath: path, ResponseChan: responseChan}:
 		return responseChan, nil
-	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("submission timeout: worker pool is full")
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
 }
 
@@ -96,8 +136,22 @@ func (p *WorkerPool) worker(ctx context.Context, id int) {
 		case <-ctx.Done():
 			return
 		default:
-			// Process the file
-			result, err := p.processor.Process(ctx, req.FilePath)
+			// Process the file, retrying transient failures; a panicking
+			// processor fails the file instead of the worker
+			var result models.ProcessResult
+			attempts := 0
+			err := retry.Do(ctx, p.retry, func() error {
+				attempts++
+				if attempts > 1 && p.retryMetrics != nil {
+					p.retryMetrics.IncrementRetries()
+				}
+				var err error
+				result, err = SafeProcess(ctx, p.processor, req.FilePath)
+				return err
+			})
+			if err != nil && p.retryMetrics != nil {
+				p.retryMetrics.IncrementPermanentErrors()
+			}
 			if err != nil {
 				// Demonstrates error channel
 				select {
@@ -0,0 +1,168 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/monitor"
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/retry"
+)
+
+func TestWorkerPoolSubmitCtx(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("hello world\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Without workers the queue (two slots for a pool of one) fills up
+	pool := NewWorkerPool(1, NewTextProcessor(4096))
+	for i := 0; i < 2; i++ {
+		if _, err := pool.SubmitCtx(context.Background(), testFile); err != nil {
+			t.Fatalf("Failed to submit: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if _, err := pool.SubmitCtx(ctx, testFile); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Cancelled submission took %v", elapsed)
+	}
+
+	// Once workers run, queued files are processed
+	pool.Start(context.Background())
+	defer pool.Stop()
+
+	responses, err := pool.SubmitCtx(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	if result := <-responses; result.Words != 2 {
+		t.Errorf("Expected 2 words, got %d", result.Words)
+	}
+}
+
+// flakyProcessor fails the first failures calls with errFlaky
+type flakyProcessor struct {
+	*TextProcessor
+	failures int
+}
+
+var errFlaky = errors.New("flaky read")
+
+func (p *flakyProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
+	if p.failures > 0 {
+		p.failures--
+		return models.ProcessResult{Error: errFlaky}, errFlaky
+	}
+	return p.TextProcessor.Process(ctx, path)
+}
+
+func TestWorkerPoolRetry(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("hello world\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	flaky := &flakyProcessor{TextProcessor: NewTextProcessor(4096), failures: 2}
+	metrics := monitor.NewMetricsCollector(time.Minute)
+	pool := NewWorkerPool(1, flaky)
+	pool.SetRetryPolicy(retry.Policy{
+		BaseDelay:   time.Millisecond,
+		MaxAttempts: 3,
+		IsRetryable: func(err error) bool { return errors.Is(err, errFlaky) },
+	})
+	pool.SetRetryMetrics(metrics)
+	pool.Start(context.Background())
+	defer pool.Stop()
+
+	responses, err := pool.SubmitCtx(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	if result := <-responses; result.Error != nil || result.Words != 2 {
+		t.Errorf("Expected the third attempt to succeed, got %+v", result)
+	}
+	if retries, permanent := metrics.GetRetryMetrics(); retries != 2 || permanent != 0 {
+		t.Errorf("Expected 2 retries and no permanent errors, got %d and %d", retries, permanent)
+	}
+
+	// Failures outlasting the attempts are permanent
+	flaky.failures = 5
+	responses, err = pool.SubmitCtx(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	if result := <-responses; !errors.Is(result.Error, errFlaky) {
+		t.Errorf("Expected the flaky error, got %v", result.Error)
+	}
+	if retries, permanent := metrics.GetRetryMetrics(); retries != 4 || permanent != 1 {
+		t.Errorf("Expected 4 retries and 1 permanent error, got %d and %d", retries, permanent)
+	}
+}
+
+// panickyProcessor panics on files whose name ends in bad.txt, which
+// includes archive entries extracted to temporary files
+type panickyProcessor struct {
+	*TextProcessor
+}
+
+func (p *panickyProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
+	if strings.HasSuffix(filepath.Base(path), "bad.txt") {
+		panic("malformed input")
+	}
+	return p.TextProcessor.Process(ctx, path)
+}
+
+func TestWorkerPoolRecoversPanic(t *testing.T) {
+	tmpDir := t.TempDir()
+	badFile := filepath.Join(tmpDir, "bad.txt")
+	goodFile := filepath.Join(tmpDir, "good.txt")
+	for _, path := range []string{badFile, goodFile} {
+		if err := os.WriteFile(path, []byte("hello world\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	pool := NewWorkerPool(1, &panickyProcessor{TextProcessor: NewTextProcessor(4096)})
+	pool.Start(context.Background())
+	defer pool.Stop()
+
+	responses, err := pool.SubmitCtx(context.Background(), badFile)
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	result := <-responses
+	if !apperrors.IsErrorType(result.Error, apperrors.ErrorTypeFormat) {
+		t.Errorf("Expected a format error, got %v", result.Error)
+	}
+	var panicErr *PanicError
+	if !errors.As(result.Error, &panicErr) || panicErr.Value != "malformed input" || len(panicErr.Stack) == 0 {
+		t.Errorf("Expected the panic value and stack, got %v", result.Error)
+	}
+
+	// The same worker keeps processing files
+	responses, err = pool.SubmitCtx(context.Background(), goodFile)
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	if result := <-responses; result.Error != nil || result.Words != 2 {
+		t.Errorf("Expected the next file to succeed, got %+v", result)
+	}
+}
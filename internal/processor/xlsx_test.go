@@ -0,0 +1,55 @@
+//go:build xlsx
+
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXLSXProcessor(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "book.xlsx")
+
+	workbook := excelize.NewFile()
+	workbook.SetSheetRow("Sheet1", "A1", &[]interface{}{"name", "qty"})
+	workbook.SetSheetRow("Sheet1", "A2", &[]interface{}{"apple", 3})
+	workbook.SetSheetRow("Sheet1", "A3", &[]interface{}{"pear", nil})
+	if _, err := workbook.NewSheet("Notes"); err != nil {
+		t.Fatalf("Failed to add sheet: %v", err)
+	}
+	workbook.SetCellValue("Notes", "B2", "remember")
+	if err := workbook.SaveAs(testFile); err != nil {
+		t.Fatalf("Failed to create workbook: %v", err)
+	}
+
+	processor := NewXLSXProcessor(4096)
+	if !processor.CanHandle("REPORT.XLSX") {
+		t.Error("Expected .xlsx to be handled case-insensitively")
+	}
+
+	result, err := processor.Process(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+	if result.Lines != 5 || result.Words != 6 {
+		t.Errorf("Expected 5 rows and 6 cells, got %d and %d", result.Lines, result.Words)
+	}
+	if result.Extra["sheets"] != 2 || result.Extra["rows:Sheet1"] != 3 || result.Extra["rows:Notes"] != 2 {
+		t.Errorf("Unexpected per-sheet breakdown: %v", result.Extra)
+	}
+
+	// A corrupt workbook is a format error
+	corrupt := filepath.Join(tmpDir, "corrupt.xlsx")
+	if err := os.WriteFile(corrupt, []byte("not a zip file"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := processor.Process(context.Background(), corrupt); !apperrors.IsErrorType(err, apperrors.ErrorTypeFormat) {
+		t.Errorf("Expected a format error, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
 	"fmt"
@@ -10,12 +11,15 @@ import (
 	"strings"
 	"time"
 
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
 	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
 )
 
 // XMLProcessor implements the Processor interface for XML files
 type XMLProcessor struct {
 	*models.BaseProcessor
+	// textSink receives the character data of each file when set
+	textSink TextSink
 }
 
 // NewXMLProcessor creates a new XML processor
@@ -31,6 +35,17 @@ func (p *XMLProcessor) CanHandle(path string) bool {
 	return ext == ".xml"
 }
 
+// SupportedExtensions implements ExtensionLister
+func (p *XMLProcessor) SupportedExtensions() []string {
+	return []string{".xml"}
+}
+
+// SetTextSink passes the text nodes of each successfully processed file,
+// trimmed and one per line, to sink; nil stops extracting text
+func (p *XMLProcessor) SetTextSink(sink TextSink) {
+	p.textSink = sink
+}
+
 // Process implements the Processor interface
 func (p *XMLProcessor) Process(ctx context.Context, path string) (models.ProcessResult, error) {
 	result := models.ProcessResult{
@@ -44,24 +59,51 @@ func (p *XMLProcessor) Process(ctx context.Context, path string) (models.Process
 	// Get file info
 	info, err := os.Stat(path)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to get file info: %w", err)
+		result.Error = models.FileError(path, "get file info", err)
 		return result, result.Error
 	}
 
 	result.Size = info.Size()
 	result.Modified = info.ModTime()
 
+	// Skip oversized files before reading them
+	if err := p.CheckFileSize(path, info.Size()); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Hash the raw bytes in the same pass when enabled
+	digest, err := newDigest(ctx, p.HashAlgorithm())
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Wait for a file slot shared by every processor
+	release, err := acquireFile(ctx)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	defer release()
+
 	// Open the file
 	file, err := os.Open(path)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to open file: %w", err)
+		result.Error = models.FileError(path, "open file", err)
 		return result, result.Error
 	}
 	defer file.Close()
 
 	// Process the XML file
 	start := time.Now()
-	decoder := xml.NewDecoder(file)
+	decoder := xml.NewDecoder(teeDigest(file, digest))
+
+	// Collect the text nodes, one per line, only when they are wanted
+	var text *bytes.Buffer
+	if p.textSink != nil {
+		text = &bytes.Buffer{}
+	}
 
 	// Count elements and calculate size
 	var elements, textNodes int
@@ -71,7 +113,9 @@ func (p *XMLProcessor) Process(ctx context.Context, path string) (models.Process
 			break
 		}
 		if err != nil {
-			result.Error = fmt.Errorf("failed to decode XML: %w", err)
+			line, column := decoder.InputPos()
+			result.Error = apperrors.NewProcessError(apperrors.ErrorTypeFormat, path, "failed to decode XML", err).
+				WithPosition(line, column, decoder.InputOffset())
 			return result, result.Error
 		}
 
@@ -79,16 +123,28 @@ func (p *XMLProcessor) Process(ctx context.Context, path string) (models.Process
 		case xml.StartElement:
 			elements++
 		case xml.CharData:
-			if len(strings.TrimSpace(string(t))) > 0 {
+			if trimmed := bytes.TrimSpace(t); len(trimmed) > 0 {
 				textNodes++
+				if text != nil {
+					text.Write(trimmed)
+					text.WriteByte('\n')
+				}
 			}
 		}
 	}
 
+	if text != nil {
+		if err := p.textSink(path, text.Bytes()); err != nil {
+			result.Error = fmt.Errorf("failed to write extracted text: %w", err)
+			return result, result.Error
+		}
+	}
+
 	result.Duration = time.Since(start)
 	result.Lines = elements + textNodes // Count both elements and text nodes
 	result.Words = textNodes            // Use text nodes as word count
 	result.Bytes = int(info.Size())
+	result.Hash = digestSum(digest)
 
 	return result, nil
 }
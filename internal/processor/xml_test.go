@@ -44,4 +44,41 @@ func TestXMLProcessor(t *testing.T) {
 	if result.Lines < expectedElements {
 		t.Errorf("Expected at least %d elements, got %d", expectedElements, result.Lines)
 	}
-} 
\ No newline at end of file
+}
+
+func TestXMLProcessorTextSink(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "feed.xml")
+	content := `<feed><title> News </title><entry><p>First <b>bold</b> story</p></entry></feed>`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	outDir := filepath.Join(tmpDir, "text")
+	processor := NewXMLProcessor(4096)
+	processor.SetTextSink(DirTextSink(outDir))
+	if _, err := processor.Process(context.Background(), testFile); err != nil {
+		t.Fatalf("Failed to process file: %v", err)
+	}
+
+	// The text mirrors the file's path under the output directory
+	text, err := os.ReadFile(filepath.Join(outDir, testFile+".txt"))
+	if err != nil {
+		t.Fatalf("Failed to read extracted text: %v", err)
+	}
+	if want := "News\nFirst\nbold\nstory\n"; string(text) != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+
+	// Malformed files produce no text
+	broken := filepath.Join(tmpDir, "broken.xml")
+	if err := os.WriteFile(broken, []byte("<a>text</b>"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := processor.Process(context.Background(), broken); err == nil {
+		t.Error("Expected an error for malformed XML")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, broken+".txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected no text for malformed XML, got %v", err)
+	}
+}
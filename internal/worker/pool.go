@@ -2,10 +2,16 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
 
+// ErrStopTimeout is returned by StopTimeout when workers are still running
+// once the timeout has passed
+var ErrStopTimeout = errors.New("worker pool stop timed out")
+
 // Task represents a unit of work to be processed
 type Task interface {
 	Process() error
@@ -21,6 +27,16 @@ type Pool struct {
 	wg          sync.WaitGroup
 	ctx         context.Context
 	cancel      context.CancelFunc
+	// stopped is closed once every worker has exited and results is closed
+	stopped  chan struct{}
+	stopOnce sync.Once
+
+	// While running, resumed is closed and pausing is open; while paused it
+	// is the other way round. pauseMu guards swapping them
+	pauseMu sync.Mutex
+	paused  bool
+	resumed chan struct{}
+	pausing chan struct{}
 }
 
 // NewPool creates a new worker pool with specified parameters
@@ -34,7 +50,11 @@ func NewPool(workers int, queueSize int, rateLimit time.Duration) *Pool {
 		results:     make(chan error, queueSize),
 		ctx:         ctx,
 		cancel:      cancel,
+		stopped:     make(chan struct{}),
+		resumed:     make(chan struct{}),
+		pausing:     make(chan struct{}),
 	}
+	close(pool.resumed)
 
 	// Initialize rate limiter tokens
 	for i := 0; i < workers; i++ {
@@ -65,10 +85,40 @@ func (p *Pool) Submit(task Task) error {
 
 // Stop gracefully shuts down the worker pool
 func (p *Pool) Stop() {
-	p.cancel()
-	close(p.tasks)
-	p.wg.Wait()
-	close(p.results)
+	<-p.shutdown()
+}
+
+// StopTimeout shuts down the worker pool like Stop, but waits at most d for
+// running tasks to return
+// On timeout it returns an error wrapping ErrStopTimeout; the remaining
+// workers exit in the background once their tasks return
+func (p *Pool) StopTimeout(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-p.shutdown():
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w after %v", ErrStopTimeout, d)
+	}
+}
+
+// shutdown cancels the workers and closes the queue the first time it is
+// called, returning a channel that is closed once every worker has exited
+// and Results is closed
+func (p *Pool) shutdown() <-chan struct{} {
+	p.stopOnce.Do(func() {
+		p.cancel()
+		close(p.tasks)
+
+		go func() {
+			p.wg.Wait()
+			close(p.results)
+			close(p.stopped)
+		}()
+	})
+	return p.stopped
 }
 
 // Results returns the channel for receiving task results
@@ -76,11 +126,70 @@ func (p *Pool) Results() <-chan error {
 	return p.results
 }
 
+// Pause stops workers from taking new tasks; tasks already running finish
+// Submit still queues tasks while paused. Pausing a paused pool does nothing
+func (p *Pool) Pause() {
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+	if p.paused {
+		return
+	}
+	p.paused = true
+	p.resumed = make(chan struct{})
+	close(p.pausing)
+}
+
+// Resume lets workers take tasks again after Pause
+func (p *Pool) Resume() {
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+	if !p.paused {
+		return
+	}
+	p.paused = false
+	p.pausing = make(chan struct{})
+	close(p.resumed)
+}
+
+// pauseState returns the channels closed on resuming and on pausing
+func (p *Pool) pauseState() (resumed, pausing <-chan struct{}) {
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+	return p.resumed, p.pausing
+}
+
+// nextTask waits until the pool isn't paused and a task is queued, returning
+// false once the pool is stopping
+// A pause while waiting for a task sends the worker back to waiting it out
+func (p *Pool) nextTask() (Task, bool) {
+	for {
+		resumed, pausing := p.pauseState()
+		select {
+		case <-resumed:
+		case <-p.ctx.Done():
+			return nil, false
+		}
+
+		select {
+		case task, ok := <-p.tasks:
+			return task, ok
+		case <-pausing:
+		case <-p.ctx.Done():
+			return nil, false
+		}
+	}
+}
+
 // worker processes tasks with rate limiting
 func (p *Pool) worker(id int) {
 	defer p.wg.Done()
 
-	for task := range p.tasks {
+	for {
+		task, ok := p.nextTask()
+		if !ok {
+			return
+		}
+
 		select {
 		case <-p.ctx.Done():
 			return
@@ -97,9 +206,15 @@ func (p *Pool) worker(id int) {
 
 // Stats represents pool statistics
 type Stats struct {
-	ActiveWorkers  int
-	QueuedTasks    int
-	CompletedTasks int
+	ActiveWorkers  int  `json:"active_workers"`
+	QueuedTasks    int  `json:"queued_tasks"`
+	CompletedTasks int  `json:"completed_tasks"`
+	Paused         bool `json:"paused"`
+}
+
+// String formats the statistics for logging
+func (s Stats) String() string {
+	return fmt.Sprintf("active=%d queued=%d completed=%d paused=%t", s.ActiveWorkers, s.QueuedTasks, s.CompletedTasks, s.Paused)
 }
 
 // GetStats returns current pool statistics
@@ -108,5 +223,13 @@ func (p *Pool) GetStats() Stats {
 		ActiveWorkers:  p.workers - len(p.rateLimiter),
 		QueuedTasks:    len(p.tasks),
 		CompletedTasks: cap(p.tasks) - len(p.tasks),
+		Paused:         p.isPaused(),
 	}
 }
+
+// isPaused reports whether Pause is in effect
+func (p *Pool) isPaused() bool {
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+	return p.paused
+}
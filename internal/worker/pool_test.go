@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// sleepTask takes delay to process
+type sleepTask struct {
+	delay time.Duration
+}
+
+func (t sleepTask) Process() error {
+	time.Sleep(t.delay)
+	return nil
+}
+
+func (t sleepTask) ID() string {
+	return "sleep"
+}
+
+func TestPoolStopTimeout(t *testing.T) {
+	pool := NewPool(1, 1, 0)
+	pool.Start()
+	if err := pool.Submit(sleepTask{delay: 200 * time.Millisecond}); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	err := pool.StopTimeout(10 * time.Millisecond)
+	if !errors.Is(err, ErrStopTimeout) {
+		t.Fatalf("Expected ErrStopTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("StopTimeout took %v", elapsed)
+	}
+
+	// The slow task still finishes, and Results closes once it has
+	select {
+	case <-pool.Results():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the slow task's result")
+	}
+	select {
+	case _, ok := <-pool.Results():
+		if ok {
+			t.Error("Expected Results to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Results wasn't closed after the worker exited")
+	}
+
+	// A pool with no running tasks stops within the timeout
+	idle := NewPool(2, 1, 0)
+	idle.Start()
+	if err := idle.StopTimeout(time.Second); err != nil {
+		t.Errorf("Expected an idle pool to stop, got %v", err)
+	}
+}
+
+func TestPoolStopAfterStopTimeout(t *testing.T) {
+	pool := NewPool(1, 1, 0)
+	pool.Start()
+	if err := pool.Submit(sleepTask{delay: 100 * time.Millisecond}); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if err := pool.StopTimeout(10 * time.Millisecond); !errors.Is(err, ErrStopTimeout) {
+		t.Fatalf("Expected ErrStopTimeout, got %v", err)
+	}
+	if err := pool.StopTimeout(10 * time.Millisecond); !errors.Is(err, ErrStopTimeout) {
+		t.Fatalf("Expected a second StopTimeout to time out too, got %v", err)
+	}
+
+	// Stop waits for the same shutdown instead of closing the queue again
+	pool.Stop()
+}
+
+func TestPoolPauseResume(t *testing.T) {
+	pool := NewPool(1, 4, 0)
+	pool.Start()
+	defer pool.Stop()
+
+	// A task already running when the pool pauses still finishes
+	if err := pool.Submit(sleepTask{delay: 50 * time.Millisecond}); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	pool.Pause()
+	if !pool.GetStats().Paused {
+		t.Error("Expected stats to report the pool as paused")
+	}
+	select {
+	case <-pool.Results():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the running task to finish while paused")
+	}
+
+	// Tasks queued while paused wait for Resume
+	if err := pool.Submit(sleepTask{}); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	select {
+	case <-pool.Results():
+		t.Fatal("Expected no task to run while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pool.Resume()
+	if pool.GetStats().Paused {
+		t.Error("Expected stats to report the pool as running")
+	}
+	select {
+	case <-pool.Results():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the queued task to run after Resume")
+	}
+}
@@ -0,0 +1,76 @@
+// Package cache provides a bounded in-memory cache
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is a map holding at most a fixed number of entries, evicting the
+// least recently used one to make room for a new one
+// It is safe for concurrent use
+type LRU[K comparable, V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	// order lists the entries from most to least recently used
+	order   *list.List
+	entries map[K]*list.Element
+}
+
+// entry is the value stored in each list element
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New creates an LRU holding up to maxEntries entries; values below 1 are
+// treated as 1
+func New[K comparable, V any](maxEntries int) *LRU[K, V] {
+	return &LRU[K, V]{
+		maxEntries: max(maxEntries, 1),
+		order:      list.New(),
+		entries:    make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value stored for key and marks it as recently used
+func (c *LRU[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return value, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*entry[K, V]).value, true
+}
+
+// Add stores value for key, marking it as recently used, and reports
+// whether the least recently used entry was evicted to make room
+func (c *LRU[K, V]) Add(key K, value V) (evicted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(elem)
+		return false
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value})
+	if c.order.Len() <= c.maxEntries {
+		return false
+	}
+	oldest := c.order.Back()
+	c.order.Remove(oldest)
+	delete(c.entries, oldest.Value.(*entry[K, V]).key)
+	return true
+}
+
+// Len returns the number of entries held
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := New[string, int](3)
+	for i, key := range []string{"a", "b", "c"} {
+		if c.Add(key, i) {
+			t.Fatalf("Expected no eviction while adding %s", key)
+		}
+	}
+
+	// Reading a marks it as recently used, so b is now the oldest
+	if v, ok := c.Get("a"); !ok || v != 0 {
+		t.Fatalf("Expected a=0, got %d (found %v)", v, ok)
+	}
+	if !c.Add("d", 3) {
+		t.Error("Expected adding a fourth entry to evict one")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected b to be evicted as least recently used")
+	}
+
+	// Updating c refreshes it too, leaving a as the oldest
+	c.Add("c", 20)
+	c.Add("e", 4)
+	for key, want := range map[string]bool{"a": false, "c": true, "d": true, "e": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Expected %s present=%v, got %v", key, want, ok)
+		}
+	}
+	if v, _ := c.Get("c"); v != 20 {
+		t.Errorf("Expected the updated value 20 for c, got %d", v)
+	}
+	if c.Len() != 3 {
+		t.Errorf("Expected 3 entries, got %d", c.Len())
+	}
+
+	// A non-positive size still keeps the latest entry
+	one := New[int, int](0)
+	one.Add(1, 1)
+	one.Add(2, 2)
+	if _, ok := one.Get(2); !ok || one.Len() != 1 {
+		t.Errorf("Expected only the latest entry, got %d entries", one.Len())
+	}
+}
+
+func TestLRUConcurrent(t *testing.T) {
+	c := New[string, int](50)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := fmt.Sprintf("%d-%d", g, i%100)
+				c.Add(key, i)
+				c.Get(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if c.Len() != 50 {
+		t.Errorf("Expected the cache to stay at 50 entries, got %d", c.Len())
+	}
+}
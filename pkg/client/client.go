@@ -0,0 +1,235 @@
+// Package client provides a typed Go client for the file analytics HTTP API
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/retry"
+)
+
+// Errors matched by APIError through errors.Is, based on the status code
+var (
+	ErrBadRequest = errors.New("bad request")
+	ErrNotFound   = errors.New("not found")
+	ErrServer     = errors.New("server error")
+)
+
+// maxErrorBody limits how much of an error response is kept in APIError
+const maxErrorBody = 4096
+
+// APIError is returned when the server answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// Is maps the status code onto the package's sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// HashResult is the digest of a file computed by the server
+type HashResult struct {
+	File      string `json:"file"`
+	Algorithm string `json:"algorithm"`
+	Hash      string `json:"hash"`
+}
+
+// Metrics is a snapshot of the server's processing metrics
+type Metrics struct {
+	Processed uint64 `json:"processed"`
+	Errors    uint64 `json:"errors"`
+	// Duration is the average processing time, formatted like time.Duration
+	Duration string `json:"duration"`
+}
+
+// Client talks to the file analytics HTTP API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retry      retry.Policy
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the http.Client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry retries failed requests according to policy
+// Without an IsRetryable in the policy, server errors and failures to reach
+// the server are retried, while other API errors are returned at once
+func WithRetry(policy retry.Policy) Option {
+	return func(c *Client) {
+		if policy.IsRetryable == nil {
+			policy.IsRetryable = retryable
+		}
+		c.retry = policy
+	}
+}
+
+// retryable reports whether a request failing with err may succeed if sent again
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(apiErr, ErrServer)
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// NewClient creates a client for the API served at baseURL
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Page is one page of analyze results
+type Page struct {
+	Results []models.ProcessResult `json:"results"`
+	// Total is the number of files across all pages
+	Total int `json:"total"`
+	// Next is the offset of the following page, or 0 on the last page
+	Next int `json:"next,omitempty"`
+	// Workers is how many files the server processed concurrently
+	Workers int `json:"workers"`
+}
+
+// Analyze analyzes every supported file under the directory at path
+// Files that failed to process are returned with their Error set
+func (c *Client) Analyze(ctx context.Context, path string) ([]models.ProcessResult, error) {
+	page, err := c.AnalyzePage(ctx, path, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return page.Results, nil
+}
+
+// AnalyzePage analyzes up to limit files under path starting at offset,
+// in path order; a limit of 0 returns every remaining file
+// Iterate by passing page.Next as the offset until it is 0
+func (c *Client) AnalyzePage(ctx context.Context, path string, offset, limit int) (Page, error) {
+	query := url.Values{}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	endpoint := "/api/v1/analyze"
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var page Page
+	if err := c.do(ctx, http.MethodPost, endpoint, map[string]string{"path": path}, &page); err != nil {
+		return Page{}, err
+	}
+	return page, nil
+}
+
+// Hash returns the digest of file using algo ("sha256" when empty)
+func (c *Client) Hash(ctx context.Context, file, algo string) (HashResult, error) {
+	req := map[string]string{"file": file, "algorithm": algo}
+	var result HashResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/hash", req, &result); err != nil {
+		return HashResult{}, err
+	}
+	return result, nil
+}
+
+// Metrics returns the server's current processing metrics
+func (c *Client) Metrics(ctx context.Context) (Metrics, error) {
+	var metrics Metrics
+	if err := c.do(ctx, http.MethodGet, "/api/v1/metrics", nil, &metrics); err != nil {
+		return Metrics{}, err
+	}
+	return metrics, nil
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response
+// into out, retrying according to the client's retry policy
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	return retry.Do(ctx, c.retry, func() error {
+		return c.send(ctx, method, path, data, out)
+	})
+}
+
+// send makes a single attempt at a request whose JSON body is data, or none when nil
+func (c *Client) send(ctx context.Context, method, path string, data []byte, out interface{}) error {
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(message)),
+			RequestID:  resp.Header.Get("X-Request-ID"),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
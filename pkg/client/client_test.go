@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/internal/api"
+	"github.com/RaihanurRahman2022/file-analytics/internal/monitor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/retry"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/utils"
+)
+
+func newTestServer(t *testing.T) *Client {
+	t.Helper()
+	server := httptest.NewServer(api.NewHandlers(monitor.NewMetrics()).Router())
+	t.Cleanup(server.Close)
+	return NewClient(server.URL+"/", WithHTTPClient(server.Client()))
+}
+
+func TestClient(t *testing.T) {
+	c := newTestServer(t)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, []byte("hello client world\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	results, err := c.Analyze(ctx, dir)
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != file || results[0].Words != 3 {
+		t.Errorf("Unexpected analyze results: %+v", results)
+	}
+
+	hash, err := c.Hash(ctx, file, "")
+	if err != nil {
+		t.Fatalf("Failed to hash: %v", err)
+	}
+	want, _ := utils.HashFile(file)
+	if hash.Algorithm != "sha256" || hash.Hash != want {
+		t.Errorf("Expected sha256 %s, got %+v", want, hash)
+	}
+
+	metrics, err := c.Metrics(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	if metrics.Processed != 1 {
+		t.Errorf("Expected 1 processed file, got %d", metrics.Processed)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	c := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := c.Hash(ctx, filepath.Join(t.TempDir(), "missing.txt"), "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a not found APIError, got %v", err)
+	}
+	if apiErr.RequestID == "" {
+		t.Error("Expected the request ID to be captured")
+	}
+
+	if _, err := c.Hash(ctx, "any.txt", "md5"); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected bad request for unsupported algorithm, got %v", err)
+	}
+}
+
+func TestClientAnalyzePages(t *testing.T) {
+	c := newTestServer(t)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	for _, name := range []string{"c.txt", "a.txt", "b.txt", "d.txt", "e.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	var paths []string
+	offset := 0
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("Pagination did not terminate")
+		}
+		page, err := c.AnalyzePage(ctx, dir, offset, 2)
+		if err != nil {
+			t.Fatalf("Failed to analyze page: %v", err)
+		}
+		if page.Total != 5 {
+			t.Errorf("Expected total of 5, got %d", page.Total)
+		}
+		for _, result := range page.Results {
+			paths = append(paths, filepath.Base(result.Path))
+		}
+		if page.Next == 0 {
+			break
+		}
+		offset = page.Next
+	}
+
+	want := []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"}
+	if fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("Expected pages in path order %v, got %v", want, paths)
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch {
+		case r.URL.Path == "/api/v1/hash":
+			http.Error(w, "no such file", http.StatusNotFound)
+		case calls < 3:
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, `{"processed": 7}`)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, WithRetry(retry.Policy{BaseDelay: time.Millisecond, MaxAttempts: 3}))
+	metrics, err := c.Metrics(context.Background())
+	if err != nil || metrics.Processed != 7 || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %+v, %v after %d calls", metrics, err, calls)
+	}
+
+	// Client errors are not retried
+	calls = 0
+	if _, err := c.Hash(context.Background(), "missing.txt", ""); !errors.Is(err, ErrNotFound) || calls != 1 {
+		t.Errorf("Expected a single not found attempt, got %v after %d calls", err, calls)
+	}
+}
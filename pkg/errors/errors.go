@@ -43,15 +43,48 @@ type ProcessError struct {
 	Message string
 	Cause   error
 	Time    time.Time
+	// Line and Column locate the problem in the file, both counted from 1;
+	// zero when unknown
+	Line   int
+	Column int
+	// Offset is the problem's byte offset from the start of the file,
+	// counted from 0; it is only shown alongside a Line or when non-zero
+	Offset int64
 }
 
 // Error implements the error interface
 // Demonstrates error interface implementation
 func (e *ProcessError) Error() string {
+	where := fmt.Sprintf("in file '%s'", e.File)
+	if pos := e.Position(); pos != "" {
+		where += " at " + pos
+	}
 	if e.Cause != nil {
-		return fmt.Sprintf("%s: %s in file '%s': %v", e.Type, e.Message, e.File, e.Cause)
+		return fmt.Sprintf("%s: %s %s: %v", e.Type, e.Message, where, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s %s", e.Type, e.Message, where)
+}
+
+// WithPosition records where in the file the error was found and returns e
+func (e *ProcessError) WithPosition(line, column int, offset int64) *ProcessError {
+	e.Line, e.Column, e.Offset = line, column, offset
+	return e
+}
+
+// Position describes where in the file the error was found, such as
+// "line 3, column 7 (offset 41)", or returns "" when that is unknown
+func (e *ProcessError) Position() string {
+	if e.Line <= 0 {
+		if e.Offset > 0 {
+			return fmt.Sprintf("offset %d", e.Offset)
+		}
+		return ""
+	}
+	pos := fmt.Sprintf("line %d", e.Line)
+	if e.Column > 0 {
+		pos += fmt.Sprintf(", column %d", e.Column)
 	}
-	return fmt.Sprintf("%s: %s in file '%s'", e.Type, e.Message, e.File)
+	return pos + fmt.Sprintf(" (offset %d)", e.Offset)
 }
 
 // Unwrap implements error unwrapping
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+)
+
+// processResultAlias has the fields of ProcessResult without its JSON methods
+type processResultAlias ProcessResult
+
+// processResultJSON is the wire form of ProcessResult
+// The outer Error field shadows the embedded error value
+type processResultJSON struct {
+	processResultAlias
+	Error     string `json:"error,omitempty"`
+	ErrorType string `json:"error_type,omitempty"`
+	// ErrorLine, ErrorColumn and ErrorOffset locate format errors in the file
+	ErrorLine   int     `json:"error_line,omitempty"`
+	ErrorColumn int     `json:"error_column,omitempty"`
+	ErrorOffset int64   `json:"error_offset,omitempty"`
+	DurationMS  float64 `json:"duration_ms"`
+}
+
+// MarshalJSON renders the error as a string (with its type and position for
+// ProcessErrors) and the duration in milliseconds
+func (r ProcessResult) MarshalJSON() ([]byte, error) {
+	out := processResultJSON{
+		processResultAlias: processResultAlias(r),
+		DurationMS:         float64(r.Duration) / float64(time.Millisecond),
+	}
+
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+
+		var processErr *apperrors.ProcessError
+		if errors.As(r.Error, &processErr) {
+			out.ErrorType = processErr.Type.String()
+			out.ErrorLine, out.ErrorColumn, out.ErrorOffset = processErr.Line, processErr.Column, processErr.Offset
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON restores a result written by MarshalJSON
+// The error comes back as a plain error carrying the original message
+func (r *ProcessResult) UnmarshalJSON(data []byte) error {
+	var in processResultJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*r = ProcessResult(in.processResultAlias)
+	r.Duration = time.Duration(in.DurationMS * float64(time.Millisecond))
+	if in.Error != "" {
+		r.Error = errors.New(in.Error)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+)
+
+func TestProcessResultJSON(t *testing.T) {
+	result := ProcessResult{
+		FileInfo: FileInfo{Path: "data.json", Type: "json", Size: 42},
+		Lines:    3,
+		Error:    apperrors.NewProcessError(apperrors.ErrorTypeFormat, "data.json", "bad token").WithPosition(2, 5, 17),
+		Duration: 1500 * time.Microsecond,
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+
+	if raw["path"] != "data.json" {
+		t.Errorf("Expected path to be flattened, got %v", raw["path"])
+	}
+	if raw["duration_ms"] != 1.5 {
+		t.Errorf("Expected duration_ms 1.5, got %v", raw["duration_ms"])
+	}
+	if raw["error_type"] != "Format Error" {
+		t.Errorf("Expected error_type 'Format Error', got %v", raw["error_type"])
+	}
+	if raw["error_line"] != 2.0 || raw["error_column"] != 5.0 || raw["error_offset"] != 17.0 {
+		t.Errorf("Expected the error position in JSON, got line %v, column %v, offset %v",
+			raw["error_line"], raw["error_column"], raw["error_offset"])
+	}
+	if msg, _ := raw["error"].(string); !strings.Contains(msg, "bad token") {
+		t.Errorf("Expected error message in JSON, got %v", raw["error"])
+	}
+
+	// The JSON form must round-trip back into a result
+	var decoded ProcessResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if decoded.Path != result.Path || decoded.Lines != result.Lines || decoded.Duration != result.Duration {
+		t.Errorf("Round-trip mismatch: got %+v", decoded)
+	}
+	if decoded.Error == nil || decoded.Error.Error() != result.Error.Error() {
+		t.Errorf("Expected error to survive round-trip, got %v", decoded.Error)
+	}
+}
@@ -2,29 +2,172 @@ package models
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrFileTooLarge is the cause of errors for files over the size limit
+var ErrFileTooLarge = errors.New("file exceeds maximum size")
+
+// ErrFileVanished is the cause of errors for files deleted after the walk
+// listed them but before they could be read
+var ErrFileVanished = errors.New("file vanished")
+
+// FileError wraps a failure to stat or open path, where op names the step
+// A missing file becomes an IO ProcessError caused by ErrFileVanished, since
+// processors are only handed paths that existed when they were listed
+func FileError(path, op string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return apperrors.NewProcessError(apperrors.ErrorTypeIO, path, "file vanished before it could be read", ErrFileVanished)
+	}
+	return fmt.Errorf("failed to %s: %w", op, err)
+}
+
+// Buffer size limits for processors
+const (
+	// DefaultBufferSize is used when no positive buffer size is given
+	DefaultBufferSize = 4096
+
+	// DefaultMaxBufferSize is the default upper bound for processor buffers
+	DefaultMaxBufferSize = 1 << 20
 )
 
+// maxBufferSize holds the configured upper bound for processor buffers
+var maxBufferSize atomic.Int64
+
+func init() {
+	maxBufferSize.Store(DefaultMaxBufferSize)
+}
+
+// SetMaxBufferSize configures the largest buffer a processor may allocate
+// Non-positive values restore the default
+func SetMaxBufferSize(size int) {
+	if size <= 0 {
+		size = DefaultMaxBufferSize
+	}
+	maxBufferSize.Store(int64(size))
+}
+
+// MaxBufferSize returns the configured upper bound for processor buffers
+func MaxBufferSize() int {
+	return int(maxBufferSize.Load())
+}
+
 // FileInfo represents metadata about a processed file
 // Demonstrates struct usage
 type FileInfo struct {
-	Path      string
-	Size      int64
-	Modified  time.Time
-	Processed time.Time
-	Type      string
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Modified  time.Time `json:"modified"`
+	Processed time.Time `json:"processed"`
+	Type      string    `json:"type"`
+}
+
+// MaxAnomalies caps how many anomalies are kept per file
+// Further anomalies are only counted, so a badly broken file stays cheap
+const MaxAnomalies = 20
+
+// Anomaly describes a data-quality problem found while processing a file
+type Anomaly struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+	// Line is the 1-based line number, or 0 when not applicable
+	Line int `json:"line,omitempty"`
+}
+
+// MaxTopLevelKeys caps how many distinct top-level JSON keys are kept per file
+const MaxTopLevelKeys = 1000
+
+// JSONStructure describes the shape of the documents in a JSON file
+type JSONStructure struct {
+	// MaxDepth is the deepest nesting of objects and arrays; a top-level
+	// scalar has depth 0
+	MaxDepth int `json:"max_depth"`
+	Objects  int `json:"objects"`
+	Arrays   int `json:"arrays"`
+	Scalars  int `json:"scalars"`
+	// KeyDepths counts object keys by depth, top-level keys being at depth 1
+	KeyDepths map[int]int `json:"key_depths,omitempty"`
+	// TopLevelKeys lists the sorted keys of top-level objects, when enabled
+	TopLevelKeys []string `json:"top_level_keys,omitempty"`
 }
 
 // ProcessResult represents the result of file processing
 // Demonstrates struct composition
+// Error and Duration are rendered by MarshalJSON
 type ProcessResult struct {
 	FileInfo
-	Lines    int
-	Words    int
-	Bytes    int
-	Error    error
-	Duration time.Duration
+	Lines    int           `json:"lines"`
+	Words    int           `json:"words"`
+	Bytes    int           `json:"bytes"`
+	Error    error         `json:"-"`
+	Duration time.Duration `json:"-"`
+
+	// HasBOM reports that a leading UTF-8 byte order mark was stripped
+	HasBOM bool `json:"has_bom,omitempty"`
+
+	// Hash is the hex digest of the file, set only when hashing is enabled
+	Hash string `json:"hash,omitempty"`
+
+	// Compression names the format a compressed file was read through
+	Compression string `json:"compression,omitempty"`
+
+	// HeaderAssumed reports that the first row of a CSV file was treated as
+	// a header rather than counted as data
+	HeaderAssumed bool `json:"header_assumed,omitempty"`
+
+	// IsEmpty reports a file with no content or only whitespace
+	IsEmpty bool `json:"is_empty,omitempty"`
+
+	// Line deduplication counts, set only when the processor option is enabled
+	UniqueLines    int `json:"unique_lines,omitempty"`
+	DuplicateLines int `json:"duplicate_lines,omitempty"`
+
+	// Line length statistics in runes, set only when a line length
+	// threshold is configured
+	MaxLineLength      int     `json:"max_line_length,omitempty"`
+	AvgLineLength      float64 `json:"avg_line_length,omitempty"`
+	LinesOverThreshold int     `json:"lines_over_threshold,omitempty"`
+
+	// Indentation of text files, set only when the processor option is
+	// enabled: the dominant style ("tabs" or "spaces"), its width in spaces
+	// for space indentation, and the lines indented otherwise
+	IndentStyle           string `json:"indent_style,omitempty"`
+	IndentWidth           int    `json:"indent_width,omitempty"`
+	MismatchedIndentLines int    `json:"mismatched_indent_lines,omitempty"`
+
+	// Record validation counts, set for NDJSON files and when a JSON schema
+	// is configured
+	ValidRecords   int `json:"valid_records,omitempty"`
+	InvalidRecords int `json:"invalid_records,omitempty"`
+
+	// JSONStructure is set for JSON files when structure stats are enabled
+	JSONStructure *JSONStructure `json:"json_structure,omitempty"`
+
+	// Extra holds processor-specific counts, such as rows per spreadsheet sheet
+	Extra map[string]int `json:"extra,omitempty"`
+
+	// Anomalies lists data-quality problems that didn't stop processing
+	// AnomalyCount also includes those dropped past MaxAnomalies
+	Anomalies    []Anomaly `json:"anomalies,omitempty"`
+	AnomalyCount int       `json:"anomaly_count,omitempty"`
+}
+
+// AddAnomaly records a data-quality problem, keeping at most MaxAnomalies
+// Pass a line of 0 when the problem has no line number
+func (r *ProcessResult) AddAnomaly(kind, detail string, line int) {
+	r.AnomalyCount++
+	if len(r.Anomalies) < MaxAnomalies {
+		r.Anomalies = append(r.Anomalies, Anomaly{Kind: kind, Detail: detail, Line: line})
+	}
 }
 
 // Processor defines the interface for file processors
@@ -48,19 +191,87 @@ type Processor interface {
 type BaseProcessor struct {
 	name       string
 	bufferSize int
+	// maxFileSize rejects larger files before reading them (0 means unlimited)
+	maxFileSize int64
+	// hashAlgorithm names the digest computed while reading ("" disables it)
+	hashAlgorithm string
+	// Demonstrates sync.Pool for reusing read buffers across files
+	buffers sync.Pool
 }
 
 // NewBaseProcessor demonstrates a constructor function
 func NewBaseProcessor(name string, bufferSize int) *BaseProcessor {
 	// Demonstrates if/else with single line
 	if bufferSize <= 0 {
-		bufferSize = 4096
+		bufferSize = DefaultBufferSize
+	} else if limit := MaxBufferSize(); bufferSize > limit {
+		logrus.Warnf("Buffer size %d for %s processor exceeds maximum, clamping to %d", bufferSize, name, limit)
+		bufferSize = limit
 	}
 
-	return &BaseProcessor{
+	p := &BaseProcessor{
 		name:       name,
 		bufferSize: bufferSize,
 	}
+	p.buffers.New = func() interface{} {
+		// Pool pointers so Put doesn't allocate a slice header each time
+		buf := make([]byte, p.bufferSize)
+		return &buf
+	}
+	return p
+}
+
+// SetMaxFileSize limits the size of files the processor will read
+// Zero or a negative value means unlimited
+func (p *BaseProcessor) SetMaxFileSize(size int64) {
+	p.maxFileSize = size
+}
+
+// SetHashAlgorithm makes the processor compute a digest of each file in the
+// same pass that analyzes it, stored in ProcessResult.Hash
+// An empty algorithm turns hashing off; unsupported ones fail in Process
+func (p *BaseProcessor) SetHashAlgorithm(algo string) {
+	p.hashAlgorithm = algo
+}
+
+// HashAlgorithm returns the configured digest algorithm, or "" when off
+func (p *BaseProcessor) HashAlgorithm() string {
+	return p.hashAlgorithm
+}
+
+// MaxFileSize returns the size limit in bytes, or 0 when unlimited
+func (p *BaseProcessor) MaxFileSize() int64 {
+	if p.maxFileSize < 0 {
+		return 0
+	}
+	return p.maxFileSize
+}
+
+// CheckFileSize returns a validation error when size exceeds the limit
+// Processors call it right after stat so oversized files are never opened
+func (p *BaseProcessor) CheckFileSize(path string, size int64) error {
+	if p.maxFileSize > 0 && size > p.maxFileSize {
+		return apperrors.NewProcessError(apperrors.ErrorTypeValidation, path,
+			fmt.Sprintf("size %d exceeds limit of %d bytes", size, p.maxFileSize), ErrFileTooLarge)
+	}
+	return nil
+}
+
+// BufferSize returns the validated buffer size used for reads
+func (p *BaseProcessor) BufferSize() int {
+	return p.bufferSize
+}
+
+// AcquireBuffer takes a read buffer from the processor's pool
+// Callers must return it with ReleaseBuffer and not keep references to it
+func (p *BaseProcessor) AcquireBuffer() *[]byte {
+	return p.buffers.Get().(*[]byte)
+}
+
+// ReleaseBuffer returns a buffer obtained from AcquireBuffer to the pool
+// The buffer must not be used after it has been released
+func (p *BaseProcessor) ReleaseBuffer(buf *[]byte) {
+	p.buffers.Put(buf)
 }
 
 // Name implements the Processor interface
@@ -68,11 +279,14 @@ func (p *BaseProcessor) Name() string {
 	return p.name
 }
 
-// readLines demonstrates working with io.Reader and error handling
-func (p *BaseProcessor) readLines(reader io.Reader) (lines, words, bytes int, err error) {
-	// Create a buffer for reading
-	// Demonstrates array usage
-	buf := make([]byte, p.bufferSize)
+// ReadLines counts lines, words, and bytes in a reader
+// It is the single shared counting loop used by the text-oriented processors
+// Demonstrates working with io.Reader and error handling
+func (p *BaseProcessor) ReadLines(reader io.Reader) (lines, words, bytes int, err error) {
+	// Borrow a pooled buffer for reading; only counts escape this function
+	pooled := p.AcquireBuffer()
+	defer p.ReleaseBuffer(pooled)
+	buf := *pooled
 
 	// Variables to track state
 	var (
@@ -83,15 +297,9 @@ func (p *BaseProcessor) readLines(reader io.Reader) (lines, words, bytes int, er
 	// Read the file in chunks
 	// Demonstrates for loop with multiple conditions
 	for {
+		// A reader may return data together with an error, including io.EOF,
+		// so the bytes read are counted before the error is handled
 		count, err = reader.Read(buf)
-		if err != nil {
-			if err == io.EOF {
-				err = nil
-				break
-			}
-			return
-		}
-
 		bytes += count
 
 		// Process the buffer
@@ -112,6 +320,14 @@ func (p *BaseProcessor) readLines(reader io.Reader) (lines, words, bytes int, er
 				inWord = true
 			}
 		}
+
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			return
+		}
 	}
 
 	// Adjust final counts
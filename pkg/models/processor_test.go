@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// dataErrReader returns its data and err from a single Read
+type dataErrReader struct {
+	data string
+	err  error
+	done bool
+}
+
+func (r *dataErrReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, r.err
+	}
+	r.done = true
+	return copy(p, r.data), r.err
+}
+
+func TestReadLinesDataWithError(t *testing.T) {
+	p := NewBaseProcessor("test", 4096)
+
+	// The last chunk arrives together with io.EOF
+	lines, words, bytes, err := p.ReadLines(iotest.DataErrReader(strings.NewReader("one two\nthree\n")))
+	if err != nil {
+		t.Fatalf("Failed to read lines: %v", err)
+	}
+	if lines != 3 || words != 3 || bytes != 14 {
+		t.Errorf("Expected 3 lines, 3 words and 14 bytes, got %d, %d and %d", lines, words, bytes)
+	}
+
+	// Bytes read before a failure are still counted
+	failure := errors.New("connection reset")
+	_, words, bytes, err = p.ReadLines(&dataErrReader{data: "one two", err: failure})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+	if words != 2 || bytes != 7 {
+		t.Errorf("Expected 2 words and 7 bytes, got %d and %d", words, bytes)
+	}
+}
@@ -0,0 +1,86 @@
+// Package retry runs operations again after transient failures, waiting
+// with exponential backoff and jitter between attempts
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Policy controls how often and how patiently an operation is retried
+type Policy struct {
+	// BaseDelay is the wait before the second attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts; 0 means no cap
+	MaxDelay time.Duration
+	// Multiplier grows the wait after each attempt; values below 1 mean 2
+	Multiplier float64
+	// MaxAttempts is the total number of attempts, including the first;
+	// values below 1 mean a single attempt
+	MaxAttempts int
+	// IsRetryable decides whether an error is worth another attempt;
+	// nil retries every error
+	IsRetryable func(error) bool
+}
+
+// Delay returns the wait before the given retry, counting from 1, without jitter
+func (p Policy) Delay(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(p.BaseDelay)
+	for i := 1; i < retry; i++ {
+		delay *= multiplier
+		if p.MaxDelay > 0 && delay >= float64(p.MaxDelay) {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// Do calls fn until it succeeds, returns an error the policy doesn't retry,
+// or the attempts run out, in which case the last error is returned as is
+// Each wait is a random duration between half and all of the policy's delay,
+// so callers failing together don't retry in lockstep
+// Cancelling ctx ends the wait early; the result then wraps both ctx.Err()
+// and the last error
+func Do(ctx context.Context, policy Policy, fn func() error) error {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= attempts || (policy.IsRetryable != nil && !policy.IsRetryable(err)) {
+			return err
+		}
+
+		timer := time.NewTimer(jitter(policy.Delay(attempt)))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry stopped after %d attempts: %w: last error: %w", attempt, ctx.Err(), err)
+		}
+	}
+}
+
+// jitter picks a random duration in [delay/2, delay]
+func jitter(delay time.Duration) time.Duration {
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,92 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	policy := Policy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, Multiplier: 3}
+	want := []time.Duration{10 * time.Millisecond, 30 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	for i, expected := range want {
+		if got := policy.Delay(i + 1); got != expected {
+			t.Errorf("Retry %d: expected %v, got %v", i+1, expected, got)
+		}
+	}
+
+	// A missing multiplier doubles the delay
+	if got := (Policy{BaseDelay: time.Second}).Delay(3); got != 4*time.Second {
+		t.Errorf("Expected 4s, got %v", got)
+	}
+}
+
+func TestDo(t *testing.T) {
+	transient := errors.New("transient")
+	permanent := errors.New("permanent")
+	policy := Policy{
+		BaseDelay:   time.Millisecond,
+		MaxAttempts: 3,
+		IsRetryable: func(err error) bool { return errors.Is(err, transient) },
+	}
+
+	// Succeeds once the transient failures stop
+	calls := 0
+	err := Do(context.Background(), policy, func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d", err, calls)
+	}
+
+	// Gives up after MaxAttempts with the last error unchanged
+	calls = 0
+	err = Do(context.Background(), policy, func() error {
+		calls++
+		return transient
+	})
+	if err != transient || calls != 3 {
+		t.Errorf("Expected the transient error after 3 attempts, got %v after %d", err, calls)
+	}
+
+	// Errors the policy doesn't retry are returned at once
+	calls = 0
+	err = Do(context.Background(), policy, func() error {
+		calls++
+		return permanent
+	})
+	if err != permanent || calls != 1 {
+		t.Errorf("Expected one attempt for a permanent error, got %v after %d", err, calls)
+	}
+
+	// The zero policy makes a single attempt
+	calls = 0
+	Do(context.Background(), Policy{}, func() error {
+		calls++
+		return transient
+	})
+	if calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+}
+
+func TestDoCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	transient := errors.New("transient")
+	policy := Policy{BaseDelay: time.Hour, MaxAttempts: 5}
+
+	calls := 0
+	err := Do(ctx, policy, func() error {
+		calls++
+		cancel()
+		return transient
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, transient) || calls != 1 {
+		t.Errorf("Expected cancellation during the wait, got %v after %d attempts", err, calls)
+	}
+}
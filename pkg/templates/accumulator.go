@@ -0,0 +1,179 @@
+package templates
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// StatsAccumulator builds report statistics incrementally as results arrive,
+// so a partial report is available at any point during a run
+type StatsAccumulator struct {
+	mu        sync.Mutex
+	files     []FileInfo
+	stats     Statistics
+	errors    []string
+	totalTime time.Duration
+	started   time.Time
+	finished  time.Time
+	// emptyCategory files empty results under their own "empty" type
+	emptyCategory bool
+	// bySize and byWords track the top files for SetTopN
+	bySize  topN
+	byWords topN
+}
+
+// NewStatsAccumulator creates an empty accumulator and marks the run start
+func NewStatsAccumulator() *StatsAccumulator {
+	return &StatsAccumulator{
+		started: time.Now(),
+	}
+}
+
+// SetEmptyCategory counts empty and whitespace-only files under an "empty"
+// type instead of their processor type
+func (a *StatsAccumulator) SetEmptyCategory(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.emptyCategory = enabled
+}
+
+// SetTopN keeps the n largest files and the n files with the most words,
+// for the statistics' TopBySize and TopByWords; 0, the default, keeps none
+// Memory stays proportional to n however many files are added
+// It must be called before any result is added
+func (a *StatsAccumulator) SetTopN(n int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.bySize.n, a.byWords.n = n, n
+}
+
+// Add records a processing result, counting it as an error when it failed
+func (a *StatsAccumulator) Add(result models.ProcessResult) {
+	if result.Error != nil {
+		a.AddError(result.Path, result.Error)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.files = append(a.files, FileInfo{
+		Name:           result.Path,
+		Size:           result.Size,
+		Type:           result.Type,
+		WordCount:      result.Words,
+		LineCount:      result.Lines,
+		Hash:           result.Hash,
+		ProcessingTime: result.Duration,
+		Anomalies:      result.Anomalies,
+		AnomalyCount:   result.AnomalyCount,
+	})
+	a.stats.AnomalyCount += result.AnomalyCount
+
+	fileType := result.Type
+	if result.IsEmpty {
+		a.stats.EmptyCount++
+		if a.emptyCategory {
+			fileType = "empty"
+		}
+	}
+
+	if a.stats.TypeCounts == nil {
+		a.stats.TypeCounts = make(map[string]int)
+	}
+	a.stats.TypeCounts[fileType]++
+
+	a.stats.recordSize(result.Path, result.Size)
+	a.bySize.add(RankedFile{Name: result.Path, Value: result.Size})
+	a.byWords.add(RankedFile{Name: result.Path, Value: int64(result.Words)})
+	a.stats.TotalFiles++
+	a.stats.SuccessCount++
+	a.stats.TotalSize += result.Size
+	a.stats.TotalWords += result.Words
+	a.stats.TotalLines += result.Lines
+	a.totalTime += result.Duration
+	a.stats.AverageTime = a.totalTime / time.Duration(a.stats.SuccessCount)
+}
+
+// AddError records a file that failed to process
+func (a *StatsAccumulator) AddError(path string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stats.TotalFiles++
+	a.stats.ErrorCount++
+	a.errors = append(a.errors, fmt.Sprintf("%s: %v", path, err))
+}
+
+// Finish marks the end of the run, freezing the wall-clock time used for
+// throughput; until then throughput is measured up to the current time
+func (a *StatsAccumulator) Finish() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.finished.IsZero() {
+		a.finished = time.Now()
+	}
+}
+
+// elapsed returns the run's wall-clock time so far
+// The caller must hold a.mu
+func (a *StatsAccumulator) elapsed() time.Duration {
+	if a.finished.IsZero() {
+		return time.Since(a.started)
+	}
+	return a.finished.Sub(a.started)
+}
+
+// AddVanished records a file that was deleted before it could be processed
+// Such files are neither successes nor errors
+func (a *StatsAccumulator) AddVanished(path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stats.VanishedCount++
+}
+
+// Statistics returns a snapshot of the statistics gathered so far
+func (a *StatsAccumulator) Statistics() Statistics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.snapshot()
+}
+
+// snapshot copies the statistics so callers can't observe later updates
+// The caller must hold a.mu
+func (a *StatsAccumulator) snapshot() Statistics {
+	stats := a.stats
+	stats.SetThroughput(a.elapsed())
+	if a.stats.TypeCounts != nil {
+		stats.TypeCounts = make(map[string]int, len(a.stats.TypeCounts))
+		for name, count := range a.stats.TypeCounts {
+			stats.TypeCounts[name] = count
+		}
+	}
+	stats.TopBySize = a.bySize.sorted()
+	stats.TopByWords = a.byWords.sorted()
+	return stats
+}
+
+// Report returns a snapshot of everything gathered so far as report data
+func (a *StatsAccumulator) Report(title string) ReportData {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	files := make([]FileInfo, len(a.files))
+	copy(files, a.files)
+	errs := make([]string, len(a.errors))
+	copy(errs, a.errors)
+
+	return ReportData{
+		Title:          title,
+		Timestamp:      time.Now(),
+		Files:          files,
+		Statistics:     a.snapshot(),
+		Errors:         errs,
+		ProcessingTime: a.elapsed(),
+	}
+}
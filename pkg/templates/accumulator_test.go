@@ -0,0 +1,208 @@
+package templates
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+func TestStatsAccumulator(t *testing.T) {
+	acc := NewStatsAccumulator()
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "a.txt", Type: "text", Size: 10}, Lines: 2, Words: 4, Duration: time.Millisecond})
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "b.txt", Type: "text", Size: 20}, Lines: 1, Words: 1, Duration: 3 * time.Millisecond})
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "c.json", Type: "json", Size: 5}, Lines: 1})
+	acc.AddError("d.csv", errors.New("bad row"))
+
+	stats := acc.Statistics()
+	if stats.TotalFiles != 4 || stats.SuccessCount != 3 || stats.ErrorCount != 1 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if stats.TotalSize != 35 || stats.TotalWords != 5 || stats.TotalLines != 4 {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+	if stats.TypeCounts["text"] != 2 || stats.TypeCounts["json"] != 1 {
+		t.Errorf("Unexpected type counts: %v", stats.TypeCounts)
+	}
+	if stats.MinSize != 5 || stats.MaxSize != 20 || stats.AvgSize != 11 || stats.LargestFile != "b.txt" {
+		t.Errorf("Unexpected size distribution: %+v", stats)
+	}
+
+	// Snapshots must not change when more results arrive
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "e.txt", Type: "text"}})
+	if stats.TypeCounts["text"] != 2 {
+		t.Error("Statistics snapshot was modified by a later Add")
+	}
+
+	report, err := GenerateMarkdownReport(acc.Report("Test Report"))
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	if !strings.Contains(report, "## File Types") || !strings.Contains(report, "| text | 3 |") {
+		t.Errorf("Expected file type section in report:\n%s", report)
+	}
+	if !strings.Contains(report, "| 0 / 8 / 20 bytes |") || !strings.Contains(report, "| Largest File | b.txt |") {
+		t.Errorf("Expected size distribution in report:\n%s", report)
+	}
+}
+
+func TestReportAnomalies(t *testing.T) {
+	acc := NewStatsAccumulator()
+	result := models.ProcessResult{FileInfo: models.FileInfo{Path: "rows.csv", Type: "csv"}}
+	result.AddAnomaly("field_count", "row has 2 fields, header has 3", 4)
+	acc.Add(result)
+
+	if stats := acc.Statistics(); stats.AnomalyCount != 1 {
+		t.Errorf("Expected 1 anomaly in statistics, got %d", stats.AnomalyCount)
+	}
+
+	report, err := GenerateMarkdownReport(acc.Report("Anomalies"))
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	if !strings.Contains(report, "| rows.csv | field_count | 4 | row has 2 fields, header has 3 |") {
+		t.Errorf("Expected anomaly row in report:\n%s", report)
+	}
+
+	html, err := GenerateHTMLReport(acc.Report("Anomalies"))
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	if !strings.Contains(html, "<h2>Anomalies</h2>") {
+		t.Errorf("Expected anomalies section in HTML report")
+	}
+}
+
+func TestSummaryOnlyReport(t *testing.T) {
+	acc := NewStatsAccumulator()
+	result := models.ProcessResult{FileInfo: models.FileInfo{Path: "rows.csv", Type: "csv", Size: 12}}
+	result.AddAnomaly("field_count", "row has 2 fields, header has 3", 4)
+	acc.Add(result)
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "big.txt", Type: "text", Size: 40}})
+	data := acc.Report("Summary")
+	data.SummaryOnly = true
+
+	md, err := GenerateMarkdownReport(data)
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	html, err := GenerateHTMLReport(data)
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	json, err := GenerateJSONReport(data)
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+
+	for format, report := range map[string]string{"markdown": md, "html": html, "json": json} {
+		if strings.Contains(report, "rows.csv") {
+			t.Errorf("Expected no per-file rows in the %s report:\n%s", format, report)
+		}
+		if !strings.Contains(report, "csv") {
+			t.Errorf("Expected the type histogram in the %s report", format)
+		}
+	}
+	if !strings.Contains(md, "| Total Files | 2 |") {
+		t.Errorf("Expected statistics in the summary report:\n%s", md)
+	}
+}
+
+func TestStatsAccumulatorEmptyFiles(t *testing.T) {
+	acc := NewStatsAccumulator()
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "a.txt", Type: "text"}, IsEmpty: true})
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "b.txt", Type: "text"}, Words: 3})
+
+	if stats := acc.Statistics(); stats.EmptyCount != 1 || stats.TypeCounts["text"] != 2 {
+		t.Errorf("Expected empty file counted under its type, got %+v", stats)
+	}
+
+	// With the category enabled empty files get their own type
+	acc.SetEmptyCategory(true)
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "c.json", Type: "json"}, IsEmpty: true})
+	stats := acc.Statistics()
+	if stats.EmptyCount != 2 || stats.TypeCounts["empty"] != 1 || stats.TypeCounts["json"] != 0 {
+		t.Errorf("Expected empty category, got %+v", stats)
+	}
+}
+
+func TestThroughput(t *testing.T) {
+	stats := Statistics{TotalSize: 5_000_000, SuccessCount: 10}
+	stats.SetThroughput(2 * time.Second)
+	if stats.MBPerSecond != 2.5 || stats.FilesPerSecond != 5 {
+		t.Errorf("Expected 2.5 MB/s and 5 files/s, got %v and %v", stats.MBPerSecond, stats.FilesPerSecond)
+	}
+
+	acc := NewStatsAccumulator()
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "a.txt", Type: "text", Size: 1000}})
+	acc.Finish()
+
+	// The wall-clock time is frozen once the run has finished
+	first := acc.Statistics()
+	time.Sleep(5 * time.Millisecond)
+	if second := acc.Statistics(); second.WallTime != first.WallTime || first.FilesPerSecond <= 0 {
+		t.Errorf("Expected frozen, positive throughput: %+v vs %+v", first, second)
+	}
+
+	report, err := GenerateJSONReport(acc.Report("Throughput"))
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	if !strings.Contains(report, `"MBPerSecond"`) || !strings.Contains(report, `"FilesPerSecond"`) {
+		t.Errorf("Expected throughput in JSON report:\n%s", report)
+	}
+}
+
+func TestSplitByType(t *testing.T) {
+	acc := NewStatsAccumulator()
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "a.txt", Type: "text", Size: 10}, Lines: 2, Words: 4, Duration: time.Millisecond})
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "b.txt", Type: "text", Size: 20}, Lines: 1, Words: 1, Duration: 3 * time.Millisecond})
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "c.json", Type: "json", Size: 5}, Lines: 1})
+	acc.AddError("d.csv", errors.New("bad row"))
+
+	reports := acc.Report("Audit").SplitByType()
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 per-type reports, got %d", len(reports))
+	}
+
+	text := reports["text"]
+	if len(text.Files) != 2 || text.Statistics.TotalFiles != 2 || text.Statistics.TotalSize != 30 {
+		t.Errorf("Unexpected text report: %+v", text.Statistics)
+	}
+	if text.Statistics.MinSize != 10 || text.Statistics.AvgSize != 15 || text.Statistics.LargestFile != "b.txt" {
+		t.Errorf("Unexpected text size distribution: %+v", text.Statistics)
+	}
+	if text.Statistics.AverageTime != 2*time.Millisecond {
+		t.Errorf("Expected average time of 2ms, got %v", text.Statistics.AverageTime)
+	}
+	if text.Title != "Audit (text files)" || len(text.Errors) != 0 {
+		t.Errorf("Unexpected title or errors: %q %v", text.Title, text.Errors)
+	}
+	if json := reports["json"]; json.Statistics.TotalLines != 1 || json.Statistics.TypeCounts["json"] != 1 {
+		t.Errorf("Unexpected json report: %+v", json.Statistics)
+	}
+}
+
+func TestEncodeJSONReport(t *testing.T) {
+	acc := NewStatsAccumulator()
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "a.txt", Type: "text"}, Lines: 2})
+	data := acc.Report("Compact")
+
+	compact, err := EncodeJSONReport(data, false)
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	if strings.Contains(compact, "\n") {
+		t.Errorf("Expected a single-line report, got:\n%s", compact)
+	}
+
+	pretty, err := EncodeJSONReport(data, true)
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	if !strings.Contains(pretty, "\n  \"Title\": \"Compact\"") {
+		t.Errorf("Expected a two-space indented report, got:\n%s", pretty)
+	}
+}
@@ -2,18 +2,25 @@ package templates
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"html/template"
 	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
 )
 
 // ReportData represents the data structure for report generation
 type ReportData struct {
 	Title          string
 	Timestamp      time.Time
-	Files          []FileInfo
+	Files          []FileInfo `json:",omitempty"`
 	Statistics     Statistics
 	Errors         []string
 	ProcessingTime time.Duration
+	// SummaryOnly leaves the per-file table and anomaly list out of the
+	// report, keeping only the statistics, type histogram and errors
+	SummaryOnly bool `json:",omitempty"`
 }
 
 // FileInfo represents information about a processed file
@@ -25,6 +32,10 @@ type FileInfo struct {
 	LineCount      int
 	Hash           string
 	ProcessingTime time.Duration
+	// Anomalies are the data-quality problems kept for the file;
+	// AnomalyCount includes any dropped past models.MaxAnomalies
+	Anomalies    []models.Anomaly
+	AnomalyCount int
 }
 
 // Statistics represents overall processing statistics
@@ -36,6 +47,29 @@ type Statistics struct {
 	SuccessCount int
 	ErrorCount   int
 	AverageTime  time.Duration
+	// EmptyCount counts processed files that were empty or whitespace-only
+	EmptyCount int
+	// VanishedCount counts files deleted between listing and processing;
+	// they are not included in TotalFiles
+	VanishedCount int
+	// AnomalyCount totals the data-quality anomalies across all files
+	AnomalyCount int
+	// Size distribution of successfully processed files, in bytes;
+	// LargestFile is the path of the file of MaxSize
+	MinSize     int64
+	MaxSize     int64
+	AvgSize     int64
+	LargestFile string
+	// Throughput of successfully processed files over the run's wall-clock time
+	WallTime       time.Duration
+	MBPerSecond    float64
+	FilesPerSecond float64
+	// TypeCounts tallies successfully processed files by processor type
+	TypeCounts map[string]int
+	// TopBySize and TopByWords list the largest files and those with the
+	// most words, highest first, when the accumulator tracks them
+	TopBySize  []RankedFile `json:",omitempty"`
+	TopByWords []RankedFile `json:",omitempty"`
 }
 
 // HTMLTemplate is the template for HTML reports
@@ -70,10 +104,51 @@ const HTMLTemplate = `
             <tr><th>Total Lines</th><td>{{.Statistics.TotalLines}}</td></tr>
             <tr><th>Success Count</th><td>{{.Statistics.SuccessCount}}</td></tr>
             <tr><th>Error Count</th><td>{{.Statistics.ErrorCount}}</td></tr>
+            {{if .Statistics.EmptyCount}}<tr><th>Empty Files</th><td>{{.Statistics.EmptyCount}}</td></tr>{{end}}
+            {{if .Statistics.SuccessCount}}<tr><th>File Size (min / avg / max)</th><td>{{.Statistics.MinSize}} / {{.Statistics.AvgSize}} / {{.Statistics.MaxSize}} bytes</td></tr>
+            <tr><th>Largest File</th><td>{{.Statistics.LargestFile}}</td></tr>{{end}}
             <tr><th>Average Processing Time</th><td>{{.Statistics.AverageTime}}</td></tr>
+            <tr><th>Throughput</th><td>{{printf "%.2f" .Statistics.MBPerSecond}} MB/s, {{printf "%.1f" .Statistics.FilesPerSecond}} files/s</td></tr>
+        </table>
+    </div>
+
+    {{if .Statistics.TypeCounts}}
+    <div class="stats">
+        <h2>File Types</h2>
+        <table>
+            <tr><th>Type</th><th>Files</th></tr>
+            {{range $type, $count := .Statistics.TypeCounts}}
+            <tr><td>{{$type}}</td><td>{{$count}}</td></tr>
+            {{end}}
+        </table>
+    </div>
+    {{end}}
+
+    {{if .Statistics.TopBySize}}
+    <div class="stats">
+        <h2>Largest Files</h2>
+        <table>
+            <tr><th>Name</th><th>Size</th></tr>
+            {{range .Statistics.TopBySize}}
+            <tr><td>{{.Name}}</td><td>{{.Value}} bytes</td></tr>
+            {{end}}
         </table>
     </div>
+    {{end}}
 
+    {{if .Statistics.TopByWords}}
+    <div class="stats">
+        <h2>Most Words</h2>
+        <table>
+            <tr><th>Name</th><th>Words</th></tr>
+            {{range .Statistics.TopByWords}}
+            <tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>
+            {{end}}
+        </table>
+    </div>
+    {{end}}
+
+    {{if not .SummaryOnly}}
     <div class="file-list">
         <h2>Processed Files</h2>
         <table>
@@ -85,6 +160,7 @@ const HTMLTemplate = `
                 <th>Lines</th>
                 <th>Hash</th>
                 <th>Processing Time</th>
+                <th>Anomalies</th>
             </tr>
             {{range .Files}}
             <tr>
@@ -95,10 +171,24 @@ const HTMLTemplate = `
                 <td>{{.LineCount}}</td>
                 <td>{{.Hash}}</td>
                 <td>{{.ProcessingTime}}</td>
+                <td>{{.AnomalyCount}}</td>
             </tr>
             {{end}}
         </table>
     </div>
+    {{end}}
+
+    {{if and .Statistics.AnomalyCount (not .SummaryOnly)}}
+    <div class="anomaly-list">
+        <h2>Anomalies</h2>
+        <table>
+            <tr><th>File</th><th>Kind</th><th>Line</th><th>Detail</th></tr>
+            {{range .Files}}{{$name := .Name}}{{range .Anomalies}}
+            <tr><td>{{$name}}</td><td>{{.Kind}}</td><td>{{if .Line}}{{.Line}}{{end}}</td><td>{{.Detail}}</td></tr>
+            {{end}}{{end}}
+        </table>
+    </div>
+    {{end}}
 
     {{if .Errors}}
     <div class="error-list">
@@ -131,14 +221,46 @@ Generated at: {{.Timestamp.Format "2006-01-02 15:04:05"}}
 | Total Lines | {{.Statistics.TotalLines}} |
 | Success Count | {{.Statistics.SuccessCount}} |
 | Error Count | {{.Statistics.ErrorCount}} |
-| Average Processing Time | {{.Statistics.AverageTime}} |
+{{if .Statistics.EmptyCount}}| Empty Files | {{.Statistics.EmptyCount}} |
+{{end}}{{if .Statistics.SuccessCount}}| File Size (min / avg / max) | {{.Statistics.MinSize}} / {{.Statistics.AvgSize}} / {{.Statistics.MaxSize}} bytes |
+| Largest File | {{.Statistics.LargestFile}} |
+{{end}}| Average Processing Time | {{.Statistics.AverageTime}} |
+| Throughput | {{printf "%.2f" .Statistics.MBPerSecond}} MB/s, {{printf "%.1f" .Statistics.FilesPerSecond}} files/s |
+{{if .Statistics.TypeCounts}}
+## File Types
 
+| Type | Files |
+|------|-------|
+{{range $type, $count := .Statistics.TypeCounts}}| {{$type}} | {{$count}} |
+{{end}}{{end}}
+{{if .Statistics.TopBySize}}
+## Largest Files
+
+| Name | Size |
+|------|------|
+{{range .Statistics.TopBySize}}| {{.Name}} | {{.Value}} bytes |
+{{end}}{{end}}
+{{if .Statistics.TopByWords}}
+## Most Words
+
+| Name | Words |
+|------|-------|
+{{range .Statistics.TopByWords}}| {{.Name}} | {{.Value}} |
+{{end}}{{end}}
+{{if not .SummaryOnly}}
 ## Processed Files
 
-| Name | Size | Type | Words | Lines | Hash | Processing Time |
-|------|------|------|-------|-------|------|-----------------|
-{{range .Files}}| {{.Name}} | {{.Size}} | {{.Type}} | {{.WordCount}} | {{.LineCount}} | {{.Hash}} | {{.ProcessingTime}} |
-{{end}}
+| Name | Size | Type | Words | Lines | Hash | Processing Time | Anomalies |
+|------|------|------|-------|-------|------|-----------------|-----------|
+{{range .Files}}| {{.Name}} | {{.Size}} | {{.Type}} | {{.WordCount}} | {{.LineCount}} | {{.Hash}} | {{.ProcessingTime}} | {{.AnomalyCount}} |
+{{end}}{{end}}
+{{if and .Statistics.AnomalyCount (not .SummaryOnly)}}
+## Anomalies
+
+| File | Kind | Line | Detail |
+|------|------|------|--------|
+{{range .Files}}{{$name := .Name}}{{range .Anomalies}}| {{$name}} | {{.Kind}} | {{if .Line}}{{.Line}}{{end}} | {{.Detail}} |
+{{end}}{{end}}{{end}}
 
 {{if .Errors}}
 ## Errors
@@ -150,6 +272,72 @@ Generated at: {{.Timestamp.Format "2006-01-02 15:04:05"}}
 Total Processing Time: {{.ProcessingTime}}
 `
 
+// recordSize adds a successfully processed file to the size distribution
+// It must be called before the file is counted in SuccessCount and TotalSize
+func (s *Statistics) recordSize(name string, size int64) {
+	if s.SuccessCount == 0 || size < s.MinSize {
+		s.MinSize = size
+	}
+	if s.SuccessCount == 0 || size > s.MaxSize {
+		s.MaxSize, s.LargestFile = size, name
+	}
+	s.AvgSize = (s.TotalSize + size) / int64(s.SuccessCount+1)
+}
+
+// bytesPerMB is the decimal megabyte used for throughput, as storage vendors quote it
+const bytesPerMB = 1e6
+
+// SetThroughput records the run's wall-clock time and derives the
+// byte and file rates from the totals
+func (s *Statistics) SetThroughput(wall time.Duration) {
+	s.WallTime = wall
+	s.MBPerSecond, s.FilesPerSecond = 0, 0
+	if seconds := wall.Seconds(); seconds > 0 {
+		s.MBPerSecond = float64(s.TotalSize) / bytesPerMB / seconds
+		s.FilesPerSecond = float64(s.SuccessCount) / seconds
+	}
+}
+
+// GenerateJSONReport generates an indented JSON report from the provided data
+func GenerateJSONReport(data ReportData) (string, error) {
+	return EncodeJSONReport(data, true)
+}
+
+// EncodeJSONReport generates a JSON report indented by two spaces when pretty
+// is set, or on a single line for machine ingestion otherwise
+// Summary-only reports have no Files list
+func EncodeJSONReport(data ReportData, pretty bool) (string, error) {
+	if data.SummaryOnly {
+		data.Files = nil
+	}
+
+	var out []byte
+	var err error
+	if pretty {
+		out, err = json.MarshalIndent(data, "", "  ")
+	} else {
+		out, err = json.Marshal(data)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// DecodeJSONReport reads back a report written by EncodeJSONReport
+// Fields it doesn't know are ignored, so reports from newer versions still
+// load; a document without a Timestamp isn't a report and fails
+func DecodeJSONReport(report []byte) (ReportData, error) {
+	var data ReportData
+	if err := json.Unmarshal(report, &data); err != nil {
+		return ReportData{}, fmt.Errorf("failed to decode JSON report: %w", err)
+	}
+	if data.Timestamp.IsZero() {
+		return ReportData{}, fmt.Errorf("failed to decode JSON report: no Timestamp")
+	}
+	return data, nil
+}
+
 // GenerateHTMLReport generates an HTML report from the provided data
 func GenerateHTMLReport(data ReportData) (string, error) {
 	tmpl, err := template.New("html").Parse(HTMLTemplate)
@@ -179,3 +367,49 @@ func GenerateMarkdownReport(data ReportData) (string, error) {
 
 	return buf.String(), nil
 }
+
+// SplitByType partitions the report's files by type into one report per type,
+// each with statistics recomputed from its own files
+// Errors are not attributed to a type and stay in the overall report only
+func (data ReportData) SplitByType() map[string]ReportData {
+	files := make(map[string][]FileInfo)
+	for _, file := range data.Files {
+		files[file.Type] = append(files[file.Type], file)
+	}
+
+	// Each type gets top lists as long as the overall ones
+	topLen := max(len(data.Statistics.TopBySize), len(data.Statistics.TopByWords))
+
+	reports := make(map[string]ReportData, len(files))
+	for fileType, typeFiles := range files {
+		var stats Statistics
+		var totalTime time.Duration
+		bySize, byWords := topN{n: topLen}, topN{n: topLen}
+		for _, file := range typeFiles {
+			bySize.add(RankedFile{Name: file.Name, Value: file.Size})
+			byWords.add(RankedFile{Name: file.Name, Value: int64(file.WordCount)})
+			stats.recordSize(file.Name, file.Size)
+			stats.SuccessCount++
+			stats.TotalSize += file.Size
+			stats.TotalWords += file.WordCount
+			stats.TotalLines += file.LineCount
+			stats.AnomalyCount += file.AnomalyCount
+			totalTime += file.ProcessingTime
+		}
+		stats.TotalFiles = len(typeFiles)
+		stats.AverageTime = totalTime / time.Duration(len(typeFiles))
+		stats.TypeCounts = map[string]int{fileType: len(typeFiles)}
+		stats.TopBySize, stats.TopByWords = bySize.sorted(), byWords.sorted()
+		stats.SetThroughput(data.Statistics.WallTime)
+
+		reports[fileType] = ReportData{
+			Title:          fmt.Sprintf("%s (%s files)", data.Title, fileType),
+			Timestamp:      data.Timestamp,
+			Files:          typeFiles,
+			Statistics:     stats,
+			ProcessingTime: data.ProcessingTime,
+			SummaryOnly:    data.SummaryOnly,
+		}
+	}
+	return reports
+}
@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// RankedFile is an entry of a top-N list, with the value it was ranked by
+type RankedFile struct {
+	Name  string
+	Value int64
+}
+
+// ranksBelow reports whether a ranks below b: it has a lower value, or the
+// same value and a later name, so ties are broken the same way every run
+func ranksBelow(a, b RankedFile) bool {
+	if a.Value != b.Value {
+		return a.Value < b.Value
+	}
+	return a.Name > b.Name
+}
+
+// topN keeps the n highest-ranked files offered to it in O(n) memory
+// It is a min-heap with the lowest-ranked kept file at the root, so a new
+// file is checked against it in O(1) and replaces it in O(log n)
+type topN struct {
+	n     int
+	files []RankedFile
+}
+
+// Len, Less, Swap, Push and Pop implement heap.Interface
+func (t *topN) Len() int           { return len(t.files) }
+func (t *topN) Less(i, j int) bool { return ranksBelow(t.files[i], t.files[j]) }
+func (t *topN) Swap(i, j int)      { t.files[i], t.files[j] = t.files[j], t.files[i] }
+func (t *topN) Push(x interface{}) { t.files = append(t.files, x.(RankedFile)) }
+func (t *topN) Pop() interface{} {
+	last := t.files[len(t.files)-1]
+	t.files = t.files[:len(t.files)-1]
+	return last
+}
+
+// add offers a file, keeping it if it ranks among the top n so far
+func (t *topN) add(file RankedFile) {
+	switch {
+	case t.n <= 0:
+	case len(t.files) < t.n:
+		heap.Push(t, file)
+	case ranksBelow(t.files[0], file):
+		t.files[0] = file
+		heap.Fix(t, 0)
+	}
+}
+
+// sorted returns a copy of the kept files, highest ranked first
+func (t *topN) sorted() []RankedFile {
+	if len(t.files) == 0 {
+		return nil
+	}
+	files := make([]RankedFile, len(t.files))
+	copy(files, t.files)
+	sort.Slice(files, func(i, j int) bool { return ranksBelow(files[j], files[i]) })
+	return files
+}
@@ -0,0 +1,70 @@
+package templates
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+func TestTopN(t *testing.T) {
+	// Compare against a full sort over shuffled values with ties
+	var all []RankedFile
+	for i := 0; i < 500; i++ {
+		all = append(all, RankedFile{Name: fmt.Sprintf("f%03d", i), Value: int64(i % 37)})
+	}
+	rand.New(rand.NewSource(1)).Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+
+	top := topN{n: 20}
+	for _, file := range all {
+		top.add(file)
+	}
+	if len(top.files) != 20 {
+		t.Fatalf("Expected 20 files kept, got %d", len(top.files))
+	}
+
+	sort.Slice(all, func(i, j int) bool { return ranksBelow(all[j], all[i]) })
+	if got := top.sorted(); !reflect.DeepEqual(got, all[:20]) {
+		t.Errorf("Expected %v, got %v", all[:20], got)
+	}
+
+	// Disabled tracking keeps nothing
+	none := topN{}
+	none.add(RankedFile{Name: "a", Value: 1})
+	if none.sorted() != nil {
+		t.Error("Expected a zero-size tracker to keep nothing")
+	}
+}
+
+func TestStatsAccumulatorTopN(t *testing.T) {
+	acc := NewStatsAccumulator()
+	acc.SetTopN(2)
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "a.txt", Type: "text", Size: 10}, Words: 50})
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "b.txt", Type: "text", Size: 30}, Words: 5})
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "c.json", Type: "json", Size: 20}, Words: 20})
+
+	stats := acc.Statistics()
+	wantSize := []RankedFile{{"b.txt", 30}, {"c.json", 20}}
+	wantWords := []RankedFile{{"a.txt", 50}, {"c.json", 20}}
+	if !reflect.DeepEqual(stats.TopBySize, wantSize) || !reflect.DeepEqual(stats.TopByWords, wantWords) {
+		t.Errorf("Unexpected top files: by size %v, by words %v", stats.TopBySize, stats.TopByWords)
+	}
+
+	report, err := GenerateMarkdownReport(acc.Report("Test Report"))
+	if err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	if !strings.Contains(report, "## Largest Files") || !strings.Contains(report, "| b.txt | 30 bytes |") {
+		t.Errorf("Expected the largest files in the report:\n%s", report)
+	}
+
+	// Per-type reports rank only their own files
+	text := acc.Report("Test Report").SplitByType()["text"]
+	if want := []RankedFile{{"a.txt", 50}, {"b.txt", 5}}; !reflect.DeepEqual(text.Statistics.TopByWords, want) {
+		t.Errorf("Expected %v for text files, got %v", want, text.Statistics.TopByWords)
+	}
+}
@@ -0,0 +1,184 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Size of each trend chart's plotting area, in SVG user units; the svg
+// elements of TrendHTMLTemplate have the same size
+const (
+	trendChartWidth  = 600
+	trendChartHeight = 120
+)
+
+// TrendPoint holds the totals of one analyze run, and their change since
+// the previous run
+type TrendPoint struct {
+	Title      string
+	Timestamp  time.Time
+	TotalFiles int
+	TotalSize  int64
+	ErrorCount int
+	FilesDelta int
+	SizeDelta  int64
+	ErrorDelta int
+}
+
+// TrendSeries is one metric across the runs, oldest first
+type TrendSeries struct {
+	Name   string
+	Values []int64
+	Min    int64
+	Max    int64
+	// Points are the SVG polyline coordinates of the values
+	Points string
+}
+
+// TrendData represents the data structure for trend report generation
+type TrendData struct {
+	Title     string
+	Timestamp time.Time
+	Points    []TrendPoint
+	Series    []TrendSeries
+}
+
+// NewTrend builds a trend of total files, total bytes and errors from
+// reports, ordered by their timestamps
+func NewTrend(title string, reports []ReportData) TrendData {
+	reports = append([]ReportData(nil), reports...)
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Timestamp.Before(reports[j].Timestamp)
+	})
+
+	trend := TrendData{Title: title, Timestamp: time.Now()}
+	files := make([]int64, len(reports))
+	sizes := make([]int64, len(reports))
+	errs := make([]int64, len(reports))
+	for i, report := range reports {
+		stats := report.Statistics
+		point := TrendPoint{
+			Title:      report.Title,
+			Timestamp:  report.Timestamp,
+			TotalFiles: stats.TotalFiles,
+			TotalSize:  stats.TotalSize,
+			ErrorCount: stats.ErrorCount,
+		}
+		if i > 0 {
+			prev := trend.Points[i-1]
+			point.FilesDelta = point.TotalFiles - prev.TotalFiles
+			point.SizeDelta = point.TotalSize - prev.TotalSize
+			point.ErrorDelta = point.ErrorCount - prev.ErrorCount
+		}
+		trend.Points = append(trend.Points, point)
+		files[i], sizes[i], errs[i] = int64(stats.TotalFiles), stats.TotalSize, int64(stats.ErrorCount)
+	}
+
+	trend.Series = []TrendSeries{
+		newTrendSeries("Total Files", files),
+		newTrendSeries("Total Size (bytes)", sizes),
+		newTrendSeries("Errors", errs),
+	}
+	return trend
+}
+
+// newTrendSeries scales values onto the chart, from the left edge to the
+// right with the lowest value at the bottom; a flat series runs through
+// the middle and a single run sits in the centre
+func newTrendSeries(name string, values []int64) TrendSeries {
+	series := TrendSeries{Name: name, Values: values}
+	if len(values) == 0 {
+		return series
+	}
+	series.Min, series.Max = values[0], values[0]
+	for _, v := range values {
+		series.Min, series.Max = min(series.Min, v), max(series.Max, v)
+	}
+
+	coords := make([]string, len(values))
+	for i, v := range values {
+		x := trendChartWidth / 2.0
+		if len(values) > 1 {
+			x = float64(i) * trendChartWidth / float64(len(values)-1)
+		}
+		y := trendChartHeight / 2.0
+		if series.Max > series.Min {
+			y = trendChartHeight * (1 - float64(v-series.Min)/float64(series.Max-series.Min))
+		}
+		coords[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	series.Points = strings.Join(coords, " ")
+	return series
+}
+
+// TrendHTMLTemplate is the template for trend reports
+const TrendHTMLTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+    <title>{{.Title}}</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 20px; }
+        .header { background: #f5f5f5; padding: 20px; border-radius: 5px; }
+        .stats { margin: 20px 0; }
+        svg { background: #fafafa; border: 1px solid #ddd; overflow: visible; }
+        polyline { fill: none; stroke: #36c; stroke-width: 2; }
+        table { width: 100%; border-collapse: collapse; }
+        th, td { padding: 8px; border: 1px solid #ddd; text-align: left; }
+        th { background: #f5f5f5; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>{{.Title}}</h1>
+        <p>Generated at: {{.Timestamp.Format "2006-01-02 15:04:05"}}</p>
+        <p>Runs: {{len .Points}}</p>
+    </div>
+
+    {{range .Series}}
+    <div class="stats">
+        <h2>{{.Name}}</h2>
+        <p>Range: {{.Min}} to {{.Max}}</p>
+        <svg width="600" height="120" viewBox="0 0 600 120" role="img" aria-label="{{.Name}}">
+            <polyline points="{{.Points}}"/>
+        </svg>
+    </div>
+    {{end}}
+
+    <div class="stats">
+        <h2>Runs</h2>
+        <table>
+            <tr><th>Run</th><th>Timestamp</th><th>Total Files</th><th>Total Size</th><th>Errors</th></tr>
+            {{range $i, $p := .Points}}
+            <tr>
+                <td>{{$p.Title}}</td>
+                <td>{{$p.Timestamp.Format "2006-01-02 15:04:05"}}</td>
+                <td>{{$p.TotalFiles}}{{if $i}} ({{printf "%+d" $p.FilesDelta}}){{end}}</td>
+                <td>{{$p.TotalSize}} bytes{{if $i}} ({{printf "%+d" $p.SizeDelta}}){{end}}</td>
+                <td>{{$p.ErrorCount}}{{if $i}} ({{printf "%+d" $p.ErrorDelta}}){{end}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </div>
+</body>
+</html>
+`
+
+// GenerateTrendHTMLReport generates an HTML trend report from the provided data
+func GenerateTrendHTMLReport(data TrendData) (string, error) {
+	tmpl, err := template.New("trend").Parse(TrendHTMLTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
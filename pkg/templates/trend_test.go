@@ -0,0 +1,85 @@
+package templates
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+func TestDecodeJSONReport(t *testing.T) {
+	acc := NewStatsAccumulator()
+	acc.SetTopN(2)
+	acc.Add(models.ProcessResult{FileInfo: models.FileInfo{Path: "a.csv", Type: "csv", Size: 10}, Lines: 2, Words: 4, Duration: time.Millisecond})
+	result := models.ProcessResult{FileInfo: models.FileInfo{Path: "b.csv", Type: "csv", Size: 20}, Lines: 3}
+	result.AddAnomaly("field_count", "row has 3 fields, header has 2", 2)
+	acc.Add(result)
+	acc.AddError("c.json", errors.New("bad document"))
+
+	data := acc.Report("Nightly")
+	data.Timestamp = time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+	data.ProcessingTime = 1500 * time.Millisecond
+
+	for _, pretty := range []bool{true, false} {
+		encoded, err := EncodeJSONReport(data, pretty)
+		if err != nil {
+			t.Fatalf("Failed to render report: %v", err)
+		}
+		decoded, err := DecodeJSONReport([]byte(encoded))
+		if err != nil {
+			t.Fatalf("Failed to decode report: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, data) {
+			t.Errorf("Report didn't round-trip:\nwant %+v\ngot  %+v", data, decoded)
+		}
+	}
+
+	if _, err := DecodeJSONReport([]byte(`{"name": "not a report"}`)); err == nil {
+		t.Error("Expected an error for a document without a Timestamp")
+	}
+}
+
+func TestTrend(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 2, 0, 0, 0, time.UTC) }
+	reports := []ReportData{
+		{Title: "third", Timestamp: day(3), Statistics: Statistics{TotalFiles: 15, TotalSize: 400, ErrorCount: 2}},
+		{Title: "first", Timestamp: day(1), Statistics: Statistics{TotalFiles: 10, TotalSize: 100}},
+		{Title: "second", Timestamp: day(2), Statistics: Statistics{TotalFiles: 12, TotalSize: 250}},
+	}
+
+	trend := NewTrend("Trend", reports)
+	var titles []string
+	for _, point := range trend.Points {
+		titles = append(titles, point.Title)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(titles, want) {
+		t.Fatalf("Expected runs in time order %v, got %v", want, titles)
+	}
+	if last := trend.Points[2]; last.FilesDelta != 3 || last.SizeDelta != 150 || last.ErrorDelta != 2 {
+		t.Errorf("Unexpected changes since the previous run: %+v", last)
+	}
+
+	files := trend.Series[0]
+	if files.Min != 10 || files.Max != 15 || files.Points != "0.0,120.0 300.0,72.0 600.0,0.0" {
+		t.Errorf("Unexpected files series: %+v", files)
+	}
+	if errs := trend.Series[2]; errs.Points != "0.0,120.0 300.0,120.0 600.0,0.0" {
+		t.Errorf("Unexpected errors series: %+v", errs)
+	}
+	if flat := newTrendSeries("flat", []int64{5, 5}); flat.Points != "0.0,60.0 600.0,60.0" {
+		t.Errorf("Expected a flat series through the middle, got %q", flat.Points)
+	}
+
+	html, err := GenerateTrendHTMLReport(trend)
+	if err != nil {
+		t.Fatalf("Failed to render trend report: %v", err)
+	}
+	for _, want := range []string{"<h2>Total Size (bytes)</h2>", `points="0.0,120.0 300.0,72.0 600.0,0.0"`, "400 bytes (&#43;150)"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected %q in trend report:\n%s", want, html)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// DirStats holds analytics aggregated for a single directory
+type DirStats struct {
+	Dir   string
+	Files int
+	Bytes int64
+	Lines int
+	Words int
+}
+
+// add accumulates a single processing result into the directory stats
+func (d *DirStats) add(result models.ProcessResult) {
+	d.Files++
+	d.Bytes += result.Size
+	d.Lines += result.Lines
+	d.Words += result.Words
+}
+
+// AggregateByDir groups processing results by their parent directory
+// Demonstrates map-based aggregation
+func AggregateByDir(results []models.ProcessResult) map[string]DirStats {
+	stats := make(map[string]DirStats)
+	for _, result := range results {
+		dir := filepath.Dir(result.Path)
+		entry := stats[dir]
+		entry.Dir = dir
+		entry.add(result)
+		stats[dir] = entry
+	}
+	return stats
+}
+
+// RollupDirs propagates per-directory stats into every ancestor up to root,
+// so each entry reports the totals of its whole subtree like `du` does
+func RollupDirs(stats map[string]DirStats, root string) map[string]DirStats {
+	root = filepath.Clean(root)
+	rolled := make(map[string]DirStats, len(stats))
+
+	for dir, entry := range stats {
+		// Walk from the directory up to the root, adding to each level
+		for current := dir; ; current = filepath.Dir(current) {
+			total := rolled[current]
+			total.Dir = current
+			total.Files += entry.Files
+			total.Bytes += entry.Bytes
+			total.Lines += entry.Lines
+			total.Words += entry.Words
+			rolled[current] = total
+
+			parent := filepath.Dir(current)
+			if current == root || parent == current {
+				break
+			}
+		}
+	}
+
+	return rolled
+}
+
+// SortDirStats returns the directory stats ordered by size, largest first
+// Ties are broken by directory name to keep output stable
+func SortDirStats(stats map[string]DirStats) []DirStats {
+	sorted := make([]DirStats, 0, len(stats))
+	for _, entry := range stats {
+		sorted = append(sorted, entry)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Bytes != sorted[j].Bytes {
+			return sorted[i].Bytes > sorted[j].Bytes
+		}
+		return sorted[i].Dir < sorted[j].Dir
+	})
+	return sorted
+}
+
+// FormatBytes renders a byte count in human readable form (like `du -h`)
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
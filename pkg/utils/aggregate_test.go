@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+)
+
+// dirResult builds a processing result for a file at path
+func dirResult(path string, size int64, lines, words int) models.ProcessResult {
+	return models.ProcessResult{
+		FileInfo: models.FileInfo{Path: filepath.FromSlash(path), Size: size},
+		Lines:    lines,
+		Words:    words,
+	}
+}
+
+func TestAggregateAndRollupDirs(t *testing.T) {
+	results := []models.ProcessResult{
+		dirResult("root/a.txt", 10, 1, 2),
+		dirResult("root/src/b.txt", 20, 2, 4),
+		dirResult("root/src/c.txt", 30, 3, 6),
+		dirResult("root/src/pkg/d.txt", 40, 4, 8),
+	}
+
+	dir := func(path string) string { return filepath.FromSlash(path) }
+	byDir := AggregateByDir(results)
+	want := map[string]DirStats{
+		dir("root"):         {Dir: dir("root"), Files: 1, Bytes: 10, Lines: 1, Words: 2},
+		dir("root/src"):     {Dir: dir("root/src"), Files: 2, Bytes: 50, Lines: 5, Words: 10},
+		dir("root/src/pkg"): {Dir: dir("root/src/pkg"), Files: 1, Bytes: 40, Lines: 4, Words: 8},
+	}
+	if !reflect.DeepEqual(byDir, want) {
+		t.Errorf("AggregateByDir: expected %+v, got %+v", want, byDir)
+	}
+
+	// Each directory holds its subtree's totals, and nothing above the root
+	// is reported
+	tests := []struct {
+		dir   string
+		files int
+		bytes int64
+	}{
+		{"root", 4, 100},
+		{"root/src", 3, 90},
+		{"root/src/pkg", 1, 40},
+	}
+	rolled := RollupDirs(byDir, "root/")
+	if len(rolled) != len(tests) {
+		t.Errorf("Expected %d rolled up directories, got %+v", len(tests), rolled)
+	}
+	for _, tt := range tests {
+		got, ok := rolled[dir(tt.dir)]
+		if !ok || got.Files != tt.files || got.Bytes != tt.bytes {
+			t.Errorf("RollupDirs %s: expected %d files and %d bytes, got %+v", tt.dir, tt.files, tt.bytes, got)
+		}
+	}
+	if got := rolled[dir("root")]; got.Lines != 10 || got.Words != 20 {
+		t.Errorf("Expected the root to total 10 lines and 20 words, got %+v", got)
+	}
+}
+
+func TestSortDirStats(t *testing.T) {
+	stats := map[string]DirStats{
+		"b":     {Dir: "b", Bytes: 10},
+		"a":     {Dir: "a", Bytes: 10},
+		"large": {Dir: "large", Bytes: 30},
+		"c":     {Dir: "c", Bytes: 5},
+	}
+
+	var got []string
+	for _, entry := range SortDirStats(stats) {
+		got = append(got, entry.Dir)
+	}
+	// Largest first, with equal sizes in name order
+	want := []string{"large", "a", "b", "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1024, "1.0K"},
+		{1536, "1.5K"},
+		{1024*1024 - 1, "1024.0K"},
+		{1024 * 1024, "1.0M"},
+		{5 * 1024 * 1024 * 1024, "5.0G"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d): expected %s, got %s", tt.n, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "report.json")
+
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("new content"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "new content" {
+		t.Errorf("Expected the new content, got %q (%v)", content, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the written file, found %d entries", len(entries))
+	}
+
+	// A missing directory fails without creating anything
+	if err := WriteFileAtomic(filepath.Join(tmpDir, "missing", "out.txt"), []byte("x"), 0644); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestWriteFileAtomicCrossDevice(t *testing.T) {
+	defer func(original func(string, string) error) { rename = original }(rename)
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "decoded.bin")
+	if err := WriteFileAtomic(path, []byte("copied"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "copied" {
+		t.Errorf("Expected the copied content, got %q (%v)", content, err)
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("Expected the temporary file to be removed, found %d entries", len(entries))
+	}
+}
@@ -2,8 +2,13 @@ package utils
 
 import (
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
 )
 
 // FileFilter is a function type that determines if a file should be processed
@@ -53,6 +58,81 @@ func CreateSizeFilter(minSize, maxSize int64) FileFilter {
 	}
 }
 
+// CreateModTimeFilter returns a FileFilter that checks the modification time
+// A zero after or before leaves that side of the range open
+func CreateModTimeFilter(after, before time.Time) FileFilter {
+	return func(path string) bool {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false
+		}
+
+		modTime := info.ModTime()
+		return (after.IsZero() || !modTime.Before(after)) &&
+			(before.IsZero() || modTime.Before(before))
+	}
+}
+
+// CreateRegularFileFilter returns a FileFilter that keeps only regular files,
+// following symlinks, so FIFOs, sockets and devices are never opened:
+// reading a FIFO can block forever
+func CreateRegularFileFilter() FileFilter {
+	return func(path string) bool {
+		info, err := os.Stat(path)
+		return err == nil && info.Mode().IsRegular()
+	}
+}
+
+// CreateRegexFilter returns a FileFilter that matches the slash-separated path
+// against pattern; with exclude set, matching files are rejected instead
+func CreateRegexFilter(pattern *regexp.Regexp, exclude bool) FileFilter {
+	return func(path string) bool {
+		return pattern.MatchString(FilterPath(path)) != exclude
+	}
+}
+
+// CreateGlobFilter returns a FileFilter that keeps paths matching a glob
+// pattern, or drops them when exclude is set
+// Patterns containing a slash match the whole forward-slash path, as in
+// "*/vendor/*"; others match the base name, as in "*.min.js"
+func CreateGlobFilter(pattern string, exclude bool) (FileFilter, error) {
+	pattern = slashPath(pattern, filepath.Separator)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	wholePath := strings.Contains(pattern, "/")
+
+	return func(file string) bool {
+		name := FilterPath(file)
+		if !wholePath {
+			name = path.Base(name)
+		}
+		matched, _ := path.Match(pattern, name)
+		return matched != exclude
+	}, nil
+}
+
+// FilterPath normalizes a path for pattern matching so the same regex or glob
+// works on every platform: separators become forward slashes and a Windows
+// drive letter is upper-cased, so C:\Data\a.txt matches as C:/Data/a.txt
+func FilterPath(path string) string {
+	return slashPath(path, filepath.Separator)
+}
+
+// slashPath replaces separator with '/' and upper-cases a leading drive letter
+// The separator is a parameter so Windows paths can be tested on any platform
+func slashPath(path string, separator byte) string {
+	if separator != '/' {
+		path = strings.ReplaceAll(path, string(separator), "/")
+	}
+	if len(path) >= 2 && path[1] == ':' && separator == '\\' {
+		if c := path[0]; c >= 'a' && c <= 'z' {
+			path = string(c-'a'+'A') + path[1:]
+		}
+	}
+	return path
+}
+
 // CombineFilters demonstrates variadic functions
 // Returns a FileFilter that combines multiple filters with AND logic
 func CombineFilters(filters ...FileFilter) FileFilter {
@@ -95,6 +175,37 @@ func WalkFiles(root string, filter FileFilter, fn WalkFunc) error {
 	return filepath.Walk(root, walkFn)
 }
 
+// WalkFilesLenient is like WalkFiles but keeps going when an entry can't be
+// read (for example a permission-denied subdirectory)
+// Such entries are skipped and collected into the returned ErrorCollection;
+// errors returned by fn and a missing root still abort the walk
+func WalkFilesLenient(root string, filter FileFilter, fn WalkFunc) (*apperrors.ErrorCollection, error) {
+	skipped := apperrors.NewErrorCollection()
+
+	var walkFn filepath.WalkFunc = func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// Record and skip the unreadable entry
+			skipped.Add(apperrors.NewProcessError(apperrors.ErrorTypeIO, path, "skipped unreadable path", err))
+			return nil
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		if filter != nil && !filter(path) {
+			return nil
+		}
+
+		return fn(path)
+	}
+
+	return skipped, filepath.Walk(root, walkFn)
+}
+
 // CountFiles demonstrates a simple use of WalkFiles
 // Returns the number of files matching the filter
 func CountFiles(root string, filter FileFilter) (count int, err error) {
@@ -105,3 +216,19 @@ func CountFiles(root string, filter FileFilter) (count int, err error) {
 	})
 	return
 }
+
+// CountAndSizeFiles returns the number and total size of files matching the
+// filter, without reading them
+// Files that disappear before they are stat'ed are not counted
+func CountAndSizeFiles(root string, filter FileFilter) (count int, size int64, err error) {
+	err = WalkFiles(root, filter, func(path string) error {
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			return nil
+		}
+		count++
+		size += info.Size()
+		return nil
+	})
+	return
+}
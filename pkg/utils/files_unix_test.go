@@ -0,0 +1,94 @@
+//go:build unix
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	apperrors "github.com/RaihanurRahman2022/file-analytics/pkg/errors"
+)
+
+func TestCreateRegularFileFilter(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	fifo := filepath.Join(dir, "fifo.txt")
+	if err := os.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := syscall.Mkfifo(fifo, 0644); err != nil {
+		t.Skipf("FIFOs not supported here: %v", err)
+	}
+	links := map[string]string{"file-link.txt": file, "fifo-link.txt": fifo}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+
+	regular := CreateRegularFileFilter()
+	tests := map[string]bool{
+		"file.txt":      true,
+		"file-link.txt": true,
+		"fifo.txt":      false,
+		"fifo-link.txt": false,
+		"missing.txt":   false,
+		".":             false,
+	}
+	for name, want := range tests {
+		if got := regular(filepath.Join(dir, name)); got != want {
+			t.Errorf("Expected %s to give %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestWalkFilesLenient(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read directories without permission")
+	}
+
+	dir := t.TempDir()
+	locked := filepath.Join(dir, "locked")
+	for _, path := range []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "z.txt"), filepath.Join(locked, "hidden.txt")} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatalf("Failed to lock directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+	// The walk skips the unreadable directory and records it
+	var walked []string
+	skipped, err := WalkFilesLenient(dir, nil, func(path string) error {
+		walked = append(walked, filepath.Base(path))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the walk to continue, got %v", err)
+	}
+	if len(walked) != 2 || walked[0] != "a.txt" || walked[1] != "z.txt" {
+		t.Errorf("Expected a.txt and z.txt to be walked, got %v", walked)
+	}
+	var processErr *apperrors.ProcessError
+	if errs := skipped.Errors(); len(errs) != 1 || !errors.As(errs[0], &processErr) || processErr.File != locked {
+		t.Errorf("Expected %s to be collected, got %v", locked, errs)
+	}
+
+	// WalkFiles, used by --strict, still stops at the first unreadable path
+	if err := WalkFiles(dir, nil, func(string) error { return nil }); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("Expected a permission error from WalkFiles, got %v", err)
+	}
+
+	// An unreadable root fails either way
+	if _, err := WalkFilesLenient(locked, nil, func(string) error { return nil }); err == nil {
+		t.Error("Expected an unreadable root to fail the walk")
+	}
+}
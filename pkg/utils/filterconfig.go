@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// FilterConfig describes include/exclude rules, as read from the
+// config file's filters section
+type FilterConfig struct {
+	// Extensions limits files to these extensions; ExcludeExtensions drops them
+	Extensions        []string `mapstructure:"extensions"`
+	ExcludeExtensions []string `mapstructure:"exclude_extensions"`
+
+	// MinSize and MaxSize bound the file size in bytes (0 means no bound)
+	MinSize int64 `mapstructure:"min_size"`
+	MaxSize int64 `mapstructure:"max_size"`
+
+	// ModifiedAfter and ModifiedBefore bound the modification time; each is
+	// an RFC 3339 time, a date (2006-01-02), or a duration ago such as 720h
+	ModifiedAfter  string `mapstructure:"modified_after"`
+	ModifiedBefore string `mapstructure:"modified_before"`
+
+	// Include keeps only paths matching every regex; Exclude drops paths
+	// matching any. Paths are matched with forward slashes
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+
+	// IncludeGlobs and ExcludeGlobs work like Include and Exclude with glob
+	// patterns instead; see CreateGlobFilter
+	IncludeGlobs []string `mapstructure:"include_globs"`
+	ExcludeGlobs []string `mapstructure:"exclude_globs"`
+}
+
+// Build validates the config and combines its rules into one FileFilter
+// An empty config accepts every file
+func (c FilterConfig) Build(now time.Time) (FileFilter, error) {
+	var filters []FileFilter
+
+	if len(c.Extensions) > 0 {
+		filters = append(filters, CreateExtensionFilter(c.Extensions...))
+	}
+	if len(c.ExcludeExtensions) > 0 {
+		excluded := CreateExtensionFilter(c.ExcludeExtensions...)
+		filters = append(filters, func(path string) bool { return !excluded(path) })
+	}
+
+	if c.MinSize < 0 || c.MaxSize < 0 {
+		return nil, fmt.Errorf("invalid size range: sizes must not be negative")
+	}
+	if c.MaxSize > 0 && c.MinSize > c.MaxSize {
+		return nil, fmt.Errorf("invalid size range: min_size %d is larger than max_size %d", c.MinSize, c.MaxSize)
+	}
+	if c.MinSize > 0 || c.MaxSize > 0 {
+		filters = append(filters, CreateSizeFilter(c.MinSize, c.MaxSize))
+	}
+
+	after, err := parseFilterTime(c.ModifiedAfter, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modified_after: %w", err)
+	}
+	before, err := parseFilterTime(c.ModifiedBefore, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modified_before: %w", err)
+	}
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		return nil, fmt.Errorf("invalid modification range: modified_after %s is not before modified_before %s",
+			after.Format(time.RFC3339), before.Format(time.RFC3339))
+	}
+	if !after.IsZero() || !before.IsZero() {
+		filters = append(filters, CreateModTimeFilter(after, before))
+	}
+
+	for _, rules := range []struct {
+		patterns []string
+		exclude  bool
+	}{{c.Include, false}, {c.Exclude, true}} {
+		for _, pattern := range rules.patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+			}
+			filters = append(filters, CreateRegexFilter(re, rules.exclude))
+		}
+	}
+
+	for _, rules := range []struct {
+		patterns []string
+		exclude  bool
+	}{{c.IncludeGlobs, false}, {c.ExcludeGlobs, true}} {
+		for _, pattern := range rules.patterns {
+			filter, err := CreateGlobFilter(pattern, rules.exclude)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter glob %q: %w", pattern, err)
+			}
+			filters = append(filters, filter)
+		}
+	}
+
+	return CombineFilters(filters...), nil
+}
+
+// parseFilterTime parses an absolute time, a date, or a duration before now
+// An empty value returns the zero time
+func parseFilterTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time, a date, or a duration", value)
+}
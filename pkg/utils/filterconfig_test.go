@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterConfig(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	files := map[string]struct {
+		size    int
+		modTime time.Time
+	}{
+		"keep.txt":          {10, now},
+		"tiny.txt":          {1, now},
+		"old.txt":           {10, now.Add(-48 * time.Hour)},
+		"skip.log":          {10, now},
+		"vendor/lib.txt":    {10, now},
+		"data/records.json": {10, now},
+	}
+	for name, f := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, f.size), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Chtimes(path, f.modTime, f.modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+
+	cfg := FilterConfig{
+		ExcludeExtensions: []string{".log"},
+		MinSize:           5,
+		ModifiedAfter:     "24h",
+		Exclude:           []string{"/vendor/"},
+	}
+	filter, err := cfg.Build(now)
+	if err != nil {
+		t.Fatalf("Failed to build filter: %v", err)
+	}
+
+	var kept []string
+	if err := WalkFiles(dir, filter, func(path string) error {
+		rel, _ := filepath.Rel(dir, path)
+		kept = append(kept, filepath.ToSlash(rel))
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to walk files: %v", err)
+	}
+	if strings.Join(kept, ",") != "data/records.json,keep.txt" {
+		t.Errorf("Unexpected files kept: %v", kept)
+	}
+
+	// An empty config accepts everything
+	all, err := FilterConfig{}.Build(now)
+	if err != nil || !all(filepath.Join(dir, "skip.log")) {
+		t.Errorf("Expected an empty config to accept every file, got %v", err)
+	}
+}
+
+func TestFilterConfigInvalid(t *testing.T) {
+	tests := map[string]FilterConfig{
+		"size range": {MinSize: 10, MaxSize: 5},
+		"negative":   {MinSize: -1},
+		"time":       {ModifiedBefore: "last week"},
+		"time range": {ModifiedAfter: "2024-02-01", ModifiedBefore: "2024-01-01"},
+		"regex":      {Include: []string{"("}},
+		"glob":       {ExcludeGlobs: []string{"["}},
+	}
+	for name, cfg := range tests {
+		if _, err := cfg.Build(time.Now()); err == nil {
+			t.Errorf("%s: expected an error for %+v", name, cfg)
+		}
+	}
+}
+
+func TestGlobFilter(t *testing.T) {
+	minified, err := CreateGlobFilter("*.min.js", true)
+	if err != nil {
+		t.Fatalf("Failed to create glob filter: %v", err)
+	}
+	if minified(filepath.Join("web", "app.min.js")) || !minified(filepath.Join("web", "app.js")) {
+		t.Error("Expected base name globs to exclude only minified files")
+	}
+
+	vendor, err := CreateGlobFilter("*/vendor/*", false)
+	if err != nil {
+		t.Fatalf("Failed to create glob filter: %v", err)
+	}
+	if !vendor(filepath.Join("src", "vendor", "lib.go")) || vendor(filepath.Join("src", "lib.go")) {
+		t.Error("Expected path globs to match the whole path")
+	}
+}
+
+func TestSlashPath(t *testing.T) {
+	tests := []struct {
+		path      string
+		separator byte
+		want      string
+	}{
+		{`c:\Users\me\data.csv`, '\\', "C:/Users/me/data.csv"},
+		{`D:\vendor\lib.txt`, '\\', "D:/vendor/lib.txt"},
+		{`\\server\share\a.txt`, '\\', "//server/share/a.txt"},
+		{"/home/me/c:file", '/', "/home/me/c:file"},
+		{`/home/me/odd\name.txt`, '/', `/home/me/odd\name.txt`},
+	}
+	for _, tt := range tests {
+		if got := slashPath(tt.path, tt.separator); got != tt.want {
+			t.Errorf("slashPath(%q): expected %q, got %q", tt.path, tt.want, got)
+		}
+	}
+
+	// Patterns written with forward slashes match normalized Windows paths
+	exclude := regexp.MustCompile("^C:/Users/[^/]+/vendor/")
+	if !exclude.MatchString(slashPath(`c:\Users\me\vendor\lib.txt`, '\\')) {
+		t.Error("Expected a portable pattern to match a Windows path")
+	}
+}
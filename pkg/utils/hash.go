@@ -1,14 +1,43 @@
 package utils
 
 import (
+	"crypto/md5"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"os"
+	"strings"
 )
 
+// HashAlgorithms lists the algorithms accepted by NewHash
+var HashAlgorithms = []string{"md5", "sha256"}
+
+// NewHash creates a hash for the named algorithm (see HashAlgorithms)
+func NewHash(algo string) (hash.Hash, error) {
+	switch algo {
+	case "md5":
+		return md5.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported hash algorithm %q (supported: %s)", algo, strings.Join(HashAlgorithms, ", "))
+}
+
+// HashReader returns the hex digest of everything read from r using algo
+func HashReader(r io.Reader, algo string) (string, error) {
+	h, err := NewHash(algo)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to calculate hash: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // HashFile calculates SHA256 hash of a file
 func HashFile(path string) (string, error) {
 	file, err := os.Open(path)
@@ -17,12 +46,7 @@ func HashFile(path string) (string, error) {
 	}
 	defer file.Close()
 
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", fmt.Errorf("failed to calculate hash: %w", err)
-	}
-
-	return hex.EncodeToString(hash.Sum(nil)), nil
+	return HashReader(file, "sha256")
 }
 
 // Base64EncodeFile encodes a file's contents in base64
@@ -43,7 +67,7 @@ func Base64EncodeFile(path string) (string, error) {
 	return base64.StdEncoding.EncodeToString(content), nil
 }
 
-// Base64DecodeFile decodes base64 content to a file
+// Base64DecodeFile decodes base64 content to a file, replacing it atomically
 func Base64DecodeFile(base64Content, outputPath string) error {
 	// Decode base64 content
 	content, err := base64.StdEncoding.DecodeString(base64Content)
@@ -52,7 +76,7 @@ func Base64DecodeFile(base64Content, outputPath string) error {
 	}
 
 	// Write to file
-	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+	if err := WriteFileAtomic(outputPath, content, 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// HashProgress is called after each file is hashed with the number of files
+// done so far, including failures, and the total to hash
+type HashProgress func(done, total int)
+
+// HashDir calculates the SHA256 hash of every file under root, matching the
+// filter (nil matches all), using up to workers goroutines
+// It returns the hashes keyed by path. Cancelling ctx stops dispatching new
+// files; files already being hashed finish and are included in the partial
+// result returned alongside ctx.Err()
+// progress may be nil; calls to it are serialized
+func HashDir(ctx context.Context, root string, filter FileFilter, workers int, progress HashProgress) (map[string]string, error) {
+	return HashDirWith(ctx, root, filter, workers, HashFile, progress)
+}
+
+// FileHasher returns the hex digest of the file at path
+type FileHasher func(path string) (string, error)
+
+// HashDirWith is HashDir hashing each file with hashFile, such as a
+// HashFileMmap closure for directories of large files
+func HashDirWith(ctx context.Context, root string, filter FileFilter, workers int, hashFile FileHasher, progress HashProgress) (map[string]string, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	// A cheap pre-pass gives progress a total to count towards
+	total, err := CountFiles(root, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count files: %w", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		hashes   = make(map[string]string, total)
+		firstErr error
+		done     int
+		wg       sync.WaitGroup
+	)
+	paths := make(chan string)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				hash, err := hashFile(path)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("failed to hash %s: %w", path, err)
+					}
+				} else {
+					hashes[path] = hash
+				}
+				done++
+				if progress != nil {
+					progress(done, total)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	walkErr := WalkFiles(root, filter, func(path string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case paths <- path:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(paths)
+	wg.Wait()
+
+	if walkErr != nil {
+		if ctx.Err() != nil {
+			return hashes, ctx.Err()
+		}
+		return hashes, fmt.Errorf("failed to walk directory: %w", walkErr)
+	}
+	return hashes, firstErr
+}
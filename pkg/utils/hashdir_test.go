@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("d%d", i%2))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("f%d.txt", i)), []byte(fmt.Sprint(i)), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	var calls, lastDone, lastTotal int
+	hashes, err := HashDir(context.Background(), dir, nil, 3, func(done, total int) {
+		calls++
+		lastDone, lastTotal = done, total
+	})
+	if err != nil {
+		t.Fatalf("Failed to hash directory: %v", err)
+	}
+	if len(hashes) != 5 || calls != 5 || lastDone != 5 || lastTotal != 5 {
+		t.Errorf("Expected 5 hashes and progress up to 5/5, got %d hashes, %d calls, %d/%d",
+			len(hashes), calls, lastDone, lastTotal)
+	}
+	if want := HashString("3"); hashes[filepath.Join(dir, "d1", "f3.txt")] != want {
+		t.Errorf("Unexpected hash for f3.txt: %v", hashes)
+	}
+
+	// Cancelling from the first progress call stops dispatching more files
+	ctx, cancel := context.WithCancel(context.Background())
+	partial, err := HashDir(ctx, dir, nil, 1, func(done, total int) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(partial) == 0 || len(partial) >= 5 {
+		t.Errorf("Expected a partial result, got %d hashes", len(partial))
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package utils
+
+import (
+	"errors"
+	"os"
+)
+
+// errMmapUnsupported is returned by mmapFile where mmap isn't available
+var errMmapUnsupported = errors.New("memory mapping is not supported on this platform")
+
+// mmapFile always fails, so HashFileMmap streams the file instead
+func mmapFile(file *os.File, size int) ([]byte, func() error, error) {
+	return nil, nil, errMmapUnsupported
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashFileMmap(t *testing.T) {
+	dir := t.TempDir()
+	sizes := map[string]int{
+		"empty.bin": 0,
+		"small.bin": 1000,
+		"large.bin": MmapMinSize + 1000,
+	}
+
+	for name, size := range sizes {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, bytes.Repeat([]byte("0123456789"), size/10), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		// Mapped or streamed, the digest must match HashFile's
+		want, err := HashFile(path)
+		if err != nil {
+			t.Fatalf("Failed to hash %s: %v", name, err)
+		}
+		got, err := HashFileMmap(path, "sha256")
+		if err != nil {
+			t.Fatalf("Failed to hash %s through mmap: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+
+	if _, err := HashFileMmap(filepath.Join(dir, "missing.bin"), "sha256"); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if _, err := HashFileMmap(filepath.Join(dir, "small.bin"), "crc32"); err == nil {
+		t.Error("Expected an error for an unsupported algorithm")
+	}
+}
+
+// BenchmarkHashFile compares streaming a large file through a buffer with
+// hashing it from a memory map
+func BenchmarkHashFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "large.bin")
+	content := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog\n"), 1<<20)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		b.Fatalf("Failed to create test file: %v", err)
+	}
+
+	b.Run("stream", func(b *testing.B) {
+		b.SetBytes(int64(len(content)))
+		for i := 0; i < b.N; i++ {
+			if _, err := HashFile(path); err != nil {
+				b.Fatalf("Failed to hash file: %v", err)
+			}
+		}
+	})
+
+	b.Run("mmap", func(b *testing.B) {
+		b.SetBytes(int64(len(content)))
+		for i := 0; i < b.N; i++ {
+			if _, err := HashFileMmap(path, "sha256"); err != nil {
+				b.Fatalf("Failed to hash file: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// TreeHashPrefix starts every tree hash string
+const TreeHashPrefix = "sha256-tree"
+
+// Domain separation bytes so a leaf can never collide with an inner node
+const (
+	treeLeafTag = 0x00
+	treeNodeTag = 0x01
+)
+
+// TreeHashFile hashes a file as a Merkle tree of SHA256 digests
+// The file is split into chunkSize-byte chunks that are hashed concurrently
+// by up to workers goroutines; pairs of digests are then hashed together
+// until a single root remains (an odd digest is carried up unchanged)
+//
+// The result is NOT the plain SHA256 of the file. It is reproducible only
+// with the same chunk size, so the chunk size is part of the returned
+// string: "sha256-tree:<chunkSize>:<hex root>"
+func TreeHashFile(path string, chunkSize int64, workers int) (string, error) {
+	if chunkSize <= 0 {
+		return "", fmt.Errorf("invalid chunk size: %d", chunkSize)
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	// An empty file still has one (empty) chunk
+	chunks := (info.Size() + chunkSize - 1) / chunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+
+	leaves := make([][]byte, chunks)
+	errs := make([]error, workers)
+	next := make(chan int64)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := range next {
+				if errs[w] != nil {
+					continue // Drain remaining work after a failure
+				}
+				section := io.NewSectionReader(file, i*chunkSize, chunkSize)
+				hash := sha256.New()
+				hash.Write([]byte{treeLeafTag})
+				if _, err := io.Copy(hash, section); err != nil {
+					errs[w] = fmt.Errorf("failed to hash chunk %d: %w", i, err)
+					continue
+				}
+				leaves[i] = hash.Sum(nil)
+			}
+		}(w)
+	}
+
+	for i := int64(0); i < chunks; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return "", err
+		}
+	}
+
+	root := treeRoot(leaves)
+	return fmt.Sprintf("%s:%d:%s", TreeHashPrefix, chunkSize, hex.EncodeToString(root)), nil
+}
+
+// treeRoot combines a level of digests pairwise until one remains
+func treeRoot(level [][]byte) []byte {
+	for len(level) > 1 {
+		parents := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				parents = append(parents, level[i])
+				continue
+			}
+			hash := sha256.New()
+			hash.Write([]byte{treeNodeTag})
+			hash.Write(level[i])
+			hash.Write(level[i+1])
+			parents = append(parents, hash.Sum(nil))
+		}
+		level = parents
+	}
+	return level[0]
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTreeHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// The number of workers must not change the result
+	single, err := TreeHashFile(path, 1024, 1)
+	if err != nil {
+		t.Fatalf("Failed to hash file: %v", err)
+	}
+	parallel, err := TreeHashFile(path, 1024, 8)
+	if err != nil {
+		t.Fatalf("Failed to hash file: %v", err)
+	}
+	if single != parallel {
+		t.Errorf("Hash depends on workers: %s vs %s", single, parallel)
+	}
+	if !strings.HasPrefix(single, "sha256-tree:1024:") {
+		t.Errorf("Expected the chunk size in the hash, got %s", single)
+	}
+
+	// A different chunk size gives a different tree
+	other, err := TreeHashFile(path, 4096, 4)
+	if err != nil {
+		t.Fatalf("Failed to hash file: %v", err)
+	}
+	if other == single {
+		t.Error("Expected different hashes for different chunk sizes")
+	}
+
+	// Check a two-chunk tree by hand
+	leaf := func(data []byte) []byte {
+		sum := sha256.Sum256(append([]byte{0x00}, data...))
+		return sum[:]
+	}
+	half := int64(len(content) / 2)
+	node := sha256.Sum256(append(append([]byte{0x01}, leaf(content[:half])...), leaf(content[half:])...))
+	got, err := TreeHashFile(path, half, 2)
+	if err != nil {
+		t.Fatalf("Failed to hash file: %v", err)
+	}
+	if want := "sha256-tree:5000:" + hex.EncodeToString(node[:]); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	if _, err := TreeHashFile(path, 0, 1); err == nil {
+		t.Error("Expected an error for a zero chunk size")
+	}
+}
@@ -1,14 +1,24 @@
 package integration
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/RaihanurRahman2022/file-analytics/internal/api"
 	"github.com/RaihanurRahman2022/file-analytics/internal/monitor"
+	"github.com/RaihanurRahman2022/file-analytics/pkg/models"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -70,9 +80,446 @@ func TestMetricsAPI(t *testing.T) {
 	defer server.Close()
 
 	// Test metrics endpoint
+	metrics.AddBytes(512)
 	resp, err := http.Get(server.URL + "/api/v1/metrics")
 	assert.NoError(t, err)
 	defer resp.Body.Close()
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 512.0, body["bytes_processed_total"])
+	assert.Contains(t, body, "bytes_per_second")
+}
+
+func TestBatchAnalyzeAPI(t *testing.T) {
+	// Setup
+	metrics := monitor.NewMetrics()
+	handlers := api.NewHandlers(metrics)
+	server := httptest.NewServer(handlers.Router())
+	defer server.Close()
+
+	post := func(body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		resp, err := http.Post(server.URL+"/api/v1/analyze", "application/json", bytes.NewBuffer(data))
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Results come back in request order with per-file errors inline
+	resp := post(map[string][]string{
+		"files": {"testdata/sample.json", "testdata/missing.csv", "testdata/sample.txt"},
+	})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Results []models.ProcessResult `json:"results"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	if assert.Len(t, body.Results, 3) {
+		assert.Equal(t, "testdata/sample.json", body.Results[0].Path)
+		assert.NoError(t, body.Results[0].Error)
+		assert.Equal(t, "testdata/missing.csv", body.Results[1].Path)
+		assert.Error(t, body.Results[1].Error)
+		assert.Equal(t, "testdata/sample.txt", body.Results[2].Path)
+		assert.Equal(t, 9, body.Results[2].Words)
+	}
+
+	// Empty and oversized lists are rejected
+	empty := post(map[string][]string{"files": {}})
+	defer empty.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, empty.StatusCode)
+
+	tooMany := make([]string, 101)
+	for i := range tooMany {
+		tooMany[i] = "testdata/sample.txt"
+	}
+	oversized := post(map[string][]string{"files": tooMany})
+	defer oversized.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, oversized.StatusCode)
+
+	// Invalid page parameters are rejected
+	data, _ := json.Marshal(map[string]string{"path": "testdata"})
+	badPage, err := http.Post(server.URL+"/api/v1/analyze?limit=-1", "application/json", bytes.NewBuffer(data))
+	assert.NoError(t, err)
+	defer badPage.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, badPage.StatusCode)
+}
+
+func TestRequestIDAPI(t *testing.T) {
+	// Setup
+	metrics := monitor.NewMetrics()
+	handlers := api.NewHandlers(metrics)
+	server := httptest.NewServer(handlers.Router())
+	defer server.Close()
+
+	// A supplied ID is echoed back, including in error responses
+	req, err := http.NewRequest("GET", server.URL+"/api/v1/analyze", nil)
+	assert.NoError(t, err)
+	req.Header.Set(api.RequestIDHeader, "trace-123")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "trace-123", resp.Header.Get(api.RequestIDHeader))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "request_id=trace-123")
+
+	// Without one, a UUID is generated
+	generated, err := http.Get(server.URL + "/api/v1/metrics")
+	assert.NoError(t, err)
+	defer generated.Body.Close()
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, generated.Header.Get(api.RequestIDHeader))
+}
+
+func TestReportAPI(t *testing.T) {
+	// Setup
+	metrics := monitor.NewMetrics()
+	handlers := api.NewHandlers(metrics)
+	server := httptest.NewServer(handlers.Router())
+	defer server.Close()
+
+	// Nothing to report before the first analysis
+	resp, err := http.Get(server.URL + "/api/v1/report")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	data, _ := json.Marshal(map[string][]string{"files": {"testdata/sample.txt", "testdata/missing.csv"}})
+	analyzed, err := http.Post(server.URL+"/api/v1/analyze", "application/json", bytes.NewBuffer(data))
+	assert.NoError(t, err)
+	analyzed.Body.Close()
+
+	tests := []struct {
+		format      string
+		contentType string
+		fileName    string
+	}{
+		{"", "text/html; charset=utf-8", "report.html"},
+		{"md", "text/markdown; charset=utf-8", "report.md"},
+		{"json", "application/json", "report.json"},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + "/api/v1/report?format=" + tt.format)
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, tt.format)
+		assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="`+tt.fileName+`"`, resp.Header.Get("Content-Disposition"))
+		assert.Contains(t, string(body), "testdata/sample.txt")
+	}
+
+	var report struct {
+		Statistics struct {
+			SuccessCount int
+			ErrorCount   int
+		}
+	}
+	resp, err = http.Get(server.URL + "/api/v1/report?format=json")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, 1, report.Statistics.SuccessCount)
+	assert.Equal(t, 1, report.Statistics.ErrorCount)
+
+	// Summary reports leave out the per-file table
+	summary, err := http.Get(server.URL + "/api/v1/report?format=md&summary=true")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(summary.Body)
+	summary.Body.Close()
+	assert.NotContains(t, string(body), "## Processed Files")
+	assert.Contains(t, string(body), "| Total Files | 2 |")
+
+	// Unknown formats are rejected
+	bad, err := http.Get(server.URL + "/api/v1/report?format=pdf")
+	assert.NoError(t, err)
+	bad.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, bad.StatusCode)
+}
+
+func TestAnalyzeWorkersAPI(t *testing.T) {
+	// Setup
+	metrics := monitor.NewMetrics()
+	handlers := api.NewHandlers(metrics)
+	handlers.SetAnalyzeWorkers(2)
+	server := httptest.NewServer(handlers.Router())
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		workers     int
+		wantStatus  int
+		wantWorkers int
+	}{
+		{"server default", 0, http.StatusOK, 2},
+		{"requested", 8, http.StatusOK, 8},
+		{"capped", 10000, http.StatusOK, 32},
+		{"negative", -1, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(map[string]interface{}{"path": "testdata", "workers": tt.workers})
+			resp, err := http.Post(server.URL+"/api/v1/analyze", "application/json", bytes.NewBuffer(data))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			if tt.wantStatus == http.StatusOK {
+				var body struct {
+					Results []models.ProcessResult `json:"results"`
+					Workers int                    `json:"workers"`
+				}
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				assert.Equal(t, tt.wantWorkers, body.Workers)
+				assert.Len(t, body.Results, 2)
+			}
+		})
+	}
+}
+
+func TestAnalyzeTuneWorkersAPI(t *testing.T) {
+	// Setup
+	metrics := monitor.NewMetrics()
+	handlers := api.NewHandlers(metrics)
+	server := httptest.NewServer(handlers.Router())
+	defer server.Close()
+
+	// 32 files are enough to measure 1, 2 and 4 workers, leaving 4 for the rest
+	dir := t.TempDir()
+	for i := 0; i < 32; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), []byte("hello world\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{"path": dir, "tune_workers": true, "workers": 4})
+	resp, err := http.Post(server.URL+"/api/v1/analyze", "application/json", bytes.NewBuffer(data))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Results []models.ProcessResult `json:"results"`
+		Workers int                    `json:"workers"`
+		Tuning  []struct {
+			Workers        int     `json:"workers"`
+			Files          int     `json:"files"`
+			FilesPerSecond float64 `json:"files_per_second"`
+		} `json:"tuning"`
+	}
+	if !assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body)) {
+		return
+	}
+	assert.Contains(t, []int{1, 2, 4}, body.Workers)
+	if assert.Len(t, body.Tuning, 3) {
+		assert.Equal(t, 4, body.Tuning[2].Workers)
+		assert.Equal(t, 16, body.Tuning[2].Files)
+	}
+
+	// Every file has a result, in path order, whichever round processed it
+	if assert.Len(t, body.Results, 32) {
+		for i, result := range body.Results {
+			assert.Equal(t, filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), result.Path)
+			assert.Equal(t, 2, result.Words)
+		}
+	}
+}
+
+func TestDrainAPI(t *testing.T) {
+	// Setup
+	metrics := monitor.NewMetrics()
+	handlers := api.NewHandlers(metrics)
+	server := httptest.NewServer(handlers.Router())
+	defer server.Close()
+
+	// Nothing running drains at once
+	assert.Equal(t, 0, handlers.Drain(context.Background()))
+
+	// A request still sending its body keeps the analysis in flight
+	body, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Post(server.URL+"/api/v1/analyze", "application/json", body)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+		}
+	}()
+	writer.Write([]byte(`{"files": [`))
+	assert.Eventually(t, func() bool { return handlers.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, 1, handlers.Drain(ctx))
+
+	writer.Write([]byte(`"testdata/sample.txt"]}`))
+	writer.Close()
+	<-done
+	assert.Equal(t, 0, handlers.Drain(context.Background()))
+}
+
+func TestArchiveAPI(t *testing.T) {
+	// Setup
+	metrics := monitor.NewMetrics()
+	handlers := api.NewHandlers(metrics)
+	server := httptest.NewServer(handlers.Router())
+	defer server.Close()
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{
+		"docs/readme.txt": "one two three\n",
+		"data/rows.json":  `{"id": 1}`,
+		"image.png":       "not an image",
+	} {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		w.Write([]byte(content))
+	}
+	assert.NoError(t, zw.Close())
+
+	var result struct {
+		Results    []models.ProcessResult `json:"results"`
+		Statistics struct {
+			TotalFiles   int
+			SuccessCount int
+			ErrorCount   int
+		} `json:"statistics"`
+	}
+
+	// The raw body and a multipart upload are both accepted
+	raw, err := http.Post(server.URL+"/api/v1/analyze/archive", "application/zip", bytes.NewReader(archive.Bytes()))
+	assert.NoError(t, err)
+	defer raw.Body.Close()
+	assert.Equal(t, http.StatusOK, raw.StatusCode)
+	assert.NoError(t, json.NewDecoder(raw.Body).Decode(&result))
+	assert.Len(t, result.Results, 3)
+	assert.Equal(t, 3, result.Statistics.TotalFiles)
+	assert.Equal(t, 2, result.Statistics.SuccessCount)
+	assert.Equal(t, 1, result.Statistics.ErrorCount)
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, err := mw.CreateFormFile("file", "bundle.zip")
+	assert.NoError(t, err)
+	part.Write(archive.Bytes())
+	assert.NoError(t, mw.Close())
+
+	multi, err := http.Post(server.URL+"/api/v1/analyze/archive", mw.FormDataContentType(), &form)
+	assert.NoError(t, err)
+	defer multi.Body.Close()
+	assert.Equal(t, http.StatusOK, multi.StatusCode)
+
+	// Anything that isn't a zip or tar archive is rejected
+	bad, err := http.Post(server.URL+"/api/v1/analyze/archive", "application/zip", bytes.NewBufferString("plain text, not an archive at all"))
+	assert.NoError(t, err)
+	bad.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, bad.StatusCode)
+}
+
+func TestAnalyzeLimitsAPI(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "top.txt"), []byte("one two"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a", "b", "deep.txt"), []byte("three four five"), 0644))
+
+	tests := []struct {
+		name       string
+		limits     api.AnalyzeLimits
+		body       map[string]interface{}
+		wantStatus int
+		wantLimit  string
+	}{
+		{"within limits", api.AnalyzeLimits{MaxDepth: 2, MaxFiles: 2, MaxBytes: 100}, map[string]interface{}{"path": dir}, http.StatusOK, ""},
+		{"too deep", api.AnalyzeLimits{MaxDepth: 1}, map[string]interface{}{"path": dir}, http.StatusBadRequest, "max_path_depth"},
+		{"too many walked files", api.AnalyzeLimits{MaxFiles: 1}, map[string]interface{}{"path": dir}, http.StatusRequestEntityTooLarge, "max_files"},
+		{"too many listed files", api.AnalyzeLimits{MaxFiles: 1}, map[string]interface{}{"files": []string{"testdata/sample.txt", "testdata/sample.json"}}, http.StatusRequestEntityTooLarge, "max_files"},
+		{"too many bytes", api.AnalyzeLimits{MaxBytes: 10}, map[string]interface{}{"path": dir}, http.StatusRequestEntityTooLarge, "max_bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers := api.NewHandlers(monitor.NewMetrics())
+			handlers.SetAnalyzeLimits(tt.limits)
+			server := httptest.NewServer(handlers.Router())
+			defer server.Close()
+
+			data, _ := json.Marshal(tt.body)
+			resp, err := http.Post(server.URL+"/api/v1/analyze", "application/json", bytes.NewBuffer(data))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			if tt.wantLimit != "" {
+				var body struct {
+					Error string `json:"error"`
+					Limit string `json:"limit"`
+					Max   int64  `json:"max"`
+				}
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				assert.Equal(t, tt.wantLimit, body.Limit)
+				assert.NotEmpty(t, body.Error)
+				assert.Positive(t, body.Max)
+			}
+		})
+	}
+}
+
+func TestAccessLogAPI(t *testing.T) {
+	var logs bytes.Buffer
+	logrus.SetOutput(&logs)
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	defer func() {
+		logrus.SetOutput(os.Stderr)
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	}()
+
+	handlers := api.NewHandlers(monitor.NewMetrics())
+	server := httptest.NewServer(handlers.Router())
+	defer server.Close()
+
+	data, _ := json.Marshal(map[string]interface{}{"files": []string{"testdata/sample.txt", "testdata/sample.json"}})
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v1/analyze", bytes.NewBuffer(data))
+	req.Header.Set(api.RequestIDHeader, "access-log-test")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	notFound, err := http.Get(server.URL + "/api/v1/nope")
+	assert.NoError(t, err)
+	notFound.Body.Close()
+
+	var entries []map[string]interface{}
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var entry map[string]interface{}
+		assert.NoError(t, decoder.Decode(&entry))
+		if entry["msg"] == "access" {
+			entries = append(entries, entry)
+		}
+	}
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+
+	analyze := entries[0]
+	assert.Equal(t, "access-log-test", analyze["request_id"])
+	assert.Equal(t, "POST", analyze["method"])
+	assert.Equal(t, "/api/v1/analyze", analyze["path"])
+	assert.Equal(t, float64(http.StatusOK), analyze["status"])
+	assert.Equal(t, float64(len(body)), analyze["bytes_out"])
+	assert.Equal(t, "127.0.0.1", analyze["remote_ip"])
+	assert.Equal(t, float64(2), analyze["files_processed"])
+	assert.Contains(t, analyze, "duration_ms")
+
+	assert.Equal(t, float64(http.StatusNotFound), entries[1]["status"])
+	assert.NotContains(t, entries[1], "files_processed")
 }